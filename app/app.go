@@ -4,6 +4,7 @@ import (
 	"fmt"
 	"os"
 	"strings"
+	"sync/atomic"
 	gotime "time"
 
 	"github.com/VolumeFi/whoops"
@@ -16,8 +17,11 @@ import (
 	valsettypes "github.com/palomachain/paloma/v2/x/valset/types"
 	"github.com/palomachain/pigeon/chain/evm"
 	"github.com/palomachain/pigeon/chain/paloma"
+	"github.com/palomachain/pigeon/chain/plugin"
 	"github.com/palomachain/pigeon/config"
 	"github.com/palomachain/pigeon/health"
+	"github.com/palomachain/pigeon/internal/eventbus"
+	"github.com/palomachain/pigeon/internal/store"
 	"github.com/palomachain/pigeon/relayer"
 	"github.com/palomachain/pigeon/util/ion"
 	"github.com/palomachain/pigeon/util/ion/byop"
@@ -33,7 +37,7 @@ const (
 
 var (
 	_relayer    *relayer.Relayer
-	_config     *config.Config
+	_config     atomic.Pointer[config.Config]
 	_configPath string
 
 	_palomaClient *paloma.Client
@@ -43,6 +47,13 @@ var (
 	_timeAdapter time.Time
 
 	_healthCheckService *health.Service
+	_readiness          *health.Readiness
+
+	_store store.Store
+
+	_dryRun bool
+
+	_eventBus *eventbus.Bus
 )
 
 var (
@@ -62,6 +73,18 @@ func Commit() string { return commit }
 
 func Relayer() *relayer.Relayer {
 	if _relayer == nil {
+		var breakerCooldown gotime.Duration
+		if cooldown := Config().CircuitBreaker.Cooldown; len(cooldown) > 0 {
+			var err error
+			breakerCooldown, err = gotime.ParseDuration(cooldown)
+			if err != nil {
+				log.WithFields(log.Fields{
+					"err":      err,
+					"cooldown": cooldown,
+				}).Fatal("invalid circuit breaker cooldown")
+			}
+		}
+
 		_relayer = relayer.New(
 			Config(),
 			PalomaClient(),
@@ -70,12 +93,60 @@ func Relayer() *relayer.Relayer {
 			relayer.Config{
 				KeepAliveLoopTimeout:    5 * gotime.Second,
 				KeepAliveBlockThreshold: 600, // Approximately 15 minutes at 1.62 blocks per second
+				MaxConcurrentChains:     Config().MaxConcurrentChains,
+				BreakerThreshold:        Config().CircuitBreaker.Threshold,
+				BreakerCooldown:         breakerCooldown,
 			},
 		)
+		_relayer.SetStore(Store())
+		_relayer.SetEventBus(EventBus())
+
+		for chainType := range Config().Plugins {
+			if err := _relayer.RegisterProcessorBuilder(chainType, plugin.NewBuilder(PalomaClient()).WithDryRun(_dryRun)); err != nil {
+				log.WithFields(log.Fields{
+					"err":        err,
+					"chain-type": chainType,
+				}).Fatal("couldn't register plugin")
+			}
+		}
 	}
 	return _relayer
 }
 
+func EventBus() *eventbus.Bus {
+	if _eventBus == nil {
+		_eventBus = eventbus.New()
+	}
+	return _eventBus
+}
+
+// OpenStore opens the state file. Only one process can hold it, so only
+// the start command opens it. It must be called before the relayer is
+// created.
+func OpenStore() {
+	if _store != nil {
+		return
+	}
+	path := Config().StateFile.Path()
+	s, err := store.Open(path)
+	if err != nil {
+		log.WithFields(log.Fields{
+			"err":  err,
+			"path": path,
+		}).Fatal("couldn't open state store")
+	}
+	_store = s
+}
+
+// Store returns the state file opened by OpenStore. Commands which didn't
+// open it get an in-memory store instead.
+func Store() store.Store {
+	if _store == nil {
+		_store = store.NewMemory()
+	}
+	return _store
+}
+
 func SetConfigPath(path string) {
 	path = config.Filepath(path).Path()
 	fi, err := os.Stat(path)
@@ -92,9 +163,20 @@ func SetConfigPath(path string) {
 	_configPath = path
 }
 
+// SetDryRun makes pigeon skip all writes to Paloma and the target chains.
+// It must be called before any client is created.
+func SetDryRun(dryRun bool) {
+	_dryRun = dryRun
+}
+
+func DryRun() bool { return _dryRun }
+
 func EvmFactory() *evm.Factory {
 	if _evmFactory == nil {
-		_evmFactory = evm.NewFactory(PalomaClient())
+		_evmFactory = evm.NewFactory(PalomaClient()).
+			WithStore(Store()).
+			WithEventBus(EventBus()).
+			WithDryRun(_dryRun)
 	}
 
 	return _evmFactory
@@ -104,34 +186,45 @@ func Config() *config.Config {
 	if len(_configPath) == 0 {
 		log.Fatal("config file path is not set")
 	}
-	if _config == nil {
-		file, err := os.Open(_configPath)
-		if err != nil {
-			log.WithFields(log.Fields{
-				"err": err,
-			}).Fatal("couldn't open config file")
-		}
-		defer file.Close()
-		cnf, err := config.FromReader(file)
+	if _config.Load() == nil {
+		cnf, err := loadConfig(_configPath)
 		if err != nil {
 			log.WithFields(log.Fields{
 				"err": err,
 			}).Fatal("couldn't read config file")
 		}
 
-		if len(cnf.Paloma.ValidatorKey) < 1 {
-			// TODO: Remove legacy SigningKey field after successful migration
-			cnf.Paloma.ValidatorKey = cnf.Paloma.SigningKey
-		}
-		if len(cnf.Paloma.SigningKeys) < 1 {
-			log.Info("No signing key collection provided, falling back to using validator key for signing")
-			cnf.Paloma.SigningKeys = []string{cnf.Paloma.ValidatorKey}
-		}
+		_config.Store(cnf)
+	}
+
+	return _config.Load()
+}
+
+func loadConfig(path string) (*config.Config, error) {
+	file, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer file.Close()
+	cnf, err := config.FromReader(file)
+	if err != nil {
+		return nil, err
+	}
+
+	if overrides := cnf.EnvOverrides(); len(overrides) > 0 {
+		log.WithField("env-vars", overrides).Info("config fields overridden by environment")
+	}
 
-		_config = cnf
+	if len(cnf.Paloma.ValidatorKey) < 1 {
+		// TODO: Remove legacy SigningKey field after successful migration
+		cnf.Paloma.ValidatorKey = cnf.Paloma.SigningKey
+	}
+	if len(cnf.Paloma.SigningKeys) < 1 {
+		log.Info("No signing key collection provided, falling back to using validator key for signing")
+		cnf.Paloma.SigningKeys = []string{cnf.Paloma.ValidatorKey}
 	}
 
-	return _config
+	return cnf, nil
 }
 
 func PalomaClient() *paloma.Client {
@@ -141,7 +234,11 @@ func PalomaClient() *paloma.Client {
 
 		// HACK: \n is added at the end of a password because github.com/cosmos/cosmos-sdk@v0.45.1/client/input/input.go at line 93 would return an EOF error which then would fail
 		// Should be fixed with https://github.com/cosmos/cosmos-sdk/pull/11796
-		passInput := strings.NewReader(config.KeyringPassword(palomaConfig.KeyringPassEnvName) + "\n")
+		pass, err := palomaConfig.KeyringPassword()
+		if err != nil {
+			log.WithError(err).Fatal("couldn't read paloma keyring password")
+		}
+		passInput := strings.NewReader(pass + "\n")
 		ionClient := whoops.Must(ion.NewClient(
 			clientCfg,
 			passInput,
@@ -155,9 +252,9 @@ func PalomaClient() *paloma.Client {
 		}
 		r := rotator.New(fn, palomaConfig.SigningKeys...)
 
-		grpcWrapper := &paloma.GRPCClientWrapper{W: ionClient}
-		senderWrapper := paloma.NewPalomaMessageSender(r, ionClient)
-		_palomaClient = paloma.NewClient(palomaConfig, grpcWrapper, ionClient, senderWrapper, ionClient)
+		grpcWrapper := &paloma.GRPCClientWrapper{W: ionClient, F: ionClient}
+		senderWrapper := paloma.NewPalomaMessageSender(r, ionClient).WithRPCFailover(ionClient).WithTxLookup(ionClient).WithDryRun(_dryRun)
+		_palomaClient = paloma.NewClient(palomaConfig, grpcWrapper, ionClient, senderWrapper, ionClient).WithRPCFailover(ionClient)
 		senderWrapper.WithCreatorProvider(_palomaClient.GetCreator)
 		senderWrapper.WithSignerProvider(_palomaClient.GetSigner)
 	}
@@ -228,20 +325,26 @@ func palomaClientConfig(palomaConfig config.Paloma) *ion.ChainClientConfig {
 		},
 	})
 
+	rpcAddrs := palomaConfig.RPCURLs()
+	if len(rpcAddrs) < 1 {
+		rpcAddrs = []string{"http://127.0.0.1:26657"}
+	}
+
 	return &ion.ChainClientConfig{
-		Key:            palomaConfig.SigningKeys[0],
-		ChainID:        defaultValue(palomaConfig.ChainID, "paloma"),
-		RPCAddr:        defaultValue(palomaConfig.BaseRPCURL, "http://127.0.0.1:26657"),
-		AccountPrefix:  defaultValue(palomaConfig.AccountPrefix, "paloma"),
-		KeyringBackend: defaultValue(palomaConfig.KeyringType, "os"),
-		GasAdjustment:  defaultValue(palomaConfig.GasAdjustment, 1.2),
-		GasPrices:      defaultValue(palomaConfig.GasPrices, "0.01uatom"),
-		KeyDirectory:   palomaConfig.KeyringDirectory.Path(),
-		Debug:          false,
-		Timeout:        defaultValue(palomaConfig.CallTimeout, "20s"),
-		OutputFormat:   "json",
-		SignModeStr:    "direct",
-		Modules:        modules,
+		Key:              palomaConfig.SigningKeys[0],
+		ChainID:          defaultValue(palomaConfig.ChainID, "paloma"),
+		RPCAddr:          rpcAddrs[0],
+		FallbackRPCAddrs: rpcAddrs[1:],
+		AccountPrefix:    defaultValue(palomaConfig.AccountPrefix, "paloma"),
+		KeyringBackend:   defaultValue(palomaConfig.KeyringType, "os"),
+		GasAdjustment:    defaultValue(palomaConfig.GasAdjustment, 1.2),
+		GasPrices:        defaultValue(palomaConfig.GasPrices, "0.01uatom"),
+		KeyDirectory:     palomaConfig.KeyringDirectory.Path(),
+		Debug:            false,
+		Timeout:          defaultValue(palomaConfig.CallTimeout, "20s"),
+		OutputFormat:     "json",
+		SignModeStr:      "direct",
+		Modules:          modules,
 	}
 }
 
@@ -258,7 +361,22 @@ func HealthCheckService() health.Service {
 			Checks: []health.Checker{
 				Relayer(),
 			},
+			Readiness:     Readiness(),
+			ExitOnFailure: Config().HealthCheckFailurePolicy == config.HealthCheckFailurePolicyExit,
 		}
 	}
 	return *_healthCheckService
 }
+
+func Readiness() *health.Readiness {
+	if _readiness == nil {
+		_readiness = health.NewReadiness(
+			health.ConditionPaloma,
+			health.ConditionStaking,
+			health.ConditionProcessors,
+			health.ConditionRightChain,
+			health.ConditionHealthChecks,
+		)
+	}
+	return _readiness
+}
@@ -0,0 +1,99 @@
+package app
+
+import (
+	"context"
+	"os"
+	"os/signal"
+	"path/filepath"
+	"reflect"
+	"syscall"
+	gotime "time"
+
+	"github.com/fsnotify/fsnotify"
+	"github.com/palomachain/pigeon/internal/liblog"
+)
+
+// configReloadDebounce is the time to wait for further file system events
+// before reloading, as editors usually emit several writes per save.
+const configReloadDebounce = 500 * gotime.Millisecond
+
+// WatchConfig reloads the configuration whenever the config file changes
+// or pigeon receives a SIGHUP. The new configuration is validated before it
+// is swapped in, an invalid configuration is logged and discarded.
+// WatchConfig blocks until the context is done.
+func WatchConfig(ctx context.Context) {
+	logger := liblog.WithContext(ctx).WithField("component", "config-reload")
+
+	sighup := make(chan os.Signal, 1)
+	signal.Notify(sighup, syscall.SIGHUP)
+	defer signal.Stop(sighup)
+
+	var fsEvents <-chan fsnotify.Event
+	var fsErrors <-chan error
+	watcher, err := fsnotify.NewWatcher()
+	if err != nil {
+		logger.WithError(err).Warn("unable to watch config file, reloading on SIGHUP only")
+	} else {
+		defer watcher.Close()
+		// Watch the directory instead of the file itself, so that
+		// atomic replacements of the file are picked up as well.
+		if err := watcher.Add(filepath.Dir(_configPath)); err != nil {
+			logger.WithError(err).Warn("unable to watch config file, reloading on SIGHUP only")
+		} else {
+			fsEvents, fsErrors = watcher.Events, watcher.Errors
+		}
+	}
+
+	debounce := gotime.NewTimer(configReloadDebounce)
+	debounce.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-sighup:
+			logger.Info("received SIGHUP")
+			reloadConfig(ctx)
+		case ev := <-fsEvents:
+			if filepath.Clean(ev.Name) != filepath.Clean(_configPath) {
+				continue
+			}
+			if !ev.Has(fsnotify.Write) && !ev.Has(fsnotify.Create) && !ev.Has(fsnotify.Rename) {
+				continue
+			}
+			debounce.Reset(configReloadDebounce)
+		case err := <-fsErrors:
+			logger.WithError(err).Warn("error while watching config file")
+		case <-debounce.C:
+			logger.Info("config file changed")
+			reloadConfig(ctx)
+		}
+	}
+}
+
+func reloadConfig(ctx context.Context) {
+	logger := liblog.WithContext(ctx).WithField("component", "config-reload")
+
+	cnf, err := loadConfig(_configPath)
+	if err != nil {
+		logger.WithError(err).Error("invalid config, keeping the current one")
+		return
+	}
+
+	old := Config()
+	if reflect.DeepEqual(old, cnf) {
+		logger.Debug("config unchanged")
+		return
+	}
+
+	if !reflect.DeepEqual(old.Paloma, cnf.Paloma) {
+		logger.Warn("changes to the paloma config require a restart to take effect")
+	}
+
+	_config.Store(cnf)
+	if _relayer != nil {
+		_relayer.SetConfig(cnf)
+	}
+
+	logger.Info("config reloaded")
+}
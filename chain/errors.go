@@ -17,6 +17,9 @@ const (
 	ErrMissingAccount    = whoops.Errorf("missing account for chain %s")
 	ErrAccountBalanceLow = whoops.Errorf("account balance %s for account %s (%s) is lower than minimum allowed balance")
 
+	ErrUnsupportedChainType     = whoops.Errorf("unsupported chain type %s for chain %s")
+	ErrBuilderAlreadyRegistered = whoops.Errorf("processor builder already registered for chain type %s")
+
 	EnrichedChainReferenceID whoops.Field[string] = "chainReferenceID"
 	EnrichedID               whoops.Field[uint64] = "id"
 	EnrichedItemType         whoops.Field[string] = "type"
@@ -0,0 +1,22 @@
+package evm
+
+import (
+	"strconv"
+
+	evmtypes "github.com/palomachain/paloma/v2/x/evm/types"
+	"github.com/palomachain/pigeon/chain"
+)
+
+// ChainType is the chain type of all EVM chains.
+const ChainType = "evm"
+
+// ChainInfo adapts an EVM chain info queried from Paloma to chain.ChainInfo.
+type ChainInfo struct {
+	*evmtypes.ChainInfo
+}
+
+var _ chain.ChainInfo = ChainInfo{}
+
+func (c ChainInfo) ChainReferenceID() string { return c.GetChainReferenceID() }
+func (c ChainInfo) ChainID() string          { return strconv.FormatUint(c.GetChainID(), 10) }
+func (ChainInfo) ChainType() string          { return ChainType }
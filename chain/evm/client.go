@@ -130,6 +130,38 @@ type Client struct {
 
 	paloma    PalomaClienter
 	mevClient mevClient
+
+	// dryRun makes the client build and sign transactions without ever
+	// sending them.
+	dryRun bool
+
+	// nonces tracks the nonces of transactions sent by this client so that
+	// concurrent transactions don't collide.
+	nonces *nonceManager
+
+	// txs watches sent Skyway batch transactions and replaces the ones
+	// stuck in the mempool. It is nil unless replacements are enabled.
+	txs *txTracker
+
+	gasPricer gasPriceStrategy
+	gasGuard  *gasGuard
+
+	// simulate makes the client simulate transactions before sending them.
+	simulate bool
+}
+
+// Close releases the RPC connections and locks the signing key.
+func (c *Client) Close() {
+	c.txs.close()
+	if cl, ok := c.conn.(interface{ Close() }); ok {
+		cl.Close()
+	}
+	if c.arbcon != nil {
+		c.arbcon.Close()
+	}
+	if c.keystore != nil {
+		_ = c.keystore.Lock(c.addr)
+	}
 }
 
 func (c Client) GetEthClient() ethClientConn {
@@ -152,6 +184,8 @@ type ethClientConn interface {
 	BlockByHash(ctx context.Context, hash common.Hash) (*etherumtypes.Block, error)
 	BlockNumber(ctx context.Context) (uint64, error)
 	BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error)
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*etherum.FeeHistory, error)
+	PendingCallContract(ctx context.Context, call etherum.CallMsg) ([]byte, error)
 }
 
 type CompassBindingCaller interface {
@@ -204,14 +238,20 @@ func (c *Client) init() error {
 		}
 		acc := accounts.Account{Address: c.addr}
 
-		whoops.Assert(c.keystore.Unlock(acc, config.KeyringPassword(c.config.KeyringPassEnvName)))
+		whoops.Assert(c.keystore.Unlock(acc, whoops.Must(c.config.KeyringPassword())))
 
-		c.conn = whoops.Must(ethclient.Dial(c.config.BaseRPCURL))
+		c.conn = whoops.Must(newRPCPool(c.config.RPCURLs(), c.config.RPCFailover, dialEthClient))
 	})
 }
 
 func (c *Client) injectArbClient() error {
-	ac, err := arbclient.Dial(c.config.BaseRPCURL)
+	// The arbitrum client is only used for a handful of calls, so it
+	// sticks to the currently active endpoint instead of failing over.
+	url := c.config.BaseRPCURL
+	if p, ok := c.conn.(*rpcPool); ok {
+		url = p.primaryURL()
+	}
+	ac, err := arbclient.Dial(url)
 	if err != nil {
 		return err
 	}
@@ -247,6 +287,24 @@ type executeSmartContractIn struct {
 	opts      callOptions
 
 	gasEstimate *big.Int
+	dryRun      bool
+
+	// nonces allocates the transaction nonce. When nil, the node's pending
+	// nonce is used as is.
+	nonces *nonceManager
+	// knownTx looks up sent transactions before their nonce is handed out
+	// again.
+	knownTx txKnownFn
+
+	// gasPricer determines the transaction fees. When nil, the multiplier
+	// strategy is used with gasAdjustment.
+	gasPricer gasPriceStrategy
+
+	// gasGuard enforces the gas spend limits. When nil, there are none.
+	gasGuard *gasGuard
+
+	// simulate runs the transaction as eth_call before sending it.
+	simulate bool
 }
 
 func callSmartContract(
@@ -273,41 +331,29 @@ func callSmartContract(
 		}
 		whoops.Assert(err)
 
-		nonce, err := args.ethClient.PendingNonceAt(ctx, args.signingAddr)
+		lease, err := args.nonces.acquire(ctx, args.signingAddr, func(ctx context.Context) (uint64, error) {
+			return args.ethClient.PendingNonceAt(ctx, args.signingAddr)
+		}, args.knownTx)
 		if err != nil {
 			logger.
 				WithField("error", err).
 				Error("callSmartContract: error calculating pending nonce")
 		}
 		whoops.Assert(err)
+		// Unless the transaction gets broadcast below, the nonce is handed
+		// back so that it can be reused.
+		defer lease.release()
 
-		gasPrice, err := args.ethClient.SuggestGasPrice(ctx)
+		gasPrice, gasTipCap, err := gasPricerOrDefault(args.gasPricer, args.gasAdjustment).
+			gasPrices(ctx, args.ethClient, args.txType)
 		if err != nil {
 			logger.
 				WithField("error", err).
-				Error("callSmartContract: error calculating suggested gas price")
+				Error("callSmartContract: error calculating gas price")
 		}
 		whoops.Assert(err)
 
-		// adjusting the gas price
-		if args.txType != 2 && args.gasAdjustment > 1.0 {
-			gasAdj := big.NewFloat(args.gasAdjustment)
-			gasAdj = gasAdj.Mul(gasAdj, new(big.Float).SetInt(gasPrice))
-			gasPrice, _ = gasAdj.Int(big.NewInt(0))
-		}
-
-		var gasTipCap *big.Int
-
 		if args.txType == 2 {
-			gasPrice = gasPrice.Mul(gasPrice, big.NewInt(2)) // double gas price for EIP-1559 tx
-			gasTipCap, err = args.ethClient.SuggestGasTipCap(ctx)
-			if err != nil {
-				logger.
-					WithField("error", err).
-					Error("callSmartContract: error calling SuggestGasTipCap")
-			}
-			whoops.Assert(err)
-			gasPrice = gasPrice.Add(gasPrice, gasTipCap)
 			logger.WithFields(log.Fields{
 				"gas-max-price": gasPrice,
 				"gas-max-tip":   gasTipCap,
@@ -338,7 +384,7 @@ func callSmartContract(
 		}
 		whoops.Assert(err)
 
-		txOpts.Nonce = big.NewInt(int64(nonce))
+		txOpts.Nonce = new(big.Int).SetUint64(lease.Nonce())
 		txOpts.From = args.signingAddr
 
 		value := new(big.Int)
@@ -401,11 +447,44 @@ func callSmartContract(
 			}).Debug("executing legacy tx")
 		}
 
+		cost := new(big.Int).Mul(new(big.Int).SetUint64(txOpts.GasLimit), gasPrice)
+		reservation, err := args.gasGuard.reserve(lease.Nonce(), gasPrice, cost, cost)
+		if err != nil {
+			logger.
+				WithField("error", err).
+				Warn("callSmartContract: gas spend limit reached, skipping transaction")
+		}
+		whoops.Assert(err)
+		// Unless the transaction gets broadcast below, the spend is refunded.
+		defer reservation.refund()
+
+		if args.simulate {
+			err := simulateTx(ctx, args.ethClient, args.abi, etherum.CallMsg{
+				From:      txOpts.From,
+				To:        &args.contract,
+				Gas:       txOpts.GasLimit,
+				GasPrice:  txOpts.GasPrice,
+				GasFeeCap: txOpts.GasFeeCap,
+				GasTipCap: txOpts.GasTipCap,
+				Value:     value,
+				Data:      packedBytes,
+			})
+			if err != nil {
+				logger.
+					WithField("error", err).
+					Error("callSmartContract: transaction simulation failed")
+			}
+			whoops.Assert(err)
+		}
+
 		// In case we want to relay, don't actually send the constructed TX
 		if args.opts.useMevRelay && args.mevClient != nil {
 			logger.Info("MEV Client set - setting TX to not execute")
 			txOpts.NoSend = true
 		}
+		if args.dryRun {
+			txOpts.NoSend = true
+		}
 		tx, err := boundContract.RawTransact(txOpts, packedBytes)
 		if err != nil {
 			logger.
@@ -413,6 +492,20 @@ func callSmartContract(
 				Error("callSmartContract: error calling boundContract.RawTransact")
 		}
 		whoops.Assert(err)
+		if !txOpts.NoSend {
+			lease.sent(tx.Hash())
+			reservation.commit()
+		}
+
+		if args.dryRun {
+			logger.WithFields(log.Fields{
+				"tx-hash":      tx.Hash(),
+				"tx-nonce":     tx.Nonce(),
+				"tx-gas-limit": tx.Gas(),
+				"tx-cost":      tx.Cost(),
+			}).Info("dry run: skipping transaction")
+			return tx
+		}
 
 		if args.mevClient != nil {
 			hash, err := args.mevClient.Relay(ctx, args.chainID, tx)
@@ -424,6 +517,8 @@ func callSmartContract(
 				logger.WithField("error", err).Error("callSmartContract: error calling mevClient.Relay")
 				whoops.Assert(err)
 			}
+			lease.sentPrivately(tx.Hash())
+			reservation.commit()
 		}
 
 		msg := "executed"
@@ -480,6 +575,11 @@ func estimateGasLimit(ctx context.Context, c ethClienter, opts *bind.TransactOpt
 	return c.EstimateGas(ctx, msg)
 }
 
+// signTx signs tx with the client's signing key.
+func (c *Client) signTx(tx *ethtypes.Transaction, chainID *big.Int) (*ethtypes.Transaction, error) {
+	return c.keystore.SignTx(accounts.Account{Address: c.addr}, tx, chainID)
+}
+
 func (c *Client) sign(ctx context.Context, bytes []byte) ([]byte, error) {
 	return c.keystore.SignHash(
 		accounts.Account{Address: c.addr},
@@ -701,6 +801,12 @@ func (c *Client) ExecuteSmartContract(
 			method:      method,
 			arguments:   arguments,
 			gasEstimate: gasEstimate,
+			dryRun:      c.dryRun,
+			nonces:      c.nonces,
+			knownTx:     nodeKnowsTx(c.conn),
+			gasPricer:   c.gasPricer,
+			gasGuard:    c.gasGuard,
+			simulate:    c.simulate,
 		},
 	)
 }
@@ -10,9 +10,11 @@ import (
 	"github.com/ethereum/go-ethereum"
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/ethereum/go-ethereum/core/types"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
 	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/palomachain/pigeon/config"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
 )
@@ -143,6 +145,102 @@ func TestExecutingSmartContract(t *testing.T) {
 				args.opts.useMevRelay = true
 			},
 		},
+		{
+			name: "dry run, should neither send nor relay transaction",
+			setup: func(t *testing.T, args *executeSmartContractIn) {
+				ethMock := newMockEthClienter(t)
+
+				ethMock.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(333), nil)
+
+				ethMock.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(444), nil)
+
+				ethMock.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(4), nil)
+
+				ethMock.On("PendingCodeAt", mock.Anything, args.contract).Return([]byte("a"), nil)
+
+				ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(222), nil)
+
+				args.ethClient = ethMock
+				args.mevClient = newMockMevClient(t)
+				args.opts.useMevRelay = true
+				args.dryRun = true
+			},
+		},
+		{
+			name:        "gas price above the limit, should not send transaction",
+			expectedErr: ErrGasSpendLimit,
+			setup: func(t *testing.T, args *executeSmartContractIn) {
+				ethMock := newMockEthClienter(t)
+
+				ethMock.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(333), nil)
+
+				ethMock.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(444), nil)
+
+				ethMock.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(4), nil)
+
+				ethMock.On("PendingCodeAt", mock.Anything, args.contract).Return([]byte("a"), nil)
+
+				ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(222), nil)
+
+				guard, err := newGasGuard("test-chain", config.GasSpendConfig{MaxGasPrice: "500"})
+				require.NoError(t, err)
+
+				args.ethClient = ethMock
+				args.gasGuard = guard
+			},
+		},
+		{
+			name: "sent transactions count against the daily budget",
+			setup: func(t *testing.T, args *executeSmartContractIn) {
+				ethMock := newMockEthClienter(t)
+
+				ethMock.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(333), nil)
+
+				ethMock.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(444), nil)
+
+				ethMock.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(4), nil)
+
+				ethMock.On("PendingCodeAt", mock.Anything, args.contract).Return([]byte("a"), nil)
+
+				ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(222), nil)
+
+				ethMock.On("SendTransaction", mock.Anything, mock.Anything).Return(nil)
+
+				guard, err := newGasGuard("test-chain", config.GasSpendConfig{DailyBudget: "1000000000"})
+				require.NoError(t, err)
+				t.Cleanup(func() {
+					// (222 * 1.5 + 100_000) gas at a fee cap of 444 * 2 + 4 wei
+					require.Equal(t, big.NewInt(89_497_036), guard.spent())
+				})
+
+				args.ethClient = ethMock
+				args.gasGuard = guard
+			},
+		},
+		{
+			name:        "simulation reverts, should not send transaction",
+			expectedErr: ErrTxSimulationReverted,
+			setup: func(t *testing.T, args *executeSmartContractIn) {
+				ethMock := newMockEthClienter(t)
+
+				ethMock.On("PendingNonceAt", mock.Anything, mock.Anything).Return(uint64(333), nil)
+
+				ethMock.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(444), nil)
+
+				ethMock.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(4), nil)
+
+				ethMock.On("PendingCodeAt", mock.Anything, args.contract).Return([]byte("a"), nil)
+
+				ethMock.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(222), nil)
+
+				data := revertData(t, "Error(string)", []string{"string"}, "Invalid signature")
+				ethMock.On("CallContract", mock.Anything, mock.Anything, (*big.Int)(nil)).
+					Return(nil, fakeJsonRpcError(hexutil.Encode(data)))
+
+				args.ethClient = ethMock
+				args.simulate = true
+			},
+		},
 		{
 			name: "Message gas estimation only, should not send transaction",
 			setup: func(t *testing.T, args *executeSmartContractIn) {
@@ -7,6 +7,7 @@ import (
 	"fmt"
 	"math/big"
 	"slices"
+	"sync/atomic"
 	"time"
 
 	"cosmossdk.io/math"
@@ -23,7 +24,11 @@ import (
 	"github.com/palomachain/pigeon/chain"
 	cabi "github.com/palomachain/pigeon/chain/evm/abi/compass"
 	"github.com/palomachain/pigeon/internal/ethfilter"
+	"github.com/palomachain/pigeon/internal/eventbus"
 	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/metrics"
+	"github.com/palomachain/pigeon/internal/shutdown"
+	"github.com/palomachain/pigeon/internal/store"
 	"github.com/palomachain/pigeon/util/slice"
 	"github.com/sirupsen/logrus"
 	log "github.com/sirupsen/logrus"
@@ -76,10 +81,15 @@ type compass struct {
 	chainID                 *big.Int
 	CompassID               string
 	ChainReferenceID        string
-	lastObservedBlockHeight uint64
+	lastObservedBlockHeight *atomic.Uint64 // also read by the status endpoint
 	startingBlockHeight     int64
 	smartContractAddr       common.Address
 	feeMgrContractAddr      common.Address
+	senderAddr              common.Address
+	store                   store.Store
+	bus                     *eventbus.Bus
+	txs                     *txTracker
+	gasGuard                *gasGuard
 }
 
 func newCompassClient(
@@ -104,6 +114,8 @@ func newCompassClient(
 		compassAbi:         compassAbi,
 		paloma:             paloma,
 		evm:                evm,
+
+		lastObservedBlockHeight: new(atomic.Uint64),
 	}
 }
 
@@ -676,15 +688,20 @@ func (t compass) processMessages(ctx context.Context, queueTypeName string, msgs
 	for i, rawMsg := range msgs {
 		logger = logger.WithField("message-id", rawMsg.ID)
 
-		if ctx.Err() != nil {
-			logger.Debug("exiting processing message context")
+		if shutdown.IsRequested(ctx) {
+			logger.WithField("skipped-message-ids", slice.Map(msgs[i:], func(msg chain.MessageWithSignatures) uint64 {
+				return msg.ID
+			})).Info("exiting processing message context")
 			break
 		}
 
 		var processingErr error
 		var tx *ethtypes.Transaction
 		var valsetID uint64
-		msg := rawMsg.Msg.(*evmtypes.Message)
+		msg, ok := rawMsg.Msg.(*evmtypes.Message)
+		if !ok {
+			return res, ErrUnsupportedMessageType.Format(rawMsg.Msg)
+		}
 		ethSender, err := func() (common.Address, error) {
 			// Do not retrieve eth sender for UploadSmartContract messages
 			switch msg.GetAction().(type) {
@@ -799,12 +816,29 @@ func (t compass) processMessages(ctx context.Context, queueTypeName string, msgs
 				logger.WithFields(log.Fields{
 					"msg-public-access-data": tx.Hash().Hex(),
 				}).Debug("setting public access data")
+				// Message relays aren't handed to the tx tracker: Paloma
+				// keeps the first hash reported here and ignores later
+				// ones, so a replacement could never be attested.
 				err := t.paloma.SetPublicAccessData(ctx, queueTypeName,
 					rawMsg.ID, valsetID, tx.Hash().Bytes())
 				if err != nil {
+					// The transaction is out, but Paloma doesn't know about it.
+					// Log everything needed to reconcile it manually.
+					logger.WithError(err).WithFields(log.Fields{
+						"tx-hash":   tx.Hash().Hex(),
+						"tx-nonce":  tx.Nonce(),
+						"valset-id": valsetID,
+					}).Error("failed to report sent transaction to paloma")
 					gErr.Add(err)
 					return res, gErr
 				}
+				metrics.AddMessages(t.ChainReferenceID, queueTypeName, metrics.ActionRelayed, 1)
+				t.bus.Publish(ctx, eventbus.MessageRelayed{
+					ChainReferenceID: t.ChainReferenceID,
+					Queue:            queueTypeName,
+					MessageID:        rawMsg.ID,
+					TxHash:           tx.Hash().Hex(),
+				})
 			}
 		case errors.Is(processingErr, ErrNoConsensus):
 			// Only log
@@ -817,6 +851,12 @@ func (t compass) processMessages(ctx context.Context, queueTypeName string, msgs
 				Error(ctx); err != nil {
 				logger.WithError(err).Error("failed to send paloma status update")
 			}
+		case errors.Is(processingErr, ErrTxSimulationFailed):
+			// The simulation couldn't run, e.g. because the RPC is down,
+			// which says nothing about the message. Retry it on the next run
+			// instead of failing it on Paloma.
+			logger.WithError(processingErr).Warn("transaction simulation failed, retrying later")
+			gErr.Add(processingErr)
 		default:
 			logger.WithError(processingErr).Error("processing error")
 
@@ -979,18 +1019,20 @@ func (t *compass) getLogs(
 		}
 
 		currentBlockNumber := filter.FromBlock.Uint64()
-		if t.lastObservedBlockHeight == 0 {
-			t.lastObservedBlockHeight = currentBlockNumber - 10_000
+		lastObservedBlockHeight := t.lastObservedBlockHeight.Load()
+		if lastObservedBlockHeight == 0 {
+			lastObservedBlockHeight = currentBlockNumber - 10_000
 		}
 
-		if currentBlockNumber < t.lastObservedBlockHeight {
+		if currentBlockNumber < lastObservedBlockHeight {
 			// Either we messed up tracking the current block, or the RPC is
 			// having some issue. Either way, it's best to sync again.
-			t.lastObservedBlockHeight = currentBlockNumber
+			lastObservedBlockHeight = currentBlockNumber
 		}
+		t.lastObservedBlockHeight.Store(lastObservedBlockHeight)
 
-		filter.FromBlock = big.NewInt(0).SetUint64(t.lastObservedBlockHeight)
-		filter.ToBlock = big.NewInt(0).SetUint64(min(t.lastObservedBlockHeight+10_000, currentBlockNumber))
+		filter.FromBlock = big.NewInt(0).SetUint64(lastObservedBlockHeight)
+		filter.ToBlock = big.NewInt(0).SetUint64(min(lastObservedBlockHeight+10_000, currentBlockNumber))
 	} else {
 		filter = ethereum.FilterQuery{
 			Addresses: []common.Address{t.smartContractAddr},
@@ -1135,7 +1177,8 @@ func (t *compass) GetSkywayEvents(
 		}
 	}
 
-	t.lastObservedBlockHeight = toBlock
+	t.lastObservedBlockHeight.Store(toBlock)
+	t.persistLastObservedBlockHeight(ctx)
 
 	return events, err
 }
@@ -1303,6 +1346,8 @@ func (t compass) provideTxProof(ctx context.Context, queueTypeName string, rawMs
 		if err != nil {
 			return err
 		}
+
+		t.recordGasSpent(ctx, tx, receipt)
 	}
 
 	return t.paloma.AddMessageEvidence(ctx, queueTypeName, rawMsg.ID, &evmtypes.TxExecutedProof{
@@ -1311,6 +1356,19 @@ func (t compass) provideTxProof(ctx context.Context, queueTypeName string, rawMs
 	})
 }
 
+// recordGasSpent reports the gas spent on a transaction, given it
+// was sent by this pigeon, and settles its spend in the daily budget.
+func (t compass) recordGasSpent(ctx context.Context, tx *ethtypes.Transaction, receipt *ethtypes.Receipt) {
+	sender, err := ethtypes.Sender(ethtypes.LatestSignerForChainID(tx.ChainId()), tx)
+	if err != nil || sender != t.senderAddr {
+		return
+	}
+
+	fee := receiptFee(receipt)
+	metrics.AddGasSpent(t.ChainReferenceID, receipt.GasUsed, fee)
+	t.gasGuard.settle(ctx, tx.Nonce(), fee)
+}
+
 func (t compass) submitBatchSendToEVMClaim(ctx context.Context, event chain.BatchSendEvent, orchestrator string) error {
 	msg := skywaytypes.MsgBatchSendToRemoteClaim{
 		EventNonce:       event.EventNonce,
@@ -1464,18 +1522,17 @@ func (t compass) skywayRelayBatches(ctx context.Context, batches []chain.SkywayB
 	for _, batch := range batches {
 		logger = logger.WithField("batch-nonce", batch.BatchNonce)
 
-		if ctx.Err() != nil {
+		if shutdown.IsRequested(ctx) {
 			logger.Debug("exiting processing batch context")
 			break
 		}
 
-		var processingErr error
 		logger := logger.WithFields(log.Fields{
 			"batch-nonce": batch.BatchNonce,
 		})
 		logger.Debug("relaying")
 
-		_, processingErr = t.skywayRelayBatch(ctx, batch, callOptions{})
+		tx, processingErr := t.skywayRelayBatch(ctx, batch, callOptions{})
 		processingErr = whoops.Enrich(
 			processingErr,
 			FieldMessageID.Val(batch.BatchNonce),
@@ -1483,7 +1540,15 @@ func (t compass) skywayRelayBatches(ctx context.Context, batches []chain.SkywayB
 
 		switch {
 		case processingErr == nil:
-			// do nothing.  claiming happens in a different goroutine
+			// claiming happens in a different goroutine
+			if tx != nil {
+				t.bus.Publish(ctx, eventbus.SkywayBatchRelayed{
+					ChainReferenceID: t.ChainReferenceID,
+					BatchNonce:       batch.BatchNonce,
+					TxHash:           tx.Hash().Hex(),
+				})
+				t.txs.track(ctx, tx)
+			}
 		case errors.Is(processingErr, ErrNoConsensus):
 			// does nothing
 		default:
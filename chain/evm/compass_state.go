@@ -0,0 +1,46 @@
+package evm
+
+import (
+	"context"
+
+	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/store"
+)
+
+const compassStateBucket = "compass"
+
+// stateKey scopes the persisted state to the compass contract, so a newly
+// deployed contract is scanned from scratch.
+func (t *compass) stateKey() string {
+	return t.ChainReferenceID + "/" + t.smartContractAddr.Hex()
+}
+
+// restore loads the last observed block height from the store and persists
+// it there from now on.
+func (t *compass) restore(s store.Store) {
+	t.store = s
+
+	var height uint64
+	found, err := s.Get(compassStateBucket, t.stateKey(), &height)
+	if err != nil {
+		liblog.WithContext(context.Background()).WithError(err).
+			WithField("chain-reference-id", t.ChainReferenceID).
+			Warn("failed to restore last observed block height")
+		return
+	}
+	if found {
+		t.lastObservedBlockHeight.Store(height)
+	}
+}
+
+func (t *compass) persistLastObservedBlockHeight(ctx context.Context) {
+	if t.store == nil {
+		return
+	}
+
+	if err := t.store.Put(compassStateBucket, t.stateKey(), t.lastObservedBlockHeight.Load()); err != nil {
+		liblog.WithContext(ctx).WithError(err).
+			WithField("chain-reference-id", t.ChainReferenceID).
+			Warn("failed to persist last observed block height")
+	}
+}
@@ -25,7 +25,10 @@ import (
 	"github.com/palomachain/pigeon/chain"
 	evmmocks "github.com/palomachain/pigeon/chain/evm/mocks"
 	"github.com/palomachain/pigeon/chain/paloma"
+	"github.com/palomachain/pigeon/internal/eventbus"
+	"github.com/palomachain/pigeon/internal/metrics"
 	"github.com/palomachain/pigeon/internal/queue"
+	"github.com/prometheus/client_golang/prometheus"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
 	"github.com/stretchr/testify/require"
@@ -266,6 +269,27 @@ func TestIsArbitraryCallAlreadyExecuted(t *testing.T) {
 	}
 }
 
+// relayedMessages returns the relayed messages metric of the test queue.
+func relayedMessages(t *testing.T) float64 {
+	families, err := prometheus.DefaultGatherer.Gather()
+	require.NoError(t, err)
+	for _, f := range families {
+		if f.GetName() != "pigeon_messages_total" {
+			continue
+		}
+		for _, m := range f.GetMetric() {
+			labels := make(map[string]string)
+			for _, l := range m.GetLabel() {
+				labels[l.GetName()] = l.GetValue()
+			}
+			if labels["chain_reference_id"] == "internal-chain-id" && labels["queue"] == "queue-name" && labels["action"] == metrics.ActionRelayed {
+				return m.GetCounter().GetValue()
+			}
+		}
+	}
+	return 0
+}
+
 func TestMessageProcessing(t *testing.T) {
 	dummyErr := whoops.String("dummy")
 	rpcErr := fakeJsonRpcError("bla")
@@ -1665,6 +1689,45 @@ func TestMessageProcessing(t *testing.T) {
 			},
 			expErr: rpcErr,
 		},
+		{
+			name: "upload_smart_contract/when the simulation fails without a revert, it doesn't send error data to paloma",
+			msgs: []chain.MessageWithSignatures{
+				{
+					QueuedMessage: chain.QueuedMessage{
+						ID:          555,
+						BytesToSign: ethCompatibleBytesToSign,
+						Msg: &types.Message{
+							Assignee: sdk.ValAddress("validator-1").String(),
+							Action: &types.Message_UploadSmartContract{
+								UploadSmartContract: &types.UploadSmartContract{
+									Bytecode:         []byte("bytecode"),
+									Abi:              string(StoredContracts()["simple"].Source),
+									ConstructorInput: []byte("constructor input"),
+								},
+							},
+						},
+					},
+					Signatures: []chain.ValidatorSignature{
+						addValidSignature(bobPK),
+					},
+				},
+			},
+			setup: func(t *testing.T) (*mockEvmClienter, *evmmocks.PalomaClienter) {
+				evm, paloma := newMockEvmClienter(t), evmmocks.NewPalomaClienter(t)
+
+				paloma.On("QueryGetEVMValsetByID", mock.Anything, uint64(0), "internal-chain-id").Return(
+					&types.Valset{
+						Validators: []string{crypto.PubkeyToAddress(bobPK.PublicKey).Hex()},
+						Powers:     []uint64{testPowerThreshold + 1},
+						ValsetID:   uint64(55),
+					},
+					nil,
+				)
+				evm.On("DeployContract", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(nil, nil, whoops.Wrap(rpcErr, ErrTxSimulationFailed))
+				return evm, paloma
+			},
+			expErr: ErrTxSimulationFailed,
+		},
 		{
 			name: "upload_smart_contract/when smart contract returns an error and sending it to paloma fails, it returns it back",
 			msgs: []chain.MessageWithSignatures{
@@ -1723,12 +1786,35 @@ func TestMessageProcessing(t *testing.T) {
 				ethClienter,
 			)
 
+			comp.bus = eventbus.New()
+			relayed := comp.bus.Subscribe(0)
+			relayedBefore := relayedMessages(t)
+
 			_, err := comp.processMessages(ctx, "queue-name", tt.msgs, callOptions{estimateOnly: tt.estimateOnly})
 			if tt.expErr != nil {
 				require.ErrorContains(t, err, tt.expErr.Error())
 			} else {
 				require.ErrorIs(t, err, tt.expErr)
 			}
+
+			// Every transaction reported to Paloma is published.
+			var reported int
+			for _, call := range palomaClienter.Calls {
+				if call.Method == "SetPublicAccessData" && call.ReturnArguments.Error(0) == nil {
+					reported++
+				}
+			}
+			require.Len(t, relayed.Events(), reported)
+			// Only messages which were actually relayed are counted.
+			require.Equal(t, float64(reported), relayedMessages(t)-relayedBefore)
+			for i := 0; i < reported; i++ {
+				assert.Equal(t, eventbus.MessageRelayed{
+					ChainReferenceID: "internal-chain-id",
+					Queue:            "queue-name",
+					MessageID:        555,
+					TxHash:           tx.Hash().Hex(),
+				}, <-relayed.Events())
+			}
 		})
 	}
 }
@@ -12,7 +12,6 @@ import (
 	"github.com/ethereum/go-ethereum/accounts/keystore"
 	"github.com/ethereum/go-ethereum/common"
 	ethtypes "github.com/ethereum/go-ethereum/core/types"
-	"github.com/palomachain/pigeon/config"
 	"github.com/palomachain/pigeon/errors"
 	"github.com/palomachain/pigeon/internal/libchain"
 	"github.com/palomachain/pigeon/internal/liblog"
@@ -49,6 +48,10 @@ func (c *Client) DeployContract(
 		constructorInput,
 		c.config.GasAdjustment,
 		c.config.TxType,
+		c.dryRun,
+		c.nonces,
+		nodeKnowsTx(c.conn),
+		c.gasPricer,
 	)
 }
 
@@ -63,13 +66,20 @@ func deployContract(
 	constructorInput []byte,
 	gasAdjustment float64,
 	txType uint8,
+	dryRun bool,
+	nonces *nonceManager,
+	knownTx txKnownFn,
+	gasPricer gasPriceStrategy,
 ) (contractAddr common.Address, tx *ethtypes.Transaction, err error) {
 	logger := liblog.WithContext(ctx).WithField("chainID", chainID)
 	err = whoops.Try(func() {
-		nonce, err := ethClient.PendingNonceAt(ctx, signingAddr)
+		lease, err := nonces.acquire(ctx, signingAddr, func(ctx context.Context) (uint64, error) {
+			return ethClient.PendingNonceAt(ctx, signingAddr)
+		}, knownTx)
 		whoops.Assert(err)
+		defer lease.release()
 
-		gasPrice, err := ethClient.SuggestGasPrice(ctx)
+		gasPrice, gasTipCap, err := gasPricerOrDefault(gasPricer, gasAdjustment).gasPrices(ctx, ethClient, txType)
 		whoops.Assert(err)
 
 		txOpts, err := bind.NewKeyStoreTransactorWithChainID(
@@ -79,25 +89,12 @@ func deployContract(
 		)
 		whoops.Assert(err)
 
-		txOpts.Nonce = big.NewInt(int64(nonce))
+		txOpts.Nonce = new(big.Int).SetUint64(lease.Nonce())
 		txOpts.From = signingAddr
-		// adjusting the gas price
-		if txType != 2 && gasAdjustment > 1.0 {
-			gasAdj := big.NewFloat(gasAdjustment)
-			gasAdj = gasAdj.Mul(gasAdj, new(big.Float).SetInt(gasPrice))
-			gasPrice, _ = gasAdj.Int(big.NewInt(0))
-		}
-
-		var gasTipCap *big.Int
-
 		// https://github.com/VolumeFi/paloma/issues/1048
 		txOpts.GasLimit = uint64(float64(txOpts.GasLimit) * 1.1)
 
 		if txType == 2 {
-			gasPrice = gasPrice.Mul(gasPrice, big.NewInt(2)) // double gas price for EIP-1559 tx
-			gasTipCap, err = ethClient.SuggestGasTipCap(ctx)
-			whoops.Assert(err)
-			gasPrice = gasPrice.Add(gasPrice, gasTipCap)
 			logger.WithFields(log.Fields{
 				"gas-max-price": gasPrice,
 				"gas-max-tip":   gasTipCap,
@@ -141,6 +138,13 @@ func deployContract(
 		whoops.Assert(err)
 		// hack ends here
 
+		if dryRun {
+			// Build and sign the transaction, but never send it
+			txOpts.NoSend = true
+			logger = logger.WithField("dry-run", true)
+			logger.Info("dry run: skipping contract deployment")
+		}
+
 		logger.Info("deploying contract")
 
 		contractAddr, tx, _, err = bind.DeployContract(
@@ -153,6 +157,9 @@ func deployContract(
 		constructorArgs, _ = contractABI.Constructor.Inputs.Unpack(constructorInput)
 
 		whoops.Assert(err)
+		if !txOpts.NoSend {
+			lease.sent(tx.Hash())
+		}
 		if tx.Type() == 2 {
 			logger.WithFields(log.Fields{
 				"tx-hash":          tx.Hash(),
@@ -186,13 +193,20 @@ func deployContractArbitrum(
 	constructorInput []byte,
 	gasAdjustment float64,
 	txType uint8,
+	dryRun bool,
+	nonces *nonceManager,
+	knownTx txKnownFn,
+	gasPricer gasPriceStrategy,
 ) (contractAddr arbcommon.Address, tx *arbtypes.Transaction, err error) {
 	logger := log.WithField("chainID", chainID)
 	err = whoops.Try(func() {
-		nonce, err := ethClient.PendingNonceAt(ctx, signingAddr)
+		lease, err := nonces.acquire(ctx, common.Address(signingAddr), func(ctx context.Context) (uint64, error) {
+			return ethClient.PendingNonceAt(ctx, signingAddr)
+		}, knownTx)
 		whoops.Assert(err)
+		defer lease.release()
 
-		gasPrice, err := ethClient.SuggestGasPrice(ctx)
+		gasPrice, gasTipCap, err := gasPricerOrDefault(gasPricer, gasAdjustment).gasPrices(ctx, arbGasPriceSource(ethClient), txType)
 		whoops.Assert(err)
 
 		txOpts, err := arbbind.NewKeyStoreTransactorWithChainID(
@@ -202,25 +216,12 @@ func deployContractArbitrum(
 		)
 		whoops.Assert(err)
 
-		txOpts.Nonce = big.NewInt(int64(nonce))
+		txOpts.Nonce = new(big.Int).SetUint64(lease.Nonce())
 		txOpts.From = signingAddr
-		// adjusting the gas price
-		if txType != 2 && gasAdjustment > 1.0 {
-			gasAdj := big.NewFloat(gasAdjustment)
-			gasAdj = gasAdj.Mul(gasAdj, new(big.Float).SetInt(gasPrice))
-			gasPrice, _ = gasAdj.Int(big.NewInt(0))
-		}
-
-		var gasTipCap *big.Int
-
 		// https://github.com/VolumeFi/paloma/issues/1048
 		txOpts.GasLimit = uint64(float64(txOpts.GasLimit) * 1.1)
 
 		if txType == 2 {
-			gasPrice = gasPrice.Mul(gasPrice, big.NewInt(2)) // double gas price for EIP-1559 tx
-			gasTipCap, err = ethClient.SuggestGasTipCap(ctx)
-			whoops.Assert(err)
-			gasPrice = gasPrice.Add(gasPrice, gasTipCap)
 			logger.WithFields(log.Fields{
 				"gas-max-price": gasPrice,
 				"gas-max-tip":   gasTipCap,
@@ -257,6 +258,13 @@ func deployContractArbitrum(
 		whoops.Assert(err)
 		// hack ends here
 
+		if dryRun {
+			// Build and sign the transaction, but never send it
+			txOpts.NoSend = true
+			logger = logger.WithField("dry-run", true)
+			logger.Info("dry run: skipping contract deployment")
+		}
+
 		logger.Info("deploying contract")
 
 		contractAddr, tx, _, err = arbbind.DeployContract(
@@ -269,6 +277,9 @@ func deployContractArbitrum(
 		constructorArgs, _ = contractAbi.Constructor.Inputs.Unpack(constructorInput)
 
 		whoops.Assert(err)
+		if !txOpts.NoSend {
+			lease.sent(common.Hash(tx.Hash()))
+		}
 		if tx.Type() == 2 {
 			logger.WithFields(log.Fields{
 				"tx-hash":          tx.Hash(),
@@ -320,7 +331,7 @@ func (c *Client) wrapArbitrumDeployment(
 	addr.SetBytes(c.addr.Bytes())
 	acc := arbaccounts.Account{Address: *addr}
 
-	whoops.Assert(keystore.Unlock(acc, config.KeyringPassword(c.config.KeyringPassEnvName)))
+	whoops.Assert(keystore.Unlock(acc, whoops.Must(c.config.KeyringPassword())))
 
 	var atx *arbtypes.Transaction
 	_, atx, err = deployContractArbitrum(
@@ -334,6 +345,10 @@ func (c *Client) wrapArbitrumDeployment(
 		constructorInput,
 		c.config.GasAdjustment,
 		c.config.TxType,
+		c.dryRun,
+		c.nonces,
+		nodeKnowsTx(c.conn),
+		c.gasPricer,
 	)
 	if err != nil {
 		logger.WithError(err).Error("failed to deploy contract to arbitrum")
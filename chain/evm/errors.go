@@ -10,6 +10,20 @@ const (
 	ErrAddressNotFoundInKeyStore = whoops.Errorf("address: '%s' not found in keystore: %s")
 	ErrUnsupportedMessageType    = whoops.Errorf("unsupported message type: %T")
 	ErrABINotInitialized         = whoops.String("ABI is not initialized")
+	ErrNoRPCEndpoints            = whoops.String("no rpc endpoints configured")
+	ErrUnsupportedTxType         = whoops.Errorf("unsupported transaction type: %d")
+	ErrTxReplacementBumpTooLow   = whoops.Errorf("tx replacement bump-percent %d is below the minimum of %d")
+	ErrInvalidGasPriceConfig     = whoops.Errorf("invalid gas-price config: %s")
+	ErrFeeHistoryUnsupported     = whoops.String("client doesn't support eth_feeHistory")
+	ErrInvalidGasSpendConfig     = whoops.Errorf("invalid gas-spend %s: %q is not an amount of wei")
+
+	ErrGasSpendLimit      = whoops.String("gas spend limit reached")
+	ErrGasPriceAboveLimit = whoops.Errorf("gas price of %s wei is above the limit of %s wei")
+	ErrTxCostAboveLimit   = whoops.Errorf("transaction cost of %s wei is above the limit of %s wei")
+	ErrGasBudgetExceeded  = whoops.Errorf("transaction cost of %s wei exceeds the daily budget of %s wei, %s wei were spent already")
+
+	ErrTxSimulationReverted = whoops.Errorf("transaction simulation reverted: %s")
+	ErrTxSimulationFailed   = whoops.String("transaction simulation failed")
 
 	ErrEvm = whoops.String("EVM related error")
 
@@ -3,24 +3,98 @@ package evm
 import (
 	"math/big"
 	"strings"
+	"sync"
+	"sync/atomic"
 
 	"github.com/ethereum/go-ethereum/accounts/abi"
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/palomachain/pigeon/chain"
 	"github.com/palomachain/pigeon/config"
 	"github.com/palomachain/pigeon/errors"
+	"github.com/palomachain/pigeon/internal/eventbus"
 	"github.com/palomachain/pigeon/internal/libchain"
 	"github.com/palomachain/pigeon/internal/mev"
+	"github.com/palomachain/pigeon/internal/store"
 )
 
 type Factory struct {
 	palomaClienter PalomaClienter
+	store          store.Store
+	bus            *eventbus.Bus
+	dryRun         bool
+
+	mu sync.Mutex
+	// nonces outlive the processors of their chain, so that a rebuild
+	// doesn't forget about sent transactions.
+	nonces map[string]*nonceManager
+	// gasGuards outlive the processors of their chain and signing address,
+	// so that all of them count against the same budget.
+	gasGuards map[string]*gasGuard
 }
 
 func NewFactory(pc PalomaClienter) *Factory {
 	return &Factory{
 		palomaClienter: pc,
+		nonces:         make(map[string]*nonceManager),
+		gasGuards:      make(map[string]*gasGuard),
+	}
+}
+
+// nonceManager returns the nonce manager shared by all processors built
+// for the chain.
+func (f *Factory) nonceManager(chainReferenceID string) *nonceManager {
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	m, ok := f.nonces[chainReferenceID]
+	if !ok {
+		m = newNonceManager()
+		f.nonces[chainReferenceID] = m
+	}
+	return m
+}
+
+// WithStore makes all processors built by the factory persist their
+// progress to the given store.
+func (f *Factory) WithStore(s store.Store) *Factory {
+	f.store = s
+	return f
+}
+
+// WithEventBus makes all processors built by the factory publish their
+// lifecycle events to the given bus.
+func (f *Factory) WithEventBus(bus *eventbus.Bus) *Factory {
+	f.bus = bus
+	return f
+}
+
+// WithDryRun makes all processors built by the factory skip sending
+// transactions.
+func (f *Factory) WithDryRun(dryRun bool) *Factory {
+	f.dryRun = dryRun
+	return f
+}
+
+// gasGuard returns the gas guard shared by all processors built for the
+// chain and signing address, with the limits of cfg. A new guard restores
+// the spend persisted by a previous run.
+func (f *Factory) gasGuard(chainReferenceID string, addr common.Address, cfg config.GasSpendConfig) (*gasGuard, error) {
+	next, err := newGasGuard(chainReferenceID, cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	key := chainReferenceID + "/" + addr.Hex()
+	f.mu.Lock()
+	defer f.mu.Unlock()
+	if g, ok := f.gasGuards[key]; ok {
+		g.setLimits(next)
+		return g, nil
 	}
+	if f.store != nil {
+		next.restore(f.store, key)
+	}
+	f.gasGuards[key] = next
+	return next, nil
 }
 
 func (f *Factory) Build(
@@ -49,32 +123,69 @@ func (f *Factory) Build(
 		config:    cfg,
 		paloma:    f.palomaClienter,
 		mevClient: mevClient,
+		dryRun:    f.dryRun,
+		nonces:    f.nonceManager(chainReferenceID),
+		simulate:  cfg.PreflightSimulation,
 	}
 
 	if err := client.init(); err != nil {
 		return Processor{}, err
 	}
 
+	gasPricer, err := newGasPriceStrategy(cfg.GasPrice, cfg.GasAdjustment, cfg.TxType)
+	if err != nil {
+		return Processor{}, errors.Unrecoverable(err)
+	}
+	client.gasPricer = gasPricer
+
+	gasGuard, err := f.gasGuard(chainReferenceID, client.addr, cfg.GasSpend)
+	if err != nil {
+		return Processor{}, errors.Unrecoverable(err)
+	}
+	client.gasGuard = gasGuard
+
+	if !f.dryRun {
+		txs, err := newTxTracker(cfg.TxReplacement, client.conn, client.signTx, client.gasGuard)
+		if err != nil {
+			return Processor{}, errors.Unrecoverable(err)
+		}
+		if f.store != nil {
+			txs.restore(f.store, chainReferenceID+"/"+client.addr.Hex())
+		}
+		client.txs = txs
+	}
+
 	if libchain.IsArbitrum(chainID) {
 		if err := client.injectArbClient(); err != nil {
 			return Processor{}, err
 		}
 	}
 
+	compass := &compass{
+		CompassID:           smartContractID,
+		ChainReferenceID:    chainReferenceID,
+		smartContractAddr:   common.HexToAddress(smartContractAddress),
+		feeMgrContractAddr:  common.HexToAddress(feeMgrContractAddress),
+		chainID:             chainID,
+		compassAbi:          smartContractABI,
+		paloma:              f.palomaClienter,
+		evm:                 client,
+		startingBlockHeight: blockHeight,
+		senderAddr:          client.addr,
+
+		lastObservedBlockHeight: new(atomic.Uint64),
+	}
+	compass.bus = f.bus
+	compass.txs = client.txs
+	compass.gasGuard = client.gasGuard
+	if f.store != nil {
+		compass.restore(f.store)
+	}
+
 	return Processor{
-		compass: &compass{
-			CompassID:           smartContractID,
-			ChainReferenceID:    chainReferenceID,
-			smartContractAddr:   common.HexToAddress(smartContractAddress),
-			feeMgrContractAddr:  common.HexToAddress(feeMgrContractAddress),
-			chainID:             chainID,
-			compassAbi:          smartContractABI,
-			paloma:              f.palomaClienter,
-			evm:                 client,
-			startingBlockHeight: blockHeight,
-		},
+		compass:           compass,
 		evmClient:         client,
-		chainType:         "evm",
+		chainType:         ChainType,
 		chainReferenceID:  chainReferenceID,
 		blockHeight:       blockHeight,
 		blockHeightHash:   blockHeightHash,
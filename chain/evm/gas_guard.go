@@ -0,0 +1,235 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"sync"
+	"time"
+
+	"github.com/VolumeFi/whoops"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/metrics"
+	"github.com/palomachain/pigeon/internal/store"
+)
+
+const gasBudgetWindow = 24 * time.Hour
+
+// gasGuard enforces the gas spend limits of a chain. Spend is accounted
+// for as the maximum cost of a transaction, i.e. its gas limit times its
+// gas price or fee cap, at the time it is sent. Once the receipt of a
+// transaction is seen, its spend is settled to the fee actually paid.
+type gasGuard struct {
+	chainReferenceID string
+
+	mu sync.Mutex
+	gasLimits
+	spends []gasSpend
+	lastID uint64
+
+	// store persists the spends under storeKey, so that the daily budget
+	// survives restarts.
+	store    store.Store
+	storeKey string
+
+	now func() time.Time
+}
+
+// gasLimits are nil if not configured.
+type gasLimits struct {
+	maxGasPrice *big.Int
+	maxTxCost   *big.Int
+	dailyBudget *big.Int
+}
+
+type gasSpend struct {
+	id  uint64
+	at  time.Time
+	wei *big.Int
+	// nonce is the nonce of the transaction. Only one transaction per
+	// nonce can be mined, e.g. a stuck transaction or its replacement.
+	nonce   uint64
+	settled bool
+}
+
+func newGasGuard(chainReferenceID string, cfg config.GasSpendConfig) (*gasGuard, error) {
+	g := &gasGuard{
+		chainReferenceID: chainReferenceID,
+		now:              time.Now,
+	}
+
+	for _, l := range []struct {
+		name  string
+		value string
+		dst   **big.Int
+	}{
+		{"max-gas-price", cfg.MaxGasPrice, &g.maxGasPrice},
+		{"max-tx-cost", cfg.MaxTxCost, &g.maxTxCost},
+		{"daily-budget", cfg.DailyBudget, &g.dailyBudget},
+	} {
+		if l.value == "" {
+			continue
+		}
+		v, ok := new(big.Int).SetString(l.value, 10)
+		if !ok || v.Sign() < 0 {
+			return nil, ErrInvalidGasSpendConfig.Format(l.name, l.value)
+		}
+		*l.dst = v
+	}
+
+	return g, nil
+}
+
+// reserve checks a transaction against the limits and, if it is within
+// them, adds spend to the daily budget. The reservation must be committed
+// once the transaction was sent, or refunded if it wasn't. spend is the
+// cost of the transaction, unless it replaces one which was already
+// accounted for. A nil guard allows everything.
+func (g *gasGuard) reserve(nonce uint64, gasPrice, cost, spend *big.Int) (*gasReservation, error) {
+	if g == nil {
+		return nil, nil
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if g.maxGasPrice != nil && gasPrice.Cmp(g.maxGasPrice) > 0 {
+		return nil, whoops.Wrap(ErrGasPriceAboveLimit.Format(gasPrice, g.maxGasPrice), ErrGasSpendLimit)
+	}
+	if g.maxTxCost != nil && cost.Cmp(g.maxTxCost) > 0 {
+		return nil, whoops.Wrap(ErrTxCostAboveLimit.Format(cost, g.maxTxCost), ErrGasSpendLimit)
+	}
+
+	spent := g.spentLocked()
+	if g.dailyBudget != nil && new(big.Int).Add(spent, spend).Cmp(g.dailyBudget) > 0 {
+		return nil, whoops.Wrap(ErrGasBudgetExceeded.Format(cost, g.dailyBudget, spent), ErrGasSpendLimit)
+	}
+
+	g.lastID++
+	s := gasSpend{id: g.lastID, at: g.now(), wei: new(big.Int).Set(spend), nonce: nonce}
+	g.spends = append(g.spends, s)
+	metrics.SetGasSpend(g.chainReferenceID, spent.Add(spent, spend))
+
+	return &gasReservation{g: g, id: s.id}, nil
+}
+
+// settle replaces the spend of the transactions using nonce with the fee
+// the mined one actually paid. Later calls for the same nonce, e.g. when a
+// receipt is seen again, are no-ops. A nil guard does nothing.
+func (g *gasGuard) settle(ctx context.Context, nonce uint64, fee *big.Int) {
+	if g == nil || fee == nil {
+		return
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	settled := false
+	kept := g.spends[:0]
+	for _, s := range g.spends {
+		if s.nonce == nonce {
+			if s.settled {
+				// Already settled by an earlier receipt.
+				return
+			}
+			if settled {
+				// Only one transaction per nonce was mined.
+				continue
+			}
+			s.wei, s.settled = new(big.Int).Set(fee), true
+			settled = true
+		}
+		kept = append(kept, s)
+	}
+	g.spends = kept
+	if !settled {
+		return
+	}
+
+	metrics.SetGasSpend(g.chainReferenceID, g.spentLocked())
+	g.persistLocked(ctx)
+}
+
+// setLimits replaces the limits with those of next, e.g. when the guard is
+// reused by a processor built from a reloaded config.
+func (g *gasGuard) setLimits(next *gasGuard) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.gasLimits = next.gasLimits
+}
+
+// spent returns what was spent in the current budget window.
+func (g *gasGuard) spent() *big.Int {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	return g.spentLocked()
+}
+
+func (g *gasGuard) spentLocked() *big.Int {
+	cutoff := g.now().Add(-gasBudgetWindow)
+	total := new(big.Int)
+	kept := g.spends[:0]
+	for _, s := range g.spends {
+		if s.at.Before(cutoff) {
+			continue
+		}
+		kept = append(kept, s)
+		total.Add(total, s.wei)
+	}
+	g.spends = kept
+	return total
+}
+
+func (g *gasGuard) commit() {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.persistLocked(context.Background())
+}
+
+func (g *gasGuard) refund(id uint64) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	for i := range g.spends {
+		if g.spends[i].id == id {
+			g.spends = append(g.spends[:i], g.spends[i+1:]...)
+			break
+		}
+	}
+	metrics.SetGasSpend(g.chainReferenceID, g.spentLocked())
+}
+
+// gasReservation is spend added to the daily budget for a transaction
+// about to be sent. Exactly one of commit or refund takes effect; later
+// calls are no-ops.
+type gasReservation struct {
+	g    *gasGuard
+	id   uint64
+	done bool
+}
+
+// commit keeps the spend, as the transaction was sent.
+func (r *gasReservation) commit() {
+	if r == nil || r.done {
+		return
+	}
+	r.done = true
+	r.g.commit()
+}
+
+// refund removes the spend again, as the transaction wasn't sent.
+func (r *gasReservation) refund() {
+	if r == nil || r.done {
+		return
+	}
+	r.done = true
+	r.g.refund(r.id)
+}
+
+// receiptFee returns the fee paid by a mined transaction, or nil if the
+// receipt doesn't tell.
+func receiptFee(receipt *ethtypes.Receipt) *big.Int {
+	if receipt == nil || receipt.EffectiveGasPrice == nil {
+		return nil
+	}
+	return new(big.Int).Mul(new(big.Int).SetUint64(receipt.GasUsed), receipt.EffectiveGasPrice)
+}
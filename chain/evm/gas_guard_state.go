@@ -0,0 +1,84 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"time"
+
+	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/metrics"
+	"github.com/palomachain/pigeon/internal/store"
+)
+
+const gasGuardBucket = "gas-spend"
+
+// storedGasSpend is the persisted form of a gasSpend.
+type storedGasSpend struct {
+	At      time.Time `json:"at"`
+	Wei     string    `json:"wei"`
+	Nonce   uint64    `json:"nonce"`
+	Settled bool      `json:"settled"`
+}
+
+// restore loads the spends of the current budget window from the store
+// and persists them there from now on. A nil guard does nothing.
+func (g *gasGuard) restore(s store.Store, key string) {
+	if g == nil {
+		return
+	}
+
+	logger := liblog.WithContext(context.Background()).WithField("gas-guard", key)
+
+	var stored []storedGasSpend
+	found, err := s.Get(gasGuardBucket, key, &stored)
+	if err != nil {
+		logger.WithError(err).Warn("failed to restore gas spend")
+	}
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.store, g.storeKey = s, key
+	if !found || err != nil {
+		return
+	}
+
+	for _, st := range stored {
+		wei, ok := new(big.Int).SetString(st.Wei, 10)
+		if !ok {
+			logger.WithField("wei", st.Wei).Warn("failed to decode gas spend")
+			continue
+		}
+		g.lastID++
+		g.spends = append(g.spends, gasSpend{
+			id:      g.lastID,
+			at:      st.At,
+			wei:     wei,
+			nonce:   st.Nonce,
+			settled: st.Settled,
+		})
+	}
+	metrics.SetGasSpend(g.chainReferenceID, g.spentLocked())
+}
+
+// persistLocked writes the spends of the current budget window to the
+// store. g.mu must be held.
+func (g *gasGuard) persistLocked(ctx context.Context) {
+	if g.store == nil {
+		return
+	}
+
+	g.spentLocked() // drops spends outside of the window
+	stored := make([]storedGasSpend, 0, len(g.spends))
+	for _, s := range g.spends {
+		stored = append(stored, storedGasSpend{
+			At:      s.at,
+			Wei:     s.wei.String(),
+			Nonce:   s.nonce,
+			Settled: s.settled,
+		})
+	}
+
+	if err := g.store.Put(gasGuardBucket, g.storeKey, stored); err != nil {
+		liblog.WithContext(ctx).WithError(err).WithField("gas-guard", g.storeKey).Warn("failed to persist gas spend")
+	}
+}
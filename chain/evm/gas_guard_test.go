@@ -0,0 +1,204 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/store"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGasGuard(t *testing.T) {
+	g, err := newGasGuard("test-chain", config.GasSpendConfig{MaxGasPrice: "100"})
+	require.NoError(t, err)
+	require.Equal(t, big.NewInt(100), g.maxGasPrice)
+	require.Nil(t, g.maxTxCost)
+	require.Nil(t, g.dailyBudget)
+
+	_, err = newGasGuard("test-chain", config.GasSpendConfig{DailyBudget: "1 ether"})
+	require.ErrorIs(t, err, ErrInvalidGasSpendConfig)
+}
+
+func TestGasGuard(t *testing.T) {
+	t.Run("a nil guard allows everything", func(t *testing.T) {
+		var g *gasGuard
+		r, err := g.reserve(1, big.NewInt(1), big.NewInt(1), big.NewInt(1))
+		require.NoError(t, err)
+		r.refund()
+		r.commit()
+	})
+
+	t.Run("it rejects gas prices above the limit", func(t *testing.T) {
+		g, err := newGasGuard("test-chain", config.GasSpendConfig{MaxGasPrice: "100"})
+		require.NoError(t, err)
+
+		_, err = g.reserve(2, big.NewInt(100), big.NewInt(1000), big.NewInt(1000))
+		require.NoError(t, err)
+		_, err = g.reserve(3, big.NewInt(101), big.NewInt(1000), big.NewInt(1000))
+		require.ErrorIs(t, err, ErrGasSpendLimit)
+		require.ErrorIs(t, err, ErrGasPriceAboveLimit)
+	})
+
+	t.Run("it rejects transactions costing more than the limit", func(t *testing.T) {
+		g, err := newGasGuard("test-chain", config.GasSpendConfig{MaxTxCost: "1000"})
+		require.NoError(t, err)
+
+		_, err = g.reserve(4, big.NewInt(100), big.NewInt(1001), big.NewInt(1001))
+		require.ErrorIs(t, err, ErrTxCostAboveLimit)
+	})
+
+	t.Run("it enforces a rolling daily budget", func(t *testing.T) {
+		g, err := newGasGuard("test-chain", config.GasSpendConfig{DailyBudget: "1000"})
+		require.NoError(t, err)
+		now := time.Unix(1_000_000, 0)
+		g.now = func() time.Time { return now }
+
+		r, err := g.reserve(5, big.NewInt(1), big.NewInt(600), big.NewInt(600))
+		require.NoError(t, err)
+		r.commit()
+		// A committed reservation can't be refunded anymore.
+		r.refund()
+
+		_, err = g.reserve(6, big.NewInt(1), big.NewInt(600), big.NewInt(600))
+		require.ErrorIs(t, err, ErrGasBudgetExceeded)
+
+		// Replacements only count with their additional cost.
+		r, err = g.reserve(7, big.NewInt(1), big.NewInt(900), big.NewInt(300))
+		require.NoError(t, err)
+		r.commit()
+		require.Equal(t, big.NewInt(900), g.spent())
+
+		now = now.Add(gasBudgetWindow + time.Second)
+		require.Equal(t, big.NewInt(0), g.spent())
+		_, err = g.reserve(8, big.NewInt(1), big.NewInt(600), big.NewInt(600))
+		require.NoError(t, err)
+	})
+
+	t.Run("refunded reservations don't count", func(t *testing.T) {
+		g, err := newGasGuard("test-chain", config.GasSpendConfig{DailyBudget: "1000"})
+		require.NoError(t, err)
+
+		r, err := g.reserve(9, big.NewInt(1), big.NewInt(600), big.NewInt(600))
+		require.NoError(t, err)
+		r.refund()
+		require.Equal(t, big.NewInt(0), g.spent())
+	})
+	t.Run("spends are settled with the fee actually paid", func(t *testing.T) {
+		g, err := newGasGuard("test-chain", config.GasSpendConfig{DailyBudget: "1000"})
+		require.NoError(t, err)
+		ctx := context.Background()
+
+		r, err := g.reserve(7, big.NewInt(1), big.NewInt(600), big.NewInt(600))
+		require.NoError(t, err)
+		r.commit()
+		// The replacement of the stuck transaction only counts with its
+		// additional cost.
+		r, err = g.reserve(7, big.NewInt(1), big.NewInt(900), big.NewInt(300))
+		require.NoError(t, err)
+		r.commit()
+		r, err = g.reserve(8, big.NewInt(1), big.NewInt(50), big.NewInt(50))
+		require.NoError(t, err)
+		r.commit()
+		require.Equal(t, big.NewInt(950), g.spent())
+
+		g.settle(ctx, 7, big.NewInt(200))
+		require.Equal(t, big.NewInt(250), g.spent())
+
+		// Seeing the receipt again doesn't change anything.
+		g.settle(ctx, 7, big.NewInt(900))
+		require.Equal(t, big.NewInt(250), g.spent())
+
+		_, err = g.reserve(9, big.NewInt(1), big.NewInt(700), big.NewInt(700))
+		require.NoError(t, err)
+	})
+}
+
+func TestGasGuardState(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemory()
+	now := time.Unix(1_000_000, 0)
+
+	newGuard := func() *gasGuard {
+		g, err := newGasGuard("test-chain", config.GasSpendConfig{DailyBudget: "1000"})
+		require.NoError(t, err)
+		g.now = func() time.Time { return now }
+		g.restore(s, "test-chain/0x1")
+		return g
+	}
+
+	g := newGuard()
+	r, err := g.reserve(1, big.NewInt(1), big.NewInt(600), big.NewInt(600))
+	require.NoError(t, err)
+	r.commit()
+	r, err = g.reserve(2, big.NewInt(1), big.NewInt(300), big.NewInt(300))
+	require.NoError(t, err)
+	r.commit()
+	g.settle(ctx, 1, big.NewInt(100))
+
+	t.Run("the budget survives a restart", func(t *testing.T) {
+		next := newGuard()
+		require.Equal(t, big.NewInt(400), next.spent())
+
+		// Settled spends stay settled.
+		next.settle(ctx, 1, big.NewInt(500))
+		require.Equal(t, big.NewInt(400), next.spent())
+		next.settle(ctx, 2, big.NewInt(200))
+		require.Equal(t, big.NewInt(300), next.spent())
+	})
+
+	t.Run("refunded reservations aren't persisted", func(t *testing.T) {
+		g := newGuard()
+		r, err := g.reserve(3, big.NewInt(1), big.NewInt(600), big.NewInt(600))
+		require.NoError(t, err)
+		r.refund()
+
+		require.Equal(t, big.NewInt(300), newGuard().spent())
+	})
+
+	t.Run("spends outside of the window are dropped", func(t *testing.T) {
+		now = now.Add(gasBudgetWindow + time.Second)
+		require.Equal(t, big.NewInt(0), newGuard().spent())
+	})
+}
+
+func TestFactoryGasGuard(t *testing.T) {
+	s := store.NewMemory()
+	addr := common.HexToAddress("0x1")
+	f := NewFactory(nil).WithStore(s)
+
+	g, err := f.gasGuard("test-chain", addr, config.GasSpendConfig{DailyBudget: "1000"})
+	require.NoError(t, err)
+	r, err := g.reserve(1, big.NewInt(1), big.NewInt(600), big.NewInt(600))
+	require.NoError(t, err)
+	r.commit()
+
+	t.Run("rebuilt processors share the guard and get the new limits", func(t *testing.T) {
+		next, err := f.gasGuard("test-chain", addr, config.GasSpendConfig{DailyBudget: "2000"})
+		require.NoError(t, err)
+		require.Same(t, g, next)
+		require.Equal(t, big.NewInt(2000), next.dailyBudget)
+		require.Equal(t, big.NewInt(600), next.spent())
+	})
+
+	t.Run("other signing addresses get their own guard", func(t *testing.T) {
+		other, err := f.gasGuard("test-chain", common.HexToAddress("0x2"), config.GasSpendConfig{})
+		require.NoError(t, err)
+		require.NotSame(t, g, other)
+		require.Equal(t, big.NewInt(0), other.spent())
+	})
+
+	t.Run("a new factory restores the spend", func(t *testing.T) {
+		restored, err := NewFactory(nil).WithStore(s).gasGuard("test-chain", addr, config.GasSpendConfig{})
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(600), restored.spent())
+	})
+
+	t.Run("invalid limits are rejected", func(t *testing.T) {
+		_, err := f.gasGuard("test-chain", addr, config.GasSpendConfig{DailyBudget: "1 ether"})
+		require.ErrorIs(t, err, ErrInvalidGasSpendConfig)
+	})
+}
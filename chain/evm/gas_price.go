@@ -0,0 +1,226 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/palomachain/pigeon/config"
+	arbethereum "github.com/roodeag/arbitrum"
+)
+
+const (
+	GasPriceStrategyMultiplier = "multiplier"
+	GasPriceStrategyFeeHistory = "fee-history"
+	GasPriceStrategyStatic     = "static"
+
+	cDefaultFeeHistoryBlocks            uint64  = 20
+	cDefaultFeeHistoryPercentile        float64 = 50
+	cDefaultFeeHistoryBaseFeeMultiplier float64 = 2
+)
+
+// gasPriceSource is the part of a chain client needed to price
+// transactions. Clients may additionally implement
+// ethereum.FeeHistoryReader to support the fee-history strategy.
+type gasPriceSource interface {
+	SuggestGasPrice(ctx context.Context) (*big.Int, error)
+	SuggestGasTipCap(ctx context.Context) (*big.Int, error)
+}
+
+// gasPriceStrategy determines the fees of a transaction. For EIP-1559
+// transactions gasPrice is the fee cap, for legacy transactions gasTipCap
+// is nil.
+type gasPriceStrategy interface {
+	gasPrices(ctx context.Context, src gasPriceSource, txType uint8) (gasPrice, gasTipCap *big.Int, err error)
+}
+
+func newGasPriceStrategy(cfg config.GasPriceConfig, gasAdjustment float64, txType uint8) (gasPriceStrategy, error) {
+	switch cfg.Strategy {
+	case "", GasPriceStrategyMultiplier:
+		return multiplierGasPrice{gasAdjustment: gasAdjustment}, nil
+	case GasPriceStrategyFeeHistory:
+		if txType != 2 {
+			// Without EIP-1559 there is no base fee to build on.
+			return nil, ErrInvalidGasPriceConfig.Format("fee-history requires tx-type 2")
+		}
+		s := feeHistoryGasPrice{
+			blocks:            cDefaultFeeHistoryBlocks,
+			percentile:        cDefaultFeeHistoryPercentile,
+			baseFeeMultiplier: cDefaultFeeHistoryBaseFeeMultiplier,
+		}
+		if cfg.FeeHistory.Blocks > 0 {
+			s.blocks = cfg.FeeHistory.Blocks
+		}
+		if cfg.FeeHistory.Percentile != 0 {
+			if cfg.FeeHistory.Percentile < 0 || cfg.FeeHistory.Percentile > 100 {
+				return nil, ErrInvalidGasPriceConfig.Format("fee-history percentile must be between 0 and 100")
+			}
+			s.percentile = cfg.FeeHistory.Percentile
+		}
+		if cfg.FeeHistory.BaseFeeMultiplier != 0 {
+			if cfg.FeeHistory.BaseFeeMultiplier < 1 {
+				return nil, ErrInvalidGasPriceConfig.Format("fee-history base-fee-multiplier must be at least 1")
+			}
+			s.baseFeeMultiplier = cfg.FeeHistory.BaseFeeMultiplier
+		}
+		return s, nil
+	case GasPriceStrategyStatic:
+		gasPrice, ok := new(big.Int).SetString(cfg.Static.GasPrice, 10)
+		if !ok || gasPrice.Sign() <= 0 {
+			return nil, ErrInvalidGasPriceConfig.Format("static gas-price must be a positive amount of wei")
+		}
+		s := staticGasPrice{gasPrice: gasPrice}
+		if txType == 2 {
+			tip, ok := new(big.Int).SetString(cfg.Static.GasTipCap, 10)
+			if !ok || tip.Sign() < 0 || tip.Cmp(gasPrice) > 0 {
+				return nil, ErrInvalidGasPriceConfig.Format("static gas-tip-cap must be an amount of wei no larger than gas-price")
+			}
+			s.gasTipCap = tip
+		}
+		return s, nil
+	default:
+		return nil, ErrInvalidGasPriceConfig.Format("unknown strategy " + cfg.Strategy)
+	}
+}
+
+// CheckGasPriceConfig reports whether the gas price strategy of the chain
+// is configured correctly for its transaction type.
+func CheckGasPriceConfig(cfg config.EVM) error {
+	_, err := newGasPriceStrategy(cfg.GasPrice, cfg.GasAdjustment, cfg.TxType)
+	return err
+}
+
+// gasPricerOrDefault returns s, or the multiplier strategy if s is nil.
+func gasPricerOrDefault(s gasPriceStrategy, gasAdjustment float64) gasPriceStrategy {
+	if s == nil {
+		return multiplierGasPrice{gasAdjustment: gasAdjustment}
+	}
+	return s
+}
+
+// multiplierGasPrice multiplies the suggested gas price of legacy
+// transactions by the gas adjustment. EIP-1559 transactions get twice the
+// suggested gas price plus the suggested tip as fee cap.
+type multiplierGasPrice struct {
+	gasAdjustment float64
+}
+
+func (s multiplierGasPrice) gasPrices(ctx context.Context, src gasPriceSource, txType uint8) (*big.Int, *big.Int, error) {
+	gasPrice, err := src.SuggestGasPrice(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	if txType != 2 {
+		if s.gasAdjustment > 1.0 {
+			gasAdj := big.NewFloat(s.gasAdjustment)
+			gasAdj = gasAdj.Mul(gasAdj, new(big.Float).SetInt(gasPrice))
+			gasPrice, _ = gasAdj.Int(big.NewInt(0))
+		}
+		return gasPrice, nil, nil
+	}
+
+	gasTipCap, err := src.SuggestGasTipCap(ctx)
+	if err != nil {
+		return nil, nil, err
+	}
+	gasPrice = gasPrice.Mul(gasPrice, big.NewInt(2))
+	gasPrice = gasPrice.Add(gasPrice, gasTipCap)
+	return gasPrice, gasTipCap, nil
+}
+
+// feeHistoryGasPrice uses the given percentile of the tips paid in recent
+// blocks as tip, and the next block's base fee times the multiplier plus
+// the tip as fee cap. It only prices EIP-1559 transactions.
+type feeHistoryGasPrice struct {
+	blocks            uint64
+	percentile        float64
+	baseFeeMultiplier float64
+}
+
+func (s feeHistoryGasPrice) gasPrices(ctx context.Context, src gasPriceSource, _ uint8) (*big.Int, *big.Int, error) {
+	reader, ok := src.(ethereum.FeeHistoryReader)
+	if !ok {
+		return nil, nil, ErrFeeHistoryUnsupported
+	}
+
+	history, err := reader.FeeHistory(ctx, s.blocks, nil, []float64{s.percentile})
+	if err != nil {
+		return nil, nil, err
+	}
+	if len(history.BaseFee) == 0 {
+		return nil, nil, ErrFeeHistoryUnsupported
+	}
+
+	gasTipCap := new(big.Int)
+	var n int64
+	for _, rewards := range history.Reward {
+		if len(rewards) == 0 || rewards[0] == nil {
+			continue
+		}
+		gasTipCap.Add(gasTipCap, rewards[0])
+		n++
+	}
+	if n > 0 {
+		gasTipCap.Div(gasTipCap, big.NewInt(n))
+	} else {
+		// None of the blocks contained any transactions.
+		gasTipCap, err = src.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, nil, err
+		}
+	}
+
+	// The last base fee is the one of the next block.
+	baseFee := new(big.Float).SetInt(history.BaseFee[len(history.BaseFee)-1])
+	baseFee.Mul(baseFee, big.NewFloat(s.baseFeeMultiplier))
+	gasPrice, _ := baseFee.Int(nil)
+	gasPrice.Add(gasPrice, gasTipCap)
+
+	return gasPrice, gasTipCap, nil
+}
+
+// staticGasPrice always uses the configured fees.
+type staticGasPrice struct {
+	gasPrice  *big.Int
+	gasTipCap *big.Int
+}
+
+func (s staticGasPrice) gasPrices(_ context.Context, _ gasPriceSource, txType uint8) (*big.Int, *big.Int, error) {
+	if txType != 2 {
+		return new(big.Int).Set(s.gasPrice), nil, nil
+	}
+	return new(big.Int).Set(s.gasPrice), new(big.Int).Set(s.gasTipCap), nil
+}
+
+type arbFeeHistoryReader interface {
+	FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*arbethereum.FeeHistory, error)
+}
+
+// arbFeeHistorySource adapts an Arbitrum client to ethereum.FeeHistoryReader.
+type arbFeeHistorySource struct {
+	gasPriceSource
+	reader arbFeeHistoryReader
+}
+
+func (s arbFeeHistorySource) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	h, err := s.reader.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	if err != nil {
+		return nil, err
+	}
+	return &ethereum.FeeHistory{
+		OldestBlock:  h.OldestBlock,
+		Reward:       h.Reward,
+		BaseFee:      h.BaseFee,
+		GasUsedRatio: h.GasUsedRatio,
+	}, nil
+}
+
+// arbGasPriceSource returns a gasPriceSource for an Arbitrum client, which
+// supports the fee-history strategy if the client does.
+func arbGasPriceSource(src gasPriceSource) gasPriceSource {
+	if reader, ok := src.(arbFeeHistoryReader); ok {
+		return arbFeeHistorySource{gasPriceSource: src, reader: reader}
+	}
+	return src
+}
@@ -0,0 +1,149 @@
+package evm
+
+import (
+	"context"
+	"math/big"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/palomachain/pigeon/config"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestNewGasPriceStrategy(t *testing.T) {
+	for _, tt := range []struct {
+		name    string
+		cfg     config.GasPriceConfig
+		txType  uint8
+		want    gasPriceStrategy
+		wantErr bool
+	}{
+		{
+			name: "defaults to the multiplier strategy",
+			want: multiplierGasPrice{gasAdjustment: 1.5},
+		},
+		{
+			name:   "fee-history with defaults",
+			cfg:    config.GasPriceConfig{Strategy: GasPriceStrategyFeeHistory},
+			txType: 2,
+			want:   feeHistoryGasPrice{blocks: 20, percentile: 50, baseFeeMultiplier: 2},
+		},
+		{
+			name: "fee-history with invalid percentile",
+			cfg: config.GasPriceConfig{
+				Strategy:   GasPriceStrategyFeeHistory,
+				FeeHistory: config.FeeHistoryConfig{Percentile: 101},
+			},
+			txType:  2,
+			wantErr: true,
+		},
+		{
+			name:    "fee-history requires eip-1559 transactions",
+			cfg:     config.GasPriceConfig{Strategy: GasPriceStrategyFeeHistory},
+			wantErr: true,
+		},
+		{
+			name: "static legacy",
+			cfg: config.GasPriceConfig{
+				Strategy: GasPriceStrategyStatic,
+				Static:   config.StaticGasPriceConfig{GasPrice: "1000"},
+			},
+			want: staticGasPrice{gasPrice: big.NewInt(1000)},
+		},
+		{
+			name: "static eip-1559 requires a tip",
+			cfg: config.GasPriceConfig{
+				Strategy: GasPriceStrategyStatic,
+				Static:   config.StaticGasPriceConfig{GasPrice: "1000"},
+			},
+			txType:  2,
+			wantErr: true,
+		},
+		{
+			name:    "unknown strategy",
+			cfg:     config.GasPriceConfig{Strategy: "bla"},
+			wantErr: true,
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			got, err := newGasPriceStrategy(tt.cfg, 1.5, tt.txType)
+			if tt.wantErr {
+				require.ErrorIs(t, err, ErrInvalidGasPriceConfig)
+				return
+			}
+			require.NoError(t, err)
+			require.Equal(t, tt.want, got)
+		})
+	}
+}
+
+func TestGasPriceStrategies(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("multiplier adjusts legacy gas prices", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		conn.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(100), nil).Once()
+
+		gasPrice, gasTipCap, err := multiplierGasPrice{gasAdjustment: 1.5}.gasPrices(ctx, conn, 0)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(150), gasPrice)
+		require.Nil(t, gasTipCap)
+	})
+
+	t.Run("multiplier doubles eip-1559 gas prices", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		conn.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(100), nil).Once()
+		conn.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(10), nil).Once()
+
+		gasPrice, gasTipCap, err := multiplierGasPrice{gasAdjustment: 1.5}.gasPrices(ctx, conn, 2)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(210), gasPrice)
+		require.Equal(t, big.NewInt(10), gasTipCap)
+	})
+
+	t.Run("fee-history averages the tip percentile", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		conn.On("FeeHistory", mock.Anything, uint64(3), (*big.Int)(nil), []float64{60}).Return(&ethereum.FeeHistory{
+			Reward:  [][]*big.Int{{big.NewInt(10)}, {big.NewInt(20)}, {}},
+			BaseFee: []*big.Int{big.NewInt(90), big.NewInt(95), big.NewInt(100), big.NewInt(110)},
+		}, nil).Once()
+
+		s := feeHistoryGasPrice{blocks: 3, percentile: 60, baseFeeMultiplier: 2}
+		gasPrice, gasTipCap, err := s.gasPrices(ctx, conn, 2)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(15), gasTipCap)
+		require.Equal(t, big.NewInt(235), gasPrice)
+	})
+
+	t.Run("fee-history falls back to the suggested tip for empty blocks", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		conn.On("FeeHistory", mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(&ethereum.FeeHistory{
+			Reward:  [][]*big.Int{{}},
+			BaseFee: []*big.Int{big.NewInt(100), big.NewInt(100)},
+		}, nil).Once()
+		conn.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(7), nil).Once()
+
+		s := feeHistoryGasPrice{blocks: 1, percentile: 50, baseFeeMultiplier: 1}
+		gasPrice, gasTipCap, err := s.gasPrices(ctx, conn, 2)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(7), gasTipCap)
+		require.Equal(t, big.NewInt(107), gasPrice)
+	})
+
+	t.Run("fee-history needs eth_feeHistory support", func(t *testing.T) {
+		_, _, err := feeHistoryGasPrice{}.gasPrices(ctx, newMockEthClienter(t), 2)
+		require.ErrorIs(t, err, ErrFeeHistoryUnsupported)
+	})
+
+	t.Run("static returns copies of the configured prices", func(t *testing.T) {
+		s := staticGasPrice{gasPrice: big.NewInt(100), gasTipCap: big.NewInt(5)}
+		gasPrice, gasTipCap, err := s.gasPrices(ctx, nil, 2)
+		require.NoError(t, err)
+		require.Equal(t, big.NewInt(100), gasPrice)
+		require.Equal(t, big.NewInt(5), gasTipCap)
+
+		gasPrice.Add(gasPrice, big.NewInt(1))
+		require.Equal(t, big.NewInt(100), s.gasPrice)
+	})
+}
@@ -3,11 +3,13 @@ package evm
 import (
 	"bytes"
 	"context"
+	"fmt"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common"
 	"github.com/palomachain/pigeon/chain"
 	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/metrics"
 )
 
 func (p Processor) HealthCheck(ctx context.Context) error {
@@ -20,6 +22,7 @@ func (p Processor) HealthCheck(ctx context.Context) error {
 	if err != nil {
 		return err
 	}
+	metrics.SetBalance(p.chainReferenceID, p.evmClient.addr.Hex(), balance)
 
 	cmp := balance.Cmp(p.minOnChainBalance)
 	if cmp == -1 || balance.Cmp(big.NewInt(0)) == 0 {
@@ -29,6 +32,50 @@ func (p Processor) HealthCheck(ctx context.Context) error {
 	return nil
 }
 
+// Probe checks whether the chain's RPC is reachable.
+func (p Processor) Probe(ctx context.Context) error {
+	_, err := p.evmClient.FindCurrentBlockNumber(ctx)
+	return err
+}
+
+// Status reports the processor's balance, compass valset and skyway
+// scanning state. Failing lookups are reported as part of the status.
+func (p Processor) Status(ctx context.Context) chain.ProcessorStatus {
+	status := chain.ProcessorStatus{
+		LastObservedBlockHeight: p.compass.lastObservedBlockHeight.Load(),
+	}
+	if p.minOnChainBalance != nil {
+		status.MinOnChainBalance = p.minOnChainBalance.String()
+	}
+
+	balance, err := p.evmClient.BalanceAt(ctx, p.evmClient.addr, 0)
+	if err != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("failed to get balance: %v", err))
+	} else {
+		status.Balance = balance.String()
+		status.BalanceSufficient = balance.Sign() > 0 &&
+			(p.minOnChainBalance == nil || balance.Cmp(p.minOnChainBalance) >= 0)
+	}
+
+	valsetID, valsetErr := p.compass.findLastValsetMessageID(ctx)
+	if valsetErr != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("failed to get compass valset id: %v", valsetErr))
+	}
+	status.CompassValsetID = valsetID
+
+	snapshot, snapshotErr := p.compass.paloma.QueryGetLatestPublishedSnapshot(ctx, p.chainReferenceID)
+	if snapshotErr != nil {
+		status.Errors = append(status.Errors, fmt.Sprintf("failed to get paloma snapshot: %v", snapshotErr))
+	} else {
+		status.PalomaSnapshotID = snapshot.GetId()
+	}
+
+	status.ValsetInSync = valsetErr == nil && snapshotErr == nil &&
+		status.CompassValsetID == status.PalomaSnapshotID
+
+	return status
+}
+
 func TestAndVerifyConfig(ctx context.Context, cfg config.EVM) error {
 	cli := &Client{
 		config: cfg,
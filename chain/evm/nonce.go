@@ -0,0 +1,310 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+
+	etherum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/palomachain/pigeon/internal/liblog"
+	log "github.com/sirupsen/logrus"
+)
+
+// pendingNonceFn returns the next nonce of the signing account as seen by
+// the node, including transactions still in its mempool.
+type pendingNonceFn func(context.Context) (uint64, error)
+
+// txKnownFn reports whether the node knows a transaction, either still
+// pending or mined.
+type txKnownFn func(context.Context, common.Hash) (bool, error)
+
+// txLookupConn is the part of the node connection needed to look up sent
+// transactions.
+type txLookupConn interface {
+	TransactionByHash(ctx context.Context, hash common.Hash) (tx *ethtypes.Transaction, isPending bool, err error)
+	TransactionReceipt(ctx context.Context, hash common.Hash) (*ethtypes.Receipt, error)
+}
+
+// nodeKnowsTx looks transactions up on conn. A transaction which the node
+// no longer has, e.g. because it was pruned, is still found by its
+// receipt.
+func nodeKnowsTx(conn txLookupConn) txKnownFn {
+	return func(ctx context.Context, hash common.Hash) (bool, error) {
+		_, _, err := conn.TransactionByHash(ctx, hash)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, etherum.NotFound) {
+			return false, err
+		}
+
+		_, err = conn.TransactionReceipt(ctx, hash)
+		if err == nil {
+			return true, nil
+		}
+		if !errors.Is(err, etherum.NotFound) {
+			return false, err
+		}
+		return false, nil
+	}
+}
+
+// nonceManager hands out transaction nonces for signing addresses. It keeps
+// track of nonces locally so that concurrent transactions from the same
+// account never share a nonce, and reconciles with the node's pending nonce
+// on every allocation to pick up transactions it doesn't know about and to
+// detect gaps left behind by transactions that were never sent or were
+// dropped. A sent nonce is only handed out again once the node confirms
+// that it knows neither the transaction nor its receipt.
+type nonceManager struct {
+	mu       sync.Mutex
+	accounts map[common.Address]*accountNonces
+}
+
+type accountNonces struct {
+	// next is the lowest nonce that was never handed out.
+	next uint64
+	// leased holds nonces that were handed out but not yet sent or released.
+	leased map[uint64]struct{}
+	// sent holds nonces of sent transactions that the node's pending nonce
+	// hasn't caught up with yet.
+	sent map[uint64]sentNonce
+	// chainNext is the latest pending nonce of the node, queried at
+	// observedAt.
+	chainNext  uint64
+	observedAt time.Time
+}
+
+type sentNonce struct {
+	hash   common.Hash
+	sentAt time.Time
+	// private is set for transactions which were relayed privately, e.g.
+	// through the MEV client. Public nodes don't see them before they are
+	// mined, so their nonce is never handed out again.
+	private bool
+}
+
+func newNonceManager() *nonceManager {
+	return &nonceManager{
+		accounts: make(map[common.Address]*accountNonces),
+	}
+}
+
+// acquire reserves the next usable nonce for addr. The returned lease must
+// either be marked as sent or released once the caller knows whether the
+// transaction went out. Sent nonces the node's pending nonce didn't catch
+// up with are looked up with known, and handed out again if the node
+// doesn't know their transaction. Without known, they are kept until the
+// node catches up. A nil manager simply returns the node's pending nonce
+// without tracking it.
+func (m *nonceManager) acquire(ctx context.Context, addr common.Address, pending pendingNonceFn, known txKnownFn) (*nonceLease, error) {
+	// The node is queried without holding the lock, so that a slow RPC
+	// doesn't block other callers.
+	queriedAt := time.Now()
+	chainNext, err := pending(ctx)
+	if err != nil {
+		return nil, err
+	}
+	if m == nil {
+		return &nonceLease{nonce: chainNext}, nil
+	}
+
+	dropped := m.dropped(ctx, addr, chainNext, queriedAt, known)
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	acc := m.accountLocked(addr, chainNext)
+
+	if queriedAt.Before(acc.observedAt) {
+		// A concurrent caller queried the node later, and may already have
+		// forgotten sent nonces below its pending nonce. Reconciling with
+		// the outdated one would see those nonces as gaps.
+		chainNext = acc.chainNext
+	} else {
+		acc.chainNext, acc.observedAt = chainNext, queriedAt
+	}
+
+	acc.reconcile(chainNext, dropped)
+
+	nonce := acc.next
+	if gaps := acc.gaps(chainNext); len(gaps) > 0 {
+		liblog.WithContext(ctx).WithFields(log.Fields{
+			"signing-addr":  addr,
+			"chain-nonce":   chainNext,
+			"local-nonce":   acc.next,
+			"missing-nonce": gaps,
+		}).Warn("nonce gap detected, reusing lowest missing nonce")
+		nonce = gaps[0]
+	} else {
+		acc.next++
+	}
+	acc.leased[nonce] = struct{}{}
+
+	return &nonceLease{
+		m:     m,
+		addr:  addr,
+		nonce: nonce,
+	}, nil
+}
+
+func (m *nonceManager) accountLocked(addr common.Address, chainNext uint64) *accountNonces {
+	acc, ok := m.accounts[addr]
+	if !ok {
+		acc = &accountNonces{
+			next:   chainNext,
+			leased: make(map[uint64]struct{}),
+			sent:   make(map[uint64]sentNonce),
+		}
+		m.accounts[addr] = acc
+	}
+	return acc
+}
+
+// dropped returns the sent transactions of addr which the node neither
+// counts in its pending nonce nor knows by their hash. Only transactions
+// sent before the pending nonce was queried at queriedAt are looked up.
+// The node is queried without holding the lock.
+func (m *nonceManager) dropped(ctx context.Context, addr common.Address, chainNext uint64, queriedAt time.Time, known txKnownFn) map[uint64]common.Hash {
+	if known == nil {
+		return nil
+	}
+
+	candidates := make(map[uint64]common.Hash)
+	m.mu.Lock()
+	if acc, ok := m.accounts[addr]; ok {
+		for n, s := range acc.sent {
+			if n >= chainNext && !s.private && s.sentAt.Before(queriedAt) {
+				candidates[n] = s.hash
+			}
+		}
+	}
+	m.mu.Unlock()
+
+	dropped := make(map[uint64]common.Hash)
+	for n, hash := range candidates {
+		ok, err := known(ctx, hash)
+		if err != nil {
+			liblog.WithContext(ctx).WithError(err).WithFields(log.Fields{
+				"signing-addr": addr,
+				"tx-hash":      hash,
+				"tx-nonce":     n,
+			}).Warn("failed to look up sent transaction, keeping its nonce")
+			continue
+		}
+		if !ok {
+			dropped[n] = hash
+		}
+	}
+	return dropped
+}
+
+// reconcile forgets about sent nonces the node has already accounted for
+// or whose transactions were dropped, and moves past nonces used by
+// transactions this manager didn't send.
+func (acc *accountNonces) reconcile(chainNext uint64, dropped map[uint64]common.Hash) {
+	for n, s := range acc.sent {
+		if n < chainNext {
+			delete(acc.sent, n)
+			continue
+		}
+		// The nonce may have been sent again since it was looked up.
+		if hash, ok := dropped[n]; ok && hash == s.hash {
+			delete(acc.sent, n)
+		}
+	}
+	if chainNext > acc.next {
+		acc.next = chainNext
+	}
+}
+
+// gaps returns the sorted nonces between the node's pending nonce and the
+// next local nonce which are neither leased nor sent.
+func (acc *accountNonces) gaps(chainNext uint64) []uint64 {
+	var gaps []uint64
+	for n := chainNext; n < acc.next; n++ {
+		if _, ok := acc.leased[n]; ok {
+			continue
+		}
+		if _, ok := acc.sent[n]; ok {
+			continue
+		}
+		gaps = append(gaps, n)
+	}
+	return gaps
+}
+
+func (m *nonceManager) markSent(addr common.Address, nonce uint64, s sentNonce) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acc, ok := m.accounts[addr]
+	if !ok {
+		return
+	}
+	delete(acc.leased, nonce)
+	acc.sent[nonce] = s
+}
+
+func (m *nonceManager) release(addr common.Address, nonce uint64) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+	acc, ok := m.accounts[addr]
+	if !ok {
+		return
+	}
+	delete(acc.leased, nonce)
+	if nonce+1 == acc.next {
+		acc.next = nonce
+	}
+}
+
+// nonceLease is a nonce handed out by the nonceManager. Exactly one of
+// sent or release takes effect; later calls are no-ops.
+type nonceLease struct {
+	m     *nonceManager
+	addr  common.Address
+	nonce uint64
+	done  bool
+}
+
+// Nonce returns the reserved nonce.
+func (l *nonceLease) Nonce() uint64 {
+	return l.nonce
+}
+
+// sent records that the transaction with hash using the nonce was
+// broadcast.
+func (l *nonceLease) sent(hash common.Hash) {
+	l.markSent(sentNonce{hash: hash, sentAt: time.Now()})
+}
+
+// sentPrivately records that the transaction with hash using the nonce was
+// relayed privately.
+func (l *nonceLease) sentPrivately(hash common.Hash) {
+	l.markSent(sentNonce{hash: hash, sentAt: time.Now(), private: true})
+}
+
+func (l *nonceLease) markSent(s sentNonce) {
+	if l == nil || l.done {
+		return
+	}
+	l.done = true
+	if l.m != nil {
+		l.m.markSent(l.addr, l.nonce, s)
+	}
+}
+
+// release gives the nonce back because no transaction using it was
+// broadcast.
+func (l *nonceLease) release() {
+	if l == nil || l.done {
+		return
+	}
+	l.done = true
+	if l.m != nil {
+		l.m.release(l.addr, l.nonce)
+	}
+}
@@ -0,0 +1,280 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+
+	etherum "github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func pendingNonce(n uint64) pendingNonceFn {
+	return func(context.Context) (uint64, error) { return n, nil }
+}
+
+func knownTx(known bool, err error) txKnownFn {
+	return func(context.Context, common.Hash) (bool, error) { return known, err }
+}
+
+func TestNodeKnowsTx(t *testing.T) {
+	ctx := context.Background()
+	hash := common.HexToHash("0xa")
+
+	t.Run("finds pending transactions", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		conn.On("TransactionByHash", mock.Anything, hash).Return(nil, true, nil)
+		known, err := nodeKnowsTx(conn)(ctx, hash)
+		require.NoError(t, err)
+		require.True(t, known)
+	})
+
+	t.Run("falls back to the receipt", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		conn.On("TransactionByHash", mock.Anything, hash).Return(nil, false, etherum.NotFound)
+		conn.On("TransactionReceipt", mock.Anything, hash).Return(&ethtypes.Receipt{}, nil)
+		known, err := nodeKnowsTx(conn)(ctx, hash)
+		require.NoError(t, err)
+		require.True(t, known)
+	})
+
+	t.Run("reports unknown transactions", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		conn.On("TransactionByHash", mock.Anything, hash).Return(nil, false, etherum.NotFound)
+		conn.On("TransactionReceipt", mock.Anything, hash).Return(nil, etherum.NotFound)
+		known, err := nodeKnowsTx(conn)(ctx, hash)
+		require.NoError(t, err)
+		require.False(t, known)
+	})
+
+	t.Run("returns other errors", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		conn.On("TransactionByHash", mock.Anything, hash).Return(nil, false, errors.New("boom"))
+		_, err := nodeKnowsTx(conn)(ctx, hash)
+		require.Error(t, err)
+	})
+}
+
+func TestNonceManager(t *testing.T) {
+	ctx := context.Background()
+	addr := common.HexToAddress("0x1")
+
+	t.Run("hands out consecutive nonces while the node lags behind", func(t *testing.T) {
+		m := newNonceManager()
+		first, err := m.acquire(ctx, addr, pendingNonce(5), nil)
+		require.NoError(t, err)
+		second, err := m.acquire(ctx, addr, pendingNonce(5), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), first.Nonce())
+		require.Equal(t, uint64(6), second.Nonce())
+
+		first.sent(common.Hash{})
+		second.sent(common.Hash{})
+		third, err := m.acquire(ctx, addr, pendingNonce(5), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), third.Nonce())
+	})
+
+	t.Run("keeps separate nonces per address", func(t *testing.T) {
+		m := newNonceManager()
+		a, err := m.acquire(ctx, addr, pendingNonce(1), nil)
+		require.NoError(t, err)
+		b, err := m.acquire(ctx, common.HexToAddress("0x2"), pendingNonce(1), nil)
+		require.NoError(t, err)
+		require.Equal(t, a.Nonce(), b.Nonce())
+	})
+
+	t.Run("reuses a released nonce", func(t *testing.T) {
+		m := newNonceManager()
+		lease, err := m.acquire(ctx, addr, pendingNonce(3), nil)
+		require.NoError(t, err)
+		lease.release()
+		// Releasing twice, or marking as sent afterwards, has no effect.
+		lease.release()
+		lease.sent(common.Hash{})
+
+		lease, err = m.acquire(ctx, addr, pendingNonce(3), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), lease.Nonce())
+	})
+
+	t.Run("fills gaps left by nonces that were never sent", func(t *testing.T) {
+		m := newNonceManager()
+		first, err := m.acquire(ctx, addr, pendingNonce(0), nil)
+		require.NoError(t, err)
+		second, err := m.acquire(ctx, addr, pendingNonce(0), nil)
+		require.NoError(t, err)
+		first.release()
+		second.sent(common.Hash{})
+
+		lease, err := m.acquire(ctx, addr, pendingNonce(0), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(0), lease.Nonce())
+		lease.sent(common.Hash{})
+
+		lease, err = m.acquire(ctx, addr, pendingNonce(0), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(2), lease.Nonce())
+	})
+
+	t.Run("catches up with transactions sent elsewhere", func(t *testing.T) {
+		m := newNonceManager()
+		lease, err := m.acquire(ctx, addr, pendingNonce(0), nil)
+		require.NoError(t, err)
+		lease.sent(common.Hash{})
+
+		lease, err = m.acquire(ctx, addr, pendingNonce(10), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), lease.Nonce())
+		require.Empty(t, m.accounts[addr].sent)
+	})
+
+	t.Run("hands out the nonce of a dropped transaction again", func(t *testing.T) {
+		m := newNonceManager()
+		lease, err := m.acquire(ctx, addr, pendingNonce(4), nil)
+		require.NoError(t, err)
+		lease.sent(common.HexToHash("0xa"))
+
+		var looked []common.Hash
+		lease, err = m.acquire(ctx, addr, pendingNonce(4), func(_ context.Context, hash common.Hash) (bool, error) {
+			looked = append(looked, hash)
+			return false, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(4), lease.Nonce())
+		require.Equal(t, []common.Hash{common.HexToHash("0xa")}, looked)
+	})
+
+	t.Run("keeps the nonce of a transaction the node still knows", func(t *testing.T) {
+		m := newNonceManager()
+		lease, err := m.acquire(ctx, addr, pendingNonce(4), nil)
+		require.NoError(t, err)
+		lease.sent(common.HexToHash("0xa"))
+
+		lease, err = m.acquire(ctx, addr, pendingNonce(4), knownTx(true, nil))
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), lease.Nonce())
+	})
+
+	t.Run("keeps the nonce if the lookup fails", func(t *testing.T) {
+		m := newNonceManager()
+		lease, err := m.acquire(ctx, addr, pendingNonce(4), nil)
+		require.NoError(t, err)
+		lease.sent(common.HexToHash("0xa"))
+
+		lease, err = m.acquire(ctx, addr, pendingNonce(4), knownTx(false, errors.New("boom")))
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), lease.Nonce())
+	})
+
+	t.Run("never hands out the nonce of a private transaction again", func(t *testing.T) {
+		m := newNonceManager()
+		lease, err := m.acquire(ctx, addr, pendingNonce(4), nil)
+		require.NoError(t, err)
+		lease.sentPrivately(common.HexToHash("0xa"))
+
+		lease, err = m.acquire(ctx, addr, pendingNonce(4), func(context.Context, common.Hash) (bool, error) {
+			t.Fatal("private transactions must not be looked up")
+			return false, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), lease.Nonce())
+	})
+
+	t.Run("doesn't look up transactions sent after the node was queried", func(t *testing.T) {
+		m := newNonceManager()
+		lease, err := m.acquire(ctx, addr, func(context.Context) (uint64, error) {
+			first, err := m.acquire(ctx, addr, pendingNonce(4), nil)
+			require.NoError(t, err)
+			first.sent(common.HexToHash("0xa"))
+			return 4, nil
+		}, func(context.Context, common.Hash) (bool, error) {
+			t.Fatal("the transaction was sent after the query")
+			return false, nil
+		})
+		require.NoError(t, err)
+		require.Equal(t, uint64(5), lease.Nonce())
+	})
+
+	t.Run("returns node errors", func(t *testing.T) {
+		m := newNonceManager()
+		_, err := m.acquire(ctx, addr, func(context.Context) (uint64, error) {
+			return 0, errors.New("boom")
+		}, nil)
+		require.Error(t, err)
+	})
+
+	t.Run("doesn't hold the lock while querying the node", func(t *testing.T) {
+		m := newNonceManager()
+		lease, err := m.acquire(ctx, addr, func(context.Context) (uint64, error) {
+			require.True(t, m.mu.TryLock(), "lock held during the RPC")
+			m.mu.Unlock()
+			return 3, nil
+		}, nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(3), lease.Nonce())
+	})
+
+	t.Run("ignores a pending nonce which was outdated by a later query", func(t *testing.T) {
+		m := newNonceManager()
+		lease, err := m.acquire(ctx, addr, func(context.Context) (uint64, error) {
+			// While this query is in flight, nonce 5 is sent and the
+			// node catches up with it.
+			first, err := m.acquire(ctx, addr, pendingNonce(5), nil)
+			require.NoError(t, err)
+			first.sent(common.Hash{})
+			second, err := m.acquire(ctx, addr, pendingNonce(6), nil)
+			require.NoError(t, err)
+			require.Equal(t, uint64(6), second.Nonce())
+			second.sent(common.Hash{})
+			return 5, nil
+		}, nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(7), lease.Nonce())
+	})
+
+	t.Run("a nil manager uses the pending nonce", func(t *testing.T) {
+		var m *nonceManager
+		lease, err := m.acquire(ctx, addr, pendingNonce(42), nil)
+		require.NoError(t, err)
+		require.Equal(t, uint64(42), lease.Nonce())
+		lease.sent(common.Hash{})
+		lease.release()
+	})
+
+	t.Run("never hands out the same nonce concurrently", func(t *testing.T) {
+		m := newNonceManager()
+		const n = 50
+		var wg sync.WaitGroup
+		nonces := make(chan uint64, n)
+		for i := 0; i < n; i++ {
+			wg.Add(1)
+			go func() {
+				defer wg.Done()
+				lease, err := m.acquire(ctx, addr, pendingNonce(0), nil)
+				require.NoError(t, err)
+				lease.sent(common.Hash{})
+				nonces <- lease.Nonce()
+			}()
+		}
+		wg.Wait()
+		close(nonces)
+
+		seen := make(map[uint64]bool)
+		for nonce := range nonces {
+			require.False(t, seen[nonce], "nonce %d handed out twice", nonce)
+			seen[nonce] = true
+		}
+		require.Len(t, seen, n)
+	})
+}
+
+func TestFactoryNonceManager(t *testing.T) {
+	f := NewFactory(nil)
+	require.Same(t, f.nonceManager("chain-1"), f.nonceManager("chain-1"), "rebuilt processors must share nonces")
+	require.NotSame(t, f.nonceManager("chain-1"), f.nonceManager("chain-2"))
+}
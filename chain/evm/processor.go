@@ -30,6 +30,22 @@ type Processor struct {
 	minOnChainBalance *big.Int
 }
 
+// Close releases all resources held by the processor.
+func (p Processor) Close() {
+	if p.evmClient != nil {
+		p.evmClient.Close()
+	}
+}
+
+// ReplaceStuckTransactions replaces sent transactions which have been
+// pending for too long with ones paying a higher fee.
+func (p Processor) ReplaceStuckTransactions(ctx context.Context) error {
+	if p.evmClient == nil {
+		return nil
+	}
+	return p.evmClient.txs.replaceStuck(ctx)
+}
+
 func (p Processor) GetChainReferenceID() string {
 	return p.chainReferenceID
 }
@@ -0,0 +1,496 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/ethclient"
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/liblog"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	cDefaultRPCMaxBlockLag   uint64        = 10
+	cDefaultRPCMaxErrorRate  float64       = 0.5
+	cDefaultRPCMaxLatency    time.Duration = 5 * time.Second
+	cDefaultRPCProbeInterval time.Duration = 30 * time.Second
+
+	// cRPCStatsDecay is the weight given to the latest sample when updating
+	// the moving averages of an endpoint's error rate and latency.
+	cRPCStatsDecay = 0.2
+
+	// cRPCLimitExceededCode is returned by most providers when a client is
+	// being rate limited. It's worth trying the next endpoint in this case.
+	cRPCLimitExceededCode = -32005
+)
+
+var _ ethClientConn = &rpcPool{}
+
+type rpcDialer func(url string) (ethClientConn, error)
+
+func dialEthClient(url string) (ethClientConn, error) {
+	c, err := ethclient.Dial(url)
+	if err != nil {
+		return nil, err
+	}
+	return c, nil
+}
+
+type rpcEndpoint struct {
+	url  string
+	conn ethClientConn
+
+	errorRate   float64
+	latency     time.Duration
+	blockHeight uint64
+}
+
+// RPCEndpointStatus is a point in time snapshot of an endpoint's health.
+type RPCEndpointStatus struct {
+	URL         string        `json:"url"`
+	Active      bool          `json:"active"`
+	Healthy     bool          `json:"healthy"`
+	ErrorRate   float64       `json:"error-rate"`
+	Latency     time.Duration `json:"latency"`
+	BlockHeight uint64        `json:"block-height"`
+}
+
+// rpcPool implements ethClientConn on top of a list of RPC endpoints. Calls
+// are sent to the active endpoint and fail over to the next healthy one on
+// transport errors. Endpoints are scored by block height lag, error rate and
+// latency.
+type rpcPool struct {
+	endpoints []*rpcEndpoint
+	active    int
+
+	maxBlockLag   uint64
+	maxErrorRate  float64
+	maxLatency    time.Duration
+	probeInterval time.Duration
+
+	lastProbe time.Time
+	probing   bool
+	mu        sync.Mutex
+}
+
+func newRPCPool(urls []string, cfg config.RPCFailoverConfig, dial rpcDialer) (*rpcPool, error) {
+	if len(urls) < 1 {
+		return nil, ErrNoRPCEndpoints
+	}
+
+	p := &rpcPool{
+		maxBlockLag:   cDefaultRPCMaxBlockLag,
+		maxErrorRate:  cDefaultRPCMaxErrorRate,
+		maxLatency:    cDefaultRPCMaxLatency,
+		probeInterval: cDefaultRPCProbeInterval,
+	}
+	if cfg.MaxBlockLag > 0 {
+		p.maxBlockLag = cfg.MaxBlockLag
+	}
+	if cfg.MaxErrorRate > 0 {
+		p.maxErrorRate = cfg.MaxErrorRate
+	}
+	if err := parseDurationInto(&p.maxLatency, cfg.MaxLatency); err != nil {
+		return nil, fmt.Errorf("invalid max-latency: %w", err)
+	}
+	if err := parseDurationInto(&p.probeInterval, cfg.ProbeInterval); err != nil {
+		return nil, fmt.Errorf("invalid probe-interval: %w", err)
+	}
+
+	var lastErr error
+	for _, url := range urls {
+		conn, err := dial(url)
+		if err != nil {
+			log.WithError(err).WithField("rpc-url", url).Warn("failed to dial rpc endpoint, skipping")
+			lastErr = err
+			continue
+		}
+		p.endpoints = append(p.endpoints, &rpcEndpoint{url: url, conn: conn})
+	}
+
+	if len(p.endpoints) < 1 {
+		return nil, lastErr
+	}
+
+	return p, nil
+}
+
+func parseDurationInto(d *time.Duration, s string) error {
+	if len(s) < 1 {
+		return nil
+	}
+	v, err := time.ParseDuration(s)
+	if err != nil {
+		return err
+	}
+	*d = v
+	return nil
+}
+
+// Close closes the connections to all endpoints.
+func (p *rpcPool) Close() {
+	for _, ep := range p.endpoints {
+		if c, ok := ep.conn.(interface{ Close() }); ok {
+			c.Close()
+		}
+	}
+}
+
+// primaryURL returns the URL of the currently active endpoint.
+func (p *rpcPool) primaryURL() string {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	return p.endpoints[p.active].url
+}
+
+// Status returns the health snapshot of every endpoint in the pool.
+func (p *rpcPool) Status() []RPCEndpointStatus {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	maxHeight := p.maxBlockHeight()
+	res := make([]RPCEndpointStatus, len(p.endpoints))
+	for i, ep := range p.endpoints {
+		res[i] = RPCEndpointStatus{
+			URL:         ep.url,
+			Active:      i == p.active,
+			Healthy:     p.isHealthy(ep, maxHeight),
+			ErrorRate:   ep.errorRate,
+			Latency:     ep.latency,
+			BlockHeight: ep.blockHeight,
+		}
+	}
+	return res
+}
+
+func (p *rpcPool) maxBlockHeight() uint64 {
+	var h uint64
+	for _, ep := range p.endpoints {
+		h = max(h, ep.blockHeight)
+	}
+	return h
+}
+
+func (p *rpcPool) isHealthy(ep *rpcEndpoint, maxHeight uint64) bool {
+	if ep.errorRate > p.maxErrorRate {
+		return false
+	}
+	if ep.latency > p.maxLatency {
+		return false
+	}
+	return maxHeight-ep.blockHeight <= p.maxBlockLag
+}
+
+// score returns a penalty for the endpoint. Lower is better.
+func (p *rpcPool) score(ep *rpcEndpoint, maxHeight uint64) float64 {
+	return ep.errorRate/p.maxErrorRate +
+		float64(ep.latency)/float64(p.maxLatency) +
+		float64(maxHeight-ep.blockHeight)/float64(p.maxBlockLag)
+}
+
+// candidates returns the endpoints in the order in which they should be
+// tried. The active endpoint stays first for as long as it's healthy, so
+// that consecutive calls observe a consistent view of the chain.
+func (p *rpcPool) candidates(ctx context.Context) []*rpcEndpoint {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	maxHeight := p.maxBlockHeight()
+	healthy := make([]*rpcEndpoint, 0, len(p.endpoints))
+	unhealthy := make([]*rpcEndpoint, 0, len(p.endpoints))
+
+	active := p.endpoints[p.active]
+	if p.isHealthy(active, maxHeight) {
+		healthy = append(healthy, active)
+	}
+	for _, ep := range p.endpoints {
+		if ep == active && len(healthy) > 0 {
+			continue
+		}
+		if p.isHealthy(ep, maxHeight) {
+			healthy = append(healthy, ep)
+		} else {
+			unhealthy = append(unhealthy, ep)
+		}
+	}
+
+	sort.SliceStable(unhealthy, func(i, j int) bool {
+		return p.score(unhealthy[i], maxHeight) < p.score(unhealthy[j], maxHeight)
+	})
+
+	res := append(healthy, unhealthy...)
+	if res[0] != active {
+		liblog.WithContext(ctx).WithFields(log.Fields{
+			"from-rpc-url": active.url,
+			"to-rpc-url":   res[0].url,
+		}).Warn("active rpc endpoint is unhealthy, switching")
+		p.setActive(res[0])
+	}
+
+	return res
+}
+
+func (p *rpcPool) setActive(ep *rpcEndpoint) {
+	for i := range p.endpoints {
+		if p.endpoints[i] == ep {
+			p.active = i
+			return
+		}
+	}
+}
+
+func (p *rpcPool) record(ep *rpcEndpoint, latency time.Duration, failed bool) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+
+	sample := 0.0
+	if failed {
+		sample = 1.0
+	}
+	ep.errorRate = ep.errorRate*(1-cRPCStatsDecay) + sample*cRPCStatsDecay
+	ep.latency = time.Duration(float64(ep.latency)*(1-cRPCStatsDecay) + float64(latency)*cRPCStatsDecay)
+}
+
+func (p *rpcPool) recordBlockHeight(ep *rpcEndpoint, height uint64) {
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	ep.blockHeight = height
+}
+
+// probeIfDue refreshes block heights of all endpoints in the background once
+// the probe interval has passed. It never blocks the caller.
+func (p *rpcPool) probeIfDue() {
+	p.mu.Lock()
+	due := !p.probing && time.Since(p.lastProbe) >= p.probeInterval
+	if due {
+		p.probing = true
+	}
+	p.mu.Unlock()
+
+	if !due {
+		return
+	}
+
+	go func() {
+		ctx, cancel := context.WithTimeout(context.Background(), p.maxLatency*2)
+		defer cancel()
+		p.probe(ctx)
+	}()
+}
+
+func (p *rpcPool) probe(ctx context.Context) {
+	var wg sync.WaitGroup
+	for _, ep := range p.endpoints {
+		wg.Add(1)
+		go func(ep *rpcEndpoint) {
+			defer wg.Done()
+			start := time.Now()
+			height, err := ep.conn.BlockNumber(ctx)
+			p.record(ep, time.Since(start), err != nil)
+			if err != nil {
+				log.WithError(err).WithField("rpc-url", ep.url).Warn("rpc endpoint probe failed")
+				return
+			}
+			p.recordBlockHeight(ep, height)
+		}(ep)
+	}
+	wg.Wait()
+
+	p.mu.Lock()
+	p.lastProbe = time.Now()
+	p.probing = false
+	p.mu.Unlock()
+}
+
+// shouldFailover reports whether the error indicates a problem with the
+// endpoint rather than with the request itself.
+func shouldFailover(ctx context.Context, err error) bool {
+	if err == nil || ctx.Err() != nil {
+		return false
+	}
+
+	if errors.Is(err, ethereum.NotFound) {
+		return false
+	}
+
+	var rpcErr rpc.Error
+	if errors.As(err, &rpcErr) {
+		return rpcErr.ErrorCode() == cRPCLimitExceededCode
+	}
+
+	var dataErr rpc.DataError
+	return !errors.As(err, &dataErr)
+}
+
+func poolCall[T any](ctx context.Context, p *rpcPool, method string, fn func(ethClientConn) (T, error)) (T, error) {
+	if len(p.endpoints) > 1 {
+		p.probeIfDue()
+	}
+
+	var res T
+	var err error
+	for _, ep := range p.candidates(ctx) {
+		start := time.Now()
+		res, err = fn(ep.conn)
+		failed := shouldFailover(ctx, err)
+		p.record(ep, time.Since(start), failed)
+		if !failed {
+			return res, err
+		}
+
+		liblog.WithContext(ctx).WithError(err).WithFields(log.Fields{
+			"rpc-url": ep.url,
+			"method":  method,
+		}).Warn("rpc call failed, trying next endpoint")
+	}
+
+	return res, err
+}
+
+func (p *rpcPool) CodeAt(ctx context.Context, contract common.Address, blockNumber *big.Int) ([]byte, error) {
+	return poolCall(ctx, p, "CodeAt", func(c ethClientConn) ([]byte, error) {
+		return c.CodeAt(ctx, contract, blockNumber)
+	})
+}
+
+func (p *rpcPool) CallContract(ctx context.Context, call ethereum.CallMsg, blockNumber *big.Int) ([]byte, error) {
+	return poolCall(ctx, p, "CallContract", func(c ethClientConn) ([]byte, error) {
+		return c.CallContract(ctx, call, blockNumber)
+	})
+}
+
+func (p *rpcPool) PendingCallContract(ctx context.Context, call ethereum.CallMsg) ([]byte, error) {
+	return poolCall(ctx, p, "PendingCallContract", func(c ethClientConn) ([]byte, error) {
+		return c.PendingCallContract(ctx, call)
+	})
+}
+
+func (p *rpcPool) HeaderByNumber(ctx context.Context, number *big.Int) (*ethtypes.Header, error) {
+	return poolCall(ctx, p, "HeaderByNumber", func(c ethClientConn) (*ethtypes.Header, error) {
+		return c.HeaderByNumber(ctx, number)
+	})
+}
+
+func (p *rpcPool) PendingCodeAt(ctx context.Context, account common.Address) ([]byte, error) {
+	return poolCall(ctx, p, "PendingCodeAt", func(c ethClientConn) ([]byte, error) {
+		return c.PendingCodeAt(ctx, account)
+	})
+}
+
+func (p *rpcPool) PendingNonceAt(ctx context.Context, account common.Address) (uint64, error) {
+	return poolCall(ctx, p, "PendingNonceAt", func(c ethClientConn) (uint64, error) {
+		return c.PendingNonceAt(ctx, account)
+	})
+}
+
+func (p *rpcPool) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	return poolCall(ctx, p, "SuggestGasPrice", func(c ethClientConn) (*big.Int, error) {
+		return c.SuggestGasPrice(ctx)
+	})
+}
+
+func (p *rpcPool) SuggestGasTipCap(ctx context.Context) (*big.Int, error) {
+	return poolCall(ctx, p, "SuggestGasTipCap", func(c ethClientConn) (*big.Int, error) {
+		return c.SuggestGasTipCap(ctx)
+	})
+}
+
+func (p *rpcPool) EstimateGas(ctx context.Context, call ethereum.CallMsg) (uint64, error) {
+	return poolCall(ctx, p, "EstimateGas", func(c ethClientConn) (uint64, error) {
+		return c.EstimateGas(ctx, call)
+	})
+}
+
+func (p *rpcPool) FeeHistory(ctx context.Context, blockCount uint64, lastBlock *big.Int, rewardPercentiles []float64) (*ethereum.FeeHistory, error) {
+	return poolCall(ctx, p, "FeeHistory", func(c ethClientConn) (*ethereum.FeeHistory, error) {
+		return c.FeeHistory(ctx, blockCount, lastBlock, rewardPercentiles)
+	})
+}
+
+// SendTransaction fails over like any other call. An attempt that failed
+// with a transport error may still have broadcast the transaction though,
+// in which case the next endpoint rejects it as known or its nonce as too
+// low. Neither is a failure if that endpoint knows the transaction.
+func (p *rpcPool) SendTransaction(ctx context.Context, tx *ethtypes.Transaction) error {
+	var attempts int
+	_, err := poolCall(ctx, p, "SendTransaction", func(c ethClientConn) (struct{}, error) {
+		attempts++
+		err := c.SendTransaction(ctx, tx)
+		if err == nil || attempts == 1 || !isAlreadySent(err) {
+			return struct{}{}, err
+		}
+		if _, _, lookupErr := c.TransactionByHash(ctx, tx.Hash()); lookupErr != nil {
+			return struct{}{}, err
+		}
+		liblog.WithContext(ctx).WithError(err).WithField("tx-hash", tx.Hash().Hex()).
+			Info("transaction was already sent by a previous rpc endpoint")
+		return struct{}{}, nil
+	})
+	return err
+}
+
+func isAlreadySent(err error) bool {
+	msg := err.Error()
+	return strings.Contains(msg, "already known") ||
+		strings.Contains(msg, "known transaction") ||
+		isNonceTooLow(err)
+}
+
+func (p *rpcPool) FilterLogs(ctx context.Context, q ethereum.FilterQuery) ([]ethtypes.Log, error) {
+	return poolCall(ctx, p, "FilterLogs", func(c ethClientConn) ([]ethtypes.Log, error) {
+		return c.FilterLogs(ctx, q)
+	})
+}
+
+func (p *rpcPool) SubscribeFilterLogs(ctx context.Context, q ethereum.FilterQuery, ch chan<- ethtypes.Log) (ethereum.Subscription, error) {
+	return poolCall(ctx, p, "SubscribeFilterLogs", func(c ethClientConn) (ethereum.Subscription, error) {
+		return c.SubscribeFilterLogs(ctx, q, ch)
+	})
+}
+
+func (p *rpcPool) TransactionByHash(ctx context.Context, hash common.Hash) (*ethtypes.Transaction, bool, error) {
+	type res struct {
+		tx        *ethtypes.Transaction
+		isPending bool
+	}
+	r, err := poolCall(ctx, p, "TransactionByHash", func(c ethClientConn) (res, error) {
+		tx, isPending, err := c.TransactionByHash(ctx, hash)
+		return res{tx, isPending}, err
+	})
+	return r.tx, r.isPending, err
+}
+
+func (p *rpcPool) TransactionReceipt(ctx context.Context, hash common.Hash) (*ethtypes.Receipt, error) {
+	return poolCall(ctx, p, "TransactionReceipt", func(c ethClientConn) (*ethtypes.Receipt, error) {
+		return c.TransactionReceipt(ctx, hash)
+	})
+}
+
+func (p *rpcPool) BlockByHash(ctx context.Context, hash common.Hash) (*ethtypes.Block, error) {
+	return poolCall(ctx, p, "BlockByHash", func(c ethClientConn) (*ethtypes.Block, error) {
+		return c.BlockByHash(ctx, hash)
+	})
+}
+
+func (p *rpcPool) BlockNumber(ctx context.Context) (uint64, error) {
+	return poolCall(ctx, p, "BlockNumber", func(c ethClientConn) (uint64, error) {
+		return c.BlockNumber(ctx)
+	})
+}
+
+func (p *rpcPool) BalanceAt(ctx context.Context, account common.Address, blockNumber *big.Int) (*big.Int, error) {
+	return poolCall(ctx, p, "BalanceAt", func(c ethClientConn) (*big.Int, error) {
+		return c.BalanceAt(ctx, account, blockNumber)
+	})
+}
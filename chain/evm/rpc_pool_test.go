@@ -0,0 +1,190 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/palomachain/pigeon/config"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeRPCError struct{ code int }
+
+func (e fakeRPCError) Error() string  { return "rpc error" }
+func (e fakeRPCError) ErrorCode() int { return e.code }
+
+func newTestRPCPool(t *testing.T, conns ...*mockEthClientConn) *rpcPool {
+	urls := make([]string, len(conns))
+	byURL := make(map[string]ethClientConn, len(conns))
+	for i, c := range conns {
+		urls[i] = string(rune('a' + i))
+		byURL[urls[i]] = c
+	}
+
+	p, err := newRPCPool(urls, config.RPCFailoverConfig{}, func(url string) (ethClientConn, error) {
+		return byURL[url], nil
+	})
+	require.NoError(t, err)
+
+	// Make sure tests are not affected by background probes.
+	p.probing = true
+	return p
+}
+
+func TestRPCPoolFailover(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("it uses the first endpoint when healthy", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		a.On("BlockNumber", mock.Anything).Return(uint64(10), nil).Once()
+
+		p := newTestRPCPool(t, a, b)
+		height, err := p.BlockNumber(ctx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(10), height)
+	})
+
+	t.Run("it fails over on transport errors", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		a.On("BlockNumber", mock.Anything).Return(uint64(0), errors.New("connection refused")).Once()
+		b.On("BlockNumber", mock.Anything).Return(uint64(11), nil).Once()
+
+		p := newTestRPCPool(t, a, b)
+		height, err := p.BlockNumber(ctx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(11), height)
+	})
+
+	t.Run("it does not fail over on json-rpc errors", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		a.On("EstimateGas", mock.Anything, mock.Anything).Return(uint64(0), fakeRPCError{code: 3}).Once()
+
+		p := newTestRPCPool(t, a, b)
+		_, err := p.EstimateGas(ctx, ethereum.CallMsg{})
+		require.ErrorAs(t, err, &fakeRPCError{})
+	})
+
+	t.Run("it does not fail over on not found errors", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		a.On("TransactionReceipt", mock.Anything, mock.Anything).Return(nil, ethereum.NotFound).Once()
+
+		p := newTestRPCPool(t, a, b)
+		_, err := p.TransactionReceipt(ctx, [32]byte{})
+		require.ErrorIs(t, err, ethereum.NotFound)
+	})
+
+	t.Run("it fails over when rate limited", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		a.On("BlockNumber", mock.Anything).Return(uint64(0), fakeRPCError{code: cRPCLimitExceededCode}).Once()
+		b.On("BlockNumber", mock.Anything).Return(uint64(12), nil).Once()
+
+		p := newTestRPCPool(t, a, b)
+		height, err := p.BlockNumber(ctx)
+		require.NoError(t, err)
+		require.Equal(t, uint64(12), height)
+	})
+
+	t.Run("it returns the last error if all endpoints fail", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		a.On("BlockNumber", mock.Anything).Return(uint64(0), errors.New("a is down")).Once()
+		b.On("BlockNumber", mock.Anything).Return(uint64(0), errors.New("b is down")).Once()
+
+		p := newTestRPCPool(t, a, b)
+		_, err := p.BlockNumber(ctx)
+		require.EqualError(t, err, "b is down")
+	})
+
+	t.Run("it treats a transaction known after failing over as sent", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		tx := ethtypes.NewTx(&ethtypes.LegacyTx{Nonce: 1})
+		a.On("SendTransaction", mock.Anything, tx).Return(errors.New("i/o timeout")).Once()
+		b.On("SendTransaction", mock.Anything, tx).Return(errors.New("nonce too low")).Once()
+		b.On("TransactionByHash", mock.Anything, tx.Hash()).Return(tx, false, nil).Once()
+
+		p := newTestRPCPool(t, a, b)
+		require.NoError(t, p.SendTransaction(ctx, tx))
+	})
+
+	t.Run("it does not treat a known transaction as sent without failing over", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		tx := ethtypes.NewTx(&ethtypes.LegacyTx{Nonce: 1})
+		a.On("SendTransaction", mock.Anything, tx).Return(fakeAlreadyKnownError{}).Once()
+
+		p := newTestRPCPool(t, a, b)
+		require.ErrorIs(t, p.SendTransaction(ctx, tx), fakeAlreadyKnownError{})
+	})
+}
+
+type fakeAlreadyKnownError struct{}
+
+func (fakeAlreadyKnownError) Error() string  { return "already known" }
+func (fakeAlreadyKnownError) ErrorCode() int { return -32000 }
+
+func TestRPCPoolHealthScoring(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("it switches away from an endpoint lagging behind", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		a.On("BlockNumber", mock.Anything).Return(uint64(100), nil).Once()
+		b.On("BlockNumber", mock.Anything).Return(uint64(200), nil).Once()
+
+		p := newTestRPCPool(t, a, b)
+		p.probe(ctx)
+
+		b.On("SuggestGasPrice", mock.Anything).Return(nil, nil).Once()
+		_, err := p.SuggestGasPrice(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "b", p.primaryURL())
+
+		status := p.Status()
+		require.False(t, status[0].Healthy)
+		require.True(t, status[1].Healthy)
+		require.True(t, status[1].Active)
+	})
+
+	t.Run("it switches away from an endpoint with a high error rate", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		p := newTestRPCPool(t, a, b)
+		for range 5 {
+			p.record(p.endpoints[0], 0, true)
+		}
+
+		b.On("BlockNumber", mock.Anything).Return(uint64(1), nil).Once()
+		_, err := p.BlockNumber(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "b", p.primaryURL())
+	})
+
+	t.Run("it switches away from a slow endpoint", func(t *testing.T) {
+		a, b := newMockEthClientConn(t), newMockEthClientConn(t)
+		p := newTestRPCPool(t, a, b)
+		p.endpoints[0].latency = 2 * p.maxLatency
+
+		b.On("BlockNumber", mock.Anything).Return(uint64(1), nil).Once()
+		_, err := p.BlockNumber(ctx)
+		require.NoError(t, err)
+		require.Equal(t, "b", p.primaryURL())
+	})
+
+	t.Run("it keeps using an unhealthy endpoint if there is nothing better", func(t *testing.T) {
+		a := newMockEthClientConn(t)
+		p := newTestRPCPool(t, a)
+		p.endpoints[0].errorRate = 1
+
+		a.On("BlockNumber", mock.Anything).Return(uint64(1), nil).Once()
+		_, err := p.BlockNumber(ctx)
+		require.NoError(t, err)
+	})
+}
+
+func TestRPCURLs(t *testing.T) {
+	cfg := config.ChainClientConfig{
+		BaseRPCURL:  "http://a",
+		BaseRPCURLs: []string{"http://b", "http://a", " ", "http://c"},
+	}
+	require.Equal(t, []string{"http://a", "http://b", "http://c"}, cfg.RPCURLs())
+}
@@ -0,0 +1,82 @@
+package evm
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"fmt"
+	"strings"
+
+	"github.com/VolumeFi/whoops"
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/accounts/abi/bind"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/rpc"
+)
+
+// simulateTx runs a transaction as eth_call against the pending block, so
+// that reverts are found before any gas is paid. Clients without support
+// for pending calls simulate against the latest block. A revert is returned
+// as ErrTxSimulationReverted with the decoded revert reason. All other
+// errors, e.g. from the RPC, are wrapped in ErrTxSimulationFailed, as they
+// say nothing about the transaction.
+func simulateTx(ctx context.Context, c ethClienter, contractABI abi.ABI, msg ethereum.CallMsg) error {
+	var err error
+	if pc, ok := c.(bind.PendingContractCaller); ok {
+		_, err = pc.PendingCallContract(ctx, msg)
+	} else {
+		_, err = c.CallContract(ctx, msg, nil)
+	}
+	if err == nil {
+		return nil
+	}
+
+	reason, ok := revertReason(err, contractABI)
+	if !ok {
+		return whoops.Wrap(err, ErrTxSimulationFailed)
+	}
+	return ErrTxSimulationReverted.Format(reason)
+}
+
+// revertReason returns the reason of a reverted call, or false if err
+// isn't caused by a revert.
+func revertReason(err error, contractABI abi.ABI) (string, bool) {
+	var dataErr rpc.DataError
+	if errors.As(err, &dataErr) {
+		if s, ok := dataErr.ErrorData().(string); ok {
+			if data, decodeErr := hexutil.Decode(s); decodeErr == nil {
+				return decodeRevert(data, contractABI), true
+			}
+		}
+	}
+
+	if strings.Contains(err.Error(), "execution reverted") {
+		return err.Error(), true
+	}
+	return "", false
+}
+
+// decodeRevert decodes revert data, which is either a revert string, a
+// panic or one of the contract's custom errors.
+func decodeRevert(data []byte, contractABI abi.ABI) string {
+	if len(data) == 0 {
+		return "execution reverted"
+	}
+	if reason, err := abi.UnpackRevert(data); err == nil {
+		return reason
+	}
+	if len(data) >= 4 {
+		for name, abiErr := range contractABI.Errors {
+			if !bytes.Equal(abiErr.ID[:4], data[:4]) {
+				continue
+			}
+			args, err := abiErr.Unpack(data)
+			if err != nil {
+				return name
+			}
+			return fmt.Sprintf("%s%v", name, args)
+		}
+	}
+	return "unknown revert data " + hexutil.Encode(data)
+}
@@ -0,0 +1,111 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+const simulateTestABI = `[{"type":"error","name":"MessageExpired","inputs":[{"name":"deadline","type":"uint256"}]}]`
+
+func revertData(t *testing.T, sig string, types []string, args ...any) []byte {
+	var arguments abi.Arguments
+	for _, typ := range types {
+		abiType, err := abi.NewType(typ, "", nil)
+		require.NoError(t, err)
+		arguments = append(arguments, abi.Argument{Type: abiType})
+	}
+	packed, err := arguments.Pack(args...)
+	require.NoError(t, err)
+	return append(crypto.Keccak256([]byte(sig))[:4], packed...)
+}
+
+func TestDecodeRevert(t *testing.T) {
+	contractABI, err := abi.JSON(strings.NewReader(simulateTestABI))
+	require.NoError(t, err)
+
+	for _, tt := range []struct {
+		name string
+		data []byte
+		want string
+	}{
+		{
+			name: "revert string",
+			data: revertData(t, "Error(string)", []string{"string"}, "Invalid signature"),
+			want: "Invalid signature",
+		},
+		{
+			name: "panic",
+			data: revertData(t, "Panic(uint256)", []string{"uint256"}, big.NewInt(0x11)),
+			want: "arithmetic underflow or overflow",
+		},
+		{
+			name: "custom error",
+			data: revertData(t, "MessageExpired(uint256)", []string{"uint256"}, big.NewInt(42)),
+			want: "MessageExpired[42]",
+		},
+		{
+			name: "no data",
+			want: "execution reverted",
+		},
+		{
+			name: "unknown data",
+			data: []byte{0xde, 0xad, 0xbe, 0xef},
+			want: "unknown revert data 0xdeadbeef",
+		},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			require.Equal(t, tt.want, decodeRevert(tt.data, contractABI))
+		})
+	}
+}
+
+func TestSimulateTx(t *testing.T) {
+	ctx := context.Background()
+	msg := ethereum.CallMsg{Data: []byte{1, 2, 3}}
+
+	t.Run("it simulates against the pending block", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		conn.On("PendingCallContract", mock.Anything, msg).Return(nil, nil).Once()
+
+		require.NoError(t, simulateTx(ctx, conn, abi.ABI{}, msg))
+	})
+
+	t.Run("it falls back to the latest block", func(t *testing.T) {
+		client := newMockEthClienter(t)
+		client.On("CallContract", mock.Anything, msg, (*big.Int)(nil)).Return(nil, nil).Once()
+
+		require.NoError(t, simulateTx(ctx, client, abi.ABI{}, msg))
+	})
+
+	t.Run("it decodes reverts", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		data := revertData(t, "Error(string)", []string{"string"}, "Insufficient funds")
+		conn.On("PendingCallContract", mock.Anything, msg).
+			Return(nil, fakeJsonRpcError(hexutil.Encode(data))).Once()
+
+		err := simulateTx(ctx, conn, abi.ABI{}, msg)
+		require.ErrorIs(t, err, ErrTxSimulationReverted)
+		require.Equal(t, "transaction simulation reverted: Insufficient funds", err.Error())
+	})
+
+	t.Run("it wraps other errors", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		fakeErr := errors.New("connection refused")
+		conn.On("PendingCallContract", mock.Anything, msg).Return(nil, fakeErr).Once()
+
+		err := simulateTx(ctx, conn, abi.ABI{}, msg)
+		require.ErrorIs(t, err, fakeErr)
+		require.ErrorIs(t, err, ErrTxSimulationFailed)
+		require.NotErrorIs(t, err, ErrTxSimulationReverted)
+	})
+}
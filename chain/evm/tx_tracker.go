@@ -0,0 +1,352 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/VolumeFi/whoops"
+	"github.com/ethereum/go-ethereum"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	cDefaultTxReplacementTimeout         time.Duration = 3 * time.Minute
+	cDefaultTxReplacementBumpPercent     uint64        = 20
+	cDefaultTxReplacementMaxReplacements int           = 5
+
+	// Nodes reject replacements which don't raise the fee by at least 10%.
+	cMinTxReplacementBumpPercent uint64 = 10
+)
+
+// txSignerFn signs a replacement transaction for the given chain.
+type txSignerFn func(tx *ethtypes.Transaction, chainID *big.Int) (*ethtypes.Transaction, error)
+
+// txTracker watches the transactions sent by a client until they are
+// mined and replaces the ones stuck in the mempool with a transaction
+// using the same nonce and a higher fee. Only transactions which are
+// attested without their hash, such as Skyway batches, may be tracked, as
+// Paloma doesn't accept a new hash for a relayed message.
+type txTracker struct {
+	mu      sync.Mutex
+	pending map[uint64]*trackedTx
+
+	// store persists the tracked transactions under storeKey, so that
+	// they are still watched after a rebuild or restart.
+	store    store.Store
+	storeKey string
+
+	conn  ethClientConn
+	sign  txSignerFn
+	guard *gasGuard
+
+	timeout         time.Duration
+	bumpPercent     uint64
+	maxReplacements int
+
+	now func() time.Time
+}
+
+type trackedTx struct {
+	// txs holds the original transaction followed by its replacements.
+	txs          []*ethtypes.Transaction
+	firstSentAt  time.Time
+	sentAt       time.Time
+	replacements int
+}
+
+func (t *trackedTx) latest() *ethtypes.Transaction {
+	return t.txs[len(t.txs)-1]
+}
+
+func newTxTracker(cfg config.TxReplacementConfig, conn ethClientConn, sign txSignerFn, guard *gasGuard) (*txTracker, error) {
+	if !cfg.Enabled {
+		return nil, nil
+	}
+
+	t := &txTracker{
+		pending:         make(map[uint64]*trackedTx),
+		conn:            conn,
+		sign:            sign,
+		guard:           guard,
+		timeout:         cDefaultTxReplacementTimeout,
+		bumpPercent:     cDefaultTxReplacementBumpPercent,
+		maxReplacements: cDefaultTxReplacementMaxReplacements,
+		now:             time.Now,
+	}
+	if err := parseDurationInto(&t.timeout, cfg.Timeout); err != nil {
+		return nil, err
+	}
+	if cfg.BumpPercent > 0 {
+		if cfg.BumpPercent < cMinTxReplacementBumpPercent {
+			return nil, ErrTxReplacementBumpTooLow.Format(cfg.BumpPercent, cMinTxReplacementBumpPercent)
+		}
+		t.bumpPercent = cfg.BumpPercent
+	}
+	if cfg.MaxReplacements > 0 {
+		t.maxReplacements = cfg.MaxReplacements
+	}
+
+	return t, nil
+}
+
+// track starts watching tx. A nil tracker ignores the transaction.
+func (t *txTracker) track(ctx context.Context, tx *ethtypes.Transaction) {
+	if t == nil || tx == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	now := t.now()
+	t.pending[tx.Nonce()] = &trackedTx{
+		txs:         []*ethtypes.Transaction{tx},
+		firstSentAt: now,
+		sentAt:      now,
+	}
+	t.persistLocked(ctx)
+}
+
+func (t *txTracker) forget(ctx context.Context, nonce uint64) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.pending, nonce)
+	t.persistLocked(ctx)
+}
+
+func (t *txTracker) snapshot() []*trackedTx {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	res := make([]*trackedTx, 0, len(t.pending))
+	for _, tracked := range t.pending {
+		res = append(res, tracked)
+	}
+	sort.Slice(res, func(i, j int) bool {
+		return res[i].latest().Nonce() < res[j].latest().Nonce()
+	})
+	return res
+}
+
+// expiry is how long a transaction is watched at most. It leaves time for
+// all replacements and for the last one to be mined. Transactions which
+// are still not mined by then were most likely dropped, or their nonce was
+// used by a transaction sent elsewhere.
+func (t *txTracker) expiry() time.Duration {
+	return t.timeout * time.Duration(t.maxReplacements+2)
+}
+
+// replaceStuck stops tracking mined transactions and replaces the ones
+// which have been pending for longer than the configured timeout.
+func (t *txTracker) replaceStuck(ctx context.Context) error {
+	if t == nil {
+		return nil
+	}
+
+	var gErr whoops.Group
+	for _, tracked := range t.snapshot() {
+		if ctx.Err() != nil {
+			return ctx.Err()
+		}
+
+		logger := liblog.WithContext(ctx).WithFields(log.Fields{
+			"tx-hash":  tracked.latest().Hash(),
+			"tx-nonce": tracked.latest().Nonce(),
+		})
+
+		mined, err := t.minedTx(ctx, tracked)
+		if err != nil {
+			logger.WithError(err).Warn("failed to look up transaction receipt")
+			gErr.Add(err)
+			continue
+		}
+		if mined != nil {
+			t.forget(ctx, mined.Nonce())
+			if mined.Hash() != tracked.latest().Hash() {
+				logger.WithField("mined-tx-hash", mined.Hash()).Info("earlier transaction was mined instead of its replacement")
+			}
+			continue
+		}
+
+		if t.now().Sub(tracked.firstSentAt) >= t.expiry() {
+			logger.WithField("replacements", tracked.replacements).Warn("transaction wasn't mined in time, no longer watching it")
+			t.forget(ctx, tracked.latest().Nonce())
+			continue
+		}
+		if t.now().Sub(tracked.sentAt) < t.timeout {
+			continue
+		}
+		if tracked.replacements >= t.maxReplacements {
+			logger.WithField("replacements", tracked.replacements).Debug("transaction still pending, but replacement limit was reached")
+			continue
+		}
+
+		gErr.Add(t.replace(ctx, tracked))
+	}
+
+	return gErr.Return()
+}
+
+// minedTx returns the transaction sent for the tracked nonce which made it
+// into a block, if any, and settles its spend with the fee it paid.
+func (t *txTracker) minedTx(ctx context.Context, tracked *trackedTx) (*ethtypes.Transaction, error) {
+	for _, tx := range tracked.txs {
+		receipt, err := t.conn.TransactionReceipt(ctx, tx.Hash())
+		if errors.Is(err, ethereum.NotFound) {
+			continue
+		}
+		if err != nil {
+			return nil, err
+		}
+		if receipt != nil {
+			t.guard.settle(ctx, tx.Nonce(), receiptFee(receipt))
+			return tx, nil
+		}
+	}
+	return nil, nil
+}
+
+func (t *txTracker) replace(ctx context.Context, tracked *trackedTx) error {
+	old := tracked.latest()
+	logger := liblog.WithContext(ctx).WithFields(log.Fields{
+		"tx-hash":  old.Hash(),
+		"tx-nonce": old.Nonce(),
+	})
+
+	bumped, err := t.bumpFees(ctx, old)
+	if err != nil {
+		logger.WithError(err).Error("failed to bump fees of stuck transaction")
+		return err
+	}
+
+	// Only one of the transactions can be mined, so only the additional
+	// cost of the replacement counts against the budget.
+	cost := maxTxCost(bumped)
+	reservation, err := t.guard.reserve(old.Nonce(), txFeeCap(bumped), cost, new(big.Int).Sub(cost, maxTxCost(old)))
+	if err != nil {
+		logger.WithError(err).Warn("not replacing stuck transaction")
+		return nil
+	}
+	defer reservation.refund()
+
+	replacement, err := t.sign(bumped, old.ChainId())
+	if err != nil {
+		logger.WithError(err).Error("failed to sign replacement transaction")
+		return err
+	}
+
+	if err := t.conn.SendTransaction(ctx, replacement); err != nil {
+		if isNonceTooLow(err) {
+			// One of the transactions using this nonce was mined after
+			// all, the next run will find its receipt.
+			logger.Info("stuck transaction was mined before it could be replaced")
+			return nil
+		}
+		logger.WithError(err).Error("failed to send replacement transaction")
+		return err
+	}
+
+	reservation.commit()
+
+	t.mu.Lock()
+	tracked.txs = append(tracked.txs, replacement)
+	tracked.sentAt = t.now()
+	tracked.replacements++
+	t.persistLocked(ctx)
+	t.mu.Unlock()
+
+	logger.WithFields(log.Fields{
+		"replacement-tx-hash": replacement.Hash(),
+		"replacements":        tracked.replacements,
+		"tx-gas-price":        replacement.GasPrice(),
+		"tx-gas-max-price":    replacement.GasFeeCap(),
+		"tx-gas-max-tip":      replacement.GasTipCap(),
+	}).Info("replaced stuck transaction")
+
+	return nil
+}
+
+// bumpFees returns an unsigned copy of tx with its fees raised by the
+// configured percentage, but never below what the node currently
+// suggests.
+func (t *txTracker) bumpFees(ctx context.Context, tx *ethtypes.Transaction) (*ethtypes.Transaction, error) {
+	switch tx.Type() {
+	case ethtypes.DynamicFeeTxType:
+		tip, err := t.conn.SuggestGasTipCap(ctx)
+		if err != nil {
+			return nil, err
+		}
+		tip = maxBig(bumpBig(tx.GasTipCap(), t.bumpPercent), tip)
+		feeCap := maxBig(bumpBig(tx.GasFeeCap(), t.bumpPercent), tip)
+
+		return ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+			ChainID:    tx.ChainId(),
+			Nonce:      tx.Nonce(),
+			GasTipCap:  tip,
+			GasFeeCap:  feeCap,
+			Gas:        tx.Gas(),
+			To:         tx.To(),
+			Value:      tx.Value(),
+			Data:       tx.Data(),
+			AccessList: tx.AccessList(),
+		}), nil
+	case ethtypes.LegacyTxType:
+		price, err := t.conn.SuggestGasPrice(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		return ethtypes.NewTx(&ethtypes.LegacyTx{
+			Nonce:    tx.Nonce(),
+			GasPrice: maxBig(bumpBig(tx.GasPrice(), t.bumpPercent), price),
+			Gas:      tx.Gas(),
+			To:       tx.To(),
+			Value:    tx.Value(),
+			Data:     tx.Data(),
+		}), nil
+	default:
+		return nil, ErrUnsupportedTxType.Format(tx.Type())
+	}
+}
+
+// bumpBig raises v by percent, and by at least one wei.
+func bumpBig(v *big.Int, percent uint64) *big.Int {
+	res := new(big.Int).Mul(v, new(big.Int).SetUint64(100+percent))
+	res.Div(res, big.NewInt(100))
+	if res.Cmp(v) <= 0 {
+		res.Add(v, big.NewInt(1))
+	}
+	return res
+}
+
+func maxBig(a, b *big.Int) *big.Int {
+	if b != nil && b.Cmp(a) > 0 {
+		return new(big.Int).Set(b)
+	}
+	return a
+}
+
+// txFeeCap returns the fee cap of EIP-1559 transactions and the gas price
+// of all others.
+func txFeeCap(tx *ethtypes.Transaction) *big.Int {
+	if tx.Type() == ethtypes.DynamicFeeTxType {
+		return tx.GasFeeCap()
+	}
+	return tx.GasPrice()
+}
+
+// maxTxCost returns the most a transaction may cost in fees.
+func maxTxCost(tx *ethtypes.Transaction) *big.Int {
+	return new(big.Int).Mul(new(big.Int).SetUint64(tx.Gas()), txFeeCap(tx))
+}
+
+func isNonceTooLow(err error) bool {
+	return strings.Contains(err.Error(), "nonce too low")
+}
@@ -0,0 +1,119 @@
+package evm
+
+import (
+	"context"
+	"sort"
+	"time"
+
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/store"
+	log "github.com/sirupsen/logrus"
+)
+
+const txTrackerBucket = "tx-tracker"
+
+// storedTrackedTx is the persisted form of a trackedTx. Transactions are
+// stored in their binary encoding.
+type storedTrackedTx struct {
+	Txs          [][]byte  `json:"txs"`
+	FirstSentAt  time.Time `json:"first-sent-at"`
+	SentAt       time.Time `json:"sent-at"`
+	Replacements int       `json:"replacements"`
+}
+
+// restore loads the transactions watched by a previous tracker of the
+// same chain and signing address from the store and persists them there
+// from now on. A nil tracker does nothing.
+func (t *txTracker) restore(s store.Store, key string) {
+	if t == nil {
+		return
+	}
+
+	logger := liblog.WithContext(context.Background()).WithField("tx-tracker", key)
+
+	var stored []storedTrackedTx
+	found, err := s.Get(txTrackerBucket, key, &stored)
+	if err != nil {
+		logger.WithError(err).Warn("failed to restore watched transactions")
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store, t.storeKey = s, key
+	if !found || err != nil {
+		return
+	}
+
+	for _, st := range stored {
+		tracked := &trackedTx{
+			firstSentAt:  st.FirstSentAt,
+			sentAt:       st.SentAt,
+			replacements: st.Replacements,
+		}
+		for _, raw := range st.Txs {
+			tx := new(ethtypes.Transaction)
+			if err := tx.UnmarshalBinary(raw); err != nil {
+				logger.WithError(err).Warn("failed to decode watched transaction")
+				continue
+			}
+			tracked.txs = append(tracked.txs, tx)
+		}
+		if len(tracked.txs) > 0 {
+			t.pending[tracked.latest().Nonce()] = tracked
+		}
+	}
+}
+
+// persistLocked writes the tracked transactions to the store. t.mu must be
+// held.
+func (t *txTracker) persistLocked(ctx context.Context) {
+	if t.store == nil {
+		return
+	}
+
+	nonces := make([]uint64, 0, len(t.pending))
+	for nonce := range t.pending {
+		nonces = append(nonces, nonce)
+	}
+	sort.Slice(nonces, func(i, j int) bool { return nonces[i] < nonces[j] })
+
+	stored := make([]storedTrackedTx, 0, len(nonces))
+	for _, nonce := range nonces {
+		tracked := t.pending[nonce]
+		st := storedTrackedTx{
+			FirstSentAt:  tracked.firstSentAt,
+			SentAt:       tracked.sentAt,
+			Replacements: tracked.replacements,
+		}
+		for _, tx := range tracked.txs {
+			raw, err := tx.MarshalBinary()
+			if err != nil {
+				liblog.WithContext(ctx).WithError(err).WithFields(log.Fields{
+					"tx-hash":  tx.Hash(),
+					"tx-nonce": tx.Nonce(),
+				}).Warn("failed to encode watched transaction")
+				continue
+			}
+			st.Txs = append(st.Txs, raw)
+		}
+		stored = append(stored, st)
+	}
+
+	if err := t.store.Put(txTrackerBucket, t.storeKey, stored); err != nil {
+		liblog.WithContext(ctx).WithError(err).WithField("tx-tracker", t.storeKey).Warn("failed to persist watched transactions")
+	}
+}
+
+// close stops persisting, so that a tracker which was replaced by a
+// rebuild doesn't overwrite what its successor persists. A nil tracker
+// does nothing.
+func (t *txTracker) close() {
+	if t == nil {
+		return
+	}
+
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	t.store = nil
+}
@@ -0,0 +1,238 @@
+package evm
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+	"time"
+
+	"github.com/ethereum/go-ethereum"
+	"github.com/ethereum/go-ethereum/common"
+	ethtypes "github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/store"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func newTestTxTracker(t *testing.T, conn *mockEthClientConn) (*txTracker, *time.Time) {
+	key, err := crypto.GenerateKey()
+	require.NoError(t, err)
+
+	tr, err := newTxTracker(config.TxReplacementConfig{Enabled: true}, conn, func(tx *ethtypes.Transaction, chainID *big.Int) (*ethtypes.Transaction, error) {
+		return ethtypes.SignTx(tx, ethtypes.LatestSignerForChainID(chainID), key)
+	}, nil)
+	require.NoError(t, err)
+
+	now := time.Unix(1_000_000, 0)
+	tr.now = func() time.Time { return now }
+	return tr, &now
+}
+
+func dynamicFeeTx(nonce uint64) *ethtypes.Transaction {
+	to := common.HexToAddress("0x1")
+	return ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+		ChainID:   big.NewInt(1),
+		Nonce:     nonce,
+		GasTipCap: big.NewInt(100),
+		GasFeeCap: big.NewInt(1000),
+		Gas:       21000,
+		To:        &to,
+	})
+}
+
+func TestNewTxTracker(t *testing.T) {
+	tr, err := newTxTracker(config.TxReplacementConfig{}, nil, nil, nil)
+	require.NoError(t, err)
+	require.Nil(t, tr, "replacements are opt-in")
+
+	_, err = newTxTracker(config.TxReplacementConfig{Enabled: true, BumpPercent: 5}, nil, nil, nil)
+	require.ErrorIs(t, err, ErrTxReplacementBumpTooLow)
+
+	tr, err = newTxTracker(config.TxReplacementConfig{Enabled: true, Timeout: "1m", BumpPercent: 50, MaxReplacements: 2}, nil, nil, nil)
+	require.NoError(t, err)
+	require.Equal(t, time.Minute, tr.timeout)
+	require.Equal(t, uint64(50), tr.bumpPercent)
+	require.Equal(t, 2, tr.maxReplacements)
+}
+
+func TestTxTracker(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("a nil tracker does nothing", func(t *testing.T) {
+		var tr *txTracker
+		tr.track(ctx, dynamicFeeTx(1))
+		require.NoError(t, tr.replaceStuck(ctx))
+	})
+
+	t.Run("it forgets mined transactions", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		tr, _ := newTestTxTracker(t, conn)
+		tx := dynamicFeeTx(1)
+		tr.track(ctx, tx)
+
+		conn.On("TransactionReceipt", mock.Anything, tx.Hash()).Return(&ethtypes.Receipt{}, nil).Once()
+		require.NoError(t, tr.replaceStuck(ctx))
+		require.Empty(t, tr.pending)
+	})
+
+	t.Run("it leaves recent transactions alone", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		tr, now := newTestTxTracker(t, conn)
+		tx := dynamicFeeTx(1)
+		tr.track(ctx, tx)
+		*now = now.Add(time.Minute)
+
+		conn.On("TransactionReceipt", mock.Anything, tx.Hash()).Return(nil, ethereum.NotFound).Once()
+		require.NoError(t, tr.replaceStuck(ctx))
+		require.Len(t, tr.pending, 1)
+	})
+
+	t.Run("it replaces stuck transactions with bumped fees", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		tr, now := newTestTxTracker(t, conn)
+		tx := dynamicFeeTx(1)
+		tr.track(ctx, tx)
+		*now = now.Add(cDefaultTxReplacementTimeout)
+
+		var reported *ethtypes.Transaction
+		conn.On("TransactionReceipt", mock.Anything, tx.Hash()).Return(nil, ethereum.NotFound).Once()
+		conn.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(110), nil).Once()
+		conn.On("SendTransaction", mock.Anything, mock.Anything).Run(func(args mock.Arguments) {
+			reported = args.Get(1).(*ethtypes.Transaction)
+		}).Return(nil).Once()
+		require.NoError(t, tr.replaceStuck(ctx))
+
+		require.NotNil(t, reported)
+		require.Equal(t, tx.Nonce(), reported.Nonce())
+		require.Equal(t, big.NewInt(120), reported.GasTipCap())
+		require.Equal(t, big.NewInt(1200), reported.GasFeeCap())
+		require.Equal(t, tx.Gas(), reported.Gas())
+		require.Equal(t, 1, tr.pending[1].replacements)
+		require.Len(t, tr.pending[1].txs, 2)
+	})
+
+	t.Run("it uses the suggested price if it is higher than the bumped one", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		tr, now := newTestTxTracker(t, conn)
+		to := common.HexToAddress("0x1")
+		tx := ethtypes.NewTx(&ethtypes.LegacyTx{Nonce: 3, GasPrice: big.NewInt(100), Gas: 21000, To: &to})
+		tr.track(ctx, tx)
+		*now = now.Add(cDefaultTxReplacementTimeout)
+
+		conn.On("TransactionReceipt", mock.Anything, tx.Hash()).Return(nil, ethereum.NotFound).Once()
+		conn.On("SuggestGasPrice", mock.Anything).Return(big.NewInt(500), nil).Once()
+		conn.On("SendTransaction", mock.Anything, mock.MatchedBy(func(tx *ethtypes.Transaction) bool {
+			return tx.GasPrice().Cmp(big.NewInt(500)) == 0 && tx.Nonce() == 3
+		})).Return(nil).Once()
+		require.NoError(t, tr.replaceStuck(ctx))
+	})
+
+	t.Run("it stops replacing once the limit is reached", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		tr, now := newTestTxTracker(t, conn)
+		tx := dynamicFeeTx(1)
+		tr.track(ctx, tx)
+		tr.pending[1].replacements = tr.maxReplacements
+		*now = now.Add(cDefaultTxReplacementTimeout)
+
+		conn.On("TransactionReceipt", mock.Anything, tx.Hash()).Return(nil, ethereum.NotFound).Once()
+		require.NoError(t, tr.replaceStuck(ctx))
+	})
+
+	t.Run("it forgets transactions which weren't mined in time", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		tr, now := newTestTxTracker(t, conn)
+		tx := dynamicFeeTx(1)
+		tr.track(ctx, tx)
+		tr.pending[1].replacements = tr.maxReplacements
+		*now = now.Add(tr.expiry())
+
+		conn.On("TransactionReceipt", mock.Anything, tx.Hash()).Return(nil, ethereum.NotFound).Once()
+		require.NoError(t, tr.replaceStuck(ctx))
+		require.Empty(t, tr.pending)
+	})
+
+	t.Run("it forgets the transactions if an earlier one was mined instead of the replacement", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		tr, _ := newTestTxTracker(t, conn)
+		original := dynamicFeeTx(1)
+		replacement := ethtypes.NewTx(&ethtypes.DynamicFeeTx{
+			ChainID:   original.ChainId(),
+			Nonce:     original.Nonce(),
+			GasTipCap: big.NewInt(200),
+			GasFeeCap: big.NewInt(2000),
+			Gas:       original.Gas(),
+			To:        original.To(),
+		})
+		tr.track(ctx, original)
+		tr.pending[1].txs = append(tr.pending[1].txs, replacement)
+
+		conn.On("TransactionReceipt", mock.Anything, original.Hash()).Return(&ethtypes.Receipt{}, nil).Once()
+		require.NoError(t, tr.replaceStuck(ctx))
+		require.Empty(t, tr.pending)
+	})
+
+	t.Run("it returns errors from sending the replacement", func(t *testing.T) {
+		conn := newMockEthClientConn(t)
+		tr, now := newTestTxTracker(t, conn)
+		tx := dynamicFeeTx(1)
+		tr.track(ctx, tx)
+		*now = now.Add(cDefaultTxReplacementTimeout)
+
+		conn.On("TransactionReceipt", mock.Anything, tx.Hash()).Return(nil, ethereum.NotFound).Once()
+		conn.On("SuggestGasTipCap", mock.Anything).Return(big.NewInt(1), nil).Once()
+		conn.On("SendTransaction", mock.Anything, mock.Anything).Return(errors.New("replacement transaction underpriced")).Once()
+		require.Error(t, tr.replaceStuck(ctx))
+		require.Equal(t, 0, tr.pending[1].replacements)
+	})
+}
+
+func TestTxTrackerState(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemory()
+
+	tr, now := newTestTxTracker(t, newMockEthClientConn(t))
+	tr.restore(s, "eth-main/0x1")
+	tx := dynamicFeeTx(1)
+	tr.track(ctx, tx)
+	tr.pending[1].txs = append(tr.pending[1].txs, dynamicFeeTx(1))
+	tr.pending[1].replacements = 1
+	tr.track(ctx, dynamicFeeTx(2))
+
+	t.Run("a rebuilt tracker keeps watching the transactions", func(t *testing.T) {
+		next, _ := newTestTxTracker(t, newMockEthClientConn(t))
+		next.restore(s, "eth-main/0x1")
+
+		require.Len(t, next.pending, 2)
+		require.Equal(t, tx.Hash(), next.pending[1].txs[0].Hash())
+		require.Len(t, next.pending[2].txs, 1)
+		require.True(t, next.pending[1].firstSentAt.Equal(*now))
+	})
+
+	t.Run("forgotten transactions are removed from the store", func(t *testing.T) {
+		tr.forget(ctx, 1)
+
+		next, _ := newTestTxTracker(t, newMockEthClientConn(t))
+		next.restore(s, "eth-main/0x1")
+		require.Len(t, next.pending, 1)
+		require.Contains(t, next.pending, uint64(2))
+	})
+
+	t.Run("a closed tracker stops persisting", func(t *testing.T) {
+		tr.close()
+		tr.forget(ctx, 2)
+
+		next, _ := newTestTxTracker(t, newMockEthClientConn(t))
+		next.restore(s, "eth-main/0x1")
+		require.Len(t, next.pending, 1)
+	})
+
+	t.Run("other chains aren't affected", func(t *testing.T) {
+		other, _ := newTestTxTracker(t, newMockEthClientConn(t))
+		other.restore(s, "eth-test/0x1")
+		require.Empty(t, other.pending)
+	})
+}
@@ -0,0 +1,186 @@
+package paloma
+
+import (
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/palomachain/pigeon/internal/metrics"
+)
+
+const (
+	queryChainInfos        = "chain-infos"
+	querySnapshot          = "snapshot"
+	queryPublishedSnapshot = "latest-published-snapshot"
+	queryEVMValset         = "evm-valset"
+)
+
+// cacheMaxEntries bounds the size of the cache. Once it is reached, expired
+// entries are evicted first, then the ones closest to expiring, and then
+// the least recently used of the ones without a TTL.
+const cacheMaxEntries = 1024
+
+// cachePolicy defines how long a query result stays in the cache. A zero
+// TTL caches the result until it is evicted to make room. Results of
+// policies with perBlock set are evicted as soon as a new Paloma block is
+// observed.
+type cachePolicy struct {
+	ttl      time.Duration
+	perBlock bool
+}
+
+var (
+	// cacheImmutable is used for data which can never change once it
+	// exists on Paloma, such as valsets and snapshots with a known ID.
+	// It never expires, but old IDs are rarely queried again, so they are
+	// the first to be evicted.
+	cacheImmutable = cachePolicy{}
+
+	cacheChainInfos = cachePolicy{ttl: 30 * time.Second, perBlock: true}
+	cacheLatest     = cachePolicy{ttl: 10 * time.Second, perBlock: true}
+)
+
+// snapshotCachePolicy caches snapshots and valsets with a known ID forever,
+// while ID 0 refers to the latest one.
+func snapshotCachePolicy(id uint64) cachePolicy {
+	if id == 0 {
+		return cacheLatest
+	}
+	return cacheImmutable
+}
+
+type cacheEntry struct {
+	value    any
+	expires  time.Time
+	perBlock bool
+	// used is the access counter of the cache when the entry was last
+	// used.
+	used uint64
+}
+
+// queryCache caches the results of Paloma queries which are run very often,
+// e.g. on every tick of every relayer loop. Errors are never cached. Cached
+// values are shared between callers and must be treated as read-only.
+type queryCache struct {
+	mu          sync.Mutex
+	entries     map[string]cacheEntry
+	accesses    uint64
+	blockHeight int64
+	now         func() time.Time
+}
+
+func newQueryCache() *queryCache {
+	return &queryCache{
+		entries: make(map[string]cacheEntry),
+		now:     time.Now,
+	}
+}
+
+// observeBlockHeight evicts all per-block entries once a new Paloma block
+// was seen.
+func (c *queryCache) observeBlockHeight(height int64) {
+	if c == nil {
+		return
+	}
+
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	if height <= c.blockHeight {
+		return
+	}
+	c.blockHeight = height
+	for k, v := range c.entries {
+		if v.perBlock {
+			delete(c.entries, k)
+		}
+	}
+}
+
+func (c *queryCache) get(key string) (any, bool) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	e, ok := c.entries[key]
+	if !ok {
+		return nil, false
+	}
+	if !e.expires.IsZero() && c.now().After(e.expires) {
+		delete(c.entries, key)
+		return nil, false
+	}
+	c.accesses++
+	e.used = c.accesses
+	c.entries[key] = e
+	return e.value, true
+}
+
+func (c *queryCache) set(key string, value any, p cachePolicy) {
+	c.mu.Lock()
+	defer c.mu.Unlock()
+	c.accesses++
+	e := cacheEntry{value: value, perBlock: p.perBlock, used: c.accesses}
+	if p.ttl > 0 {
+		e.expires = c.now().Add(p.ttl)
+	}
+	if _, ok := c.entries[key]; !ok && len(c.entries) >= cacheMaxEntries {
+		c.evictLocked()
+	}
+	c.entries[key] = e
+}
+
+// evictLocked makes room for a new entry.
+func (c *queryCache) evictLocked() {
+	now := c.now()
+	for k, v := range c.entries {
+		if !v.expires.IsZero() && now.After(v.expires) {
+			delete(c.entries, k)
+		}
+	}
+	if len(c.entries) < cacheMaxEntries {
+		return
+	}
+
+	var oldest string
+	var oldestEntry cacheEntry
+	for k, v := range c.entries {
+		if oldest == "" || evictsBefore(v, oldestEntry) {
+			oldest, oldestEntry = k, v
+		}
+	}
+	delete(c.entries, oldest)
+}
+
+// evictsBefore reports whether a should be evicted before b. Entries with
+// a TTL go first, closest to expiring first. Entries without one go last,
+// least recently used first.
+func evictsBefore(a, b cacheEntry) bool {
+	switch {
+	case a.expires.IsZero() != b.expires.IsZero():
+		return !a.expires.IsZero()
+	case !a.expires.IsZero():
+		return a.expires.Before(b.expires)
+	default:
+		return a.used < b.used
+	}
+}
+
+// cached returns the cached result of a query or runs it and caches its
+// result according to the given policy. A nil cache runs every query.
+func cached[T any](c *queryCache, query string, p cachePolicy, fn func() (T, error), keyParts ...any) (T, error) {
+	if c == nil {
+		return fn()
+	}
+
+	key := fmt.Sprintf("%s%v", query, keyParts)
+	if v, ok := c.get(key); ok {
+		metrics.ObserveQueryCache(query, true)
+		return v.(T), nil
+	}
+	metrics.ObserveQueryCache(query, false)
+
+	res, err := fn()
+	if err != nil {
+		return res, err
+	}
+	c.set(key, res, p)
+	return res, nil
+}
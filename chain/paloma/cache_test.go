@@ -0,0 +1,148 @@
+package paloma
+
+import (
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestQueryCache(t *testing.T) {
+	now := time.Now()
+	c := newQueryCache()
+	c.now = func() time.Time { return now }
+
+	var calls int
+	query := func() (int, error) {
+		calls++
+		return calls, nil
+	}
+
+	t.Run("results are cached until the TTL expires", func(t *testing.T) {
+		calls = 0
+		p := cachePolicy{ttl: time.Second}
+
+		v, err := cached(c, "ttl", p, query)
+		require.NoError(t, err)
+		assert.Equal(t, 1, v)
+
+		v, _ = cached(c, "ttl", p, query)
+		assert.Equal(t, 1, v)
+
+		now = now.Add(2 * time.Second)
+		v, _ = cached(c, "ttl", p, query)
+		assert.Equal(t, 2, v)
+	})
+
+	t.Run("results are cached per key", func(t *testing.T) {
+		calls = 0
+		v1, _ := cached(c, "keys", cacheImmutable, query, uint64(1))
+		v2, _ := cached(c, "keys", cacheImmutable, query, uint64(2))
+		v1again, _ := cached(c, "keys", cacheImmutable, query, uint64(1))
+
+		assert.Equal(t, 1, v1)
+		assert.Equal(t, 2, v2)
+		assert.Equal(t, 1, v1again)
+	})
+
+	t.Run("per block results are evicted on new blocks", func(t *testing.T) {
+		calls = 0
+		perBlock := cachePolicy{ttl: time.Hour, perBlock: true}
+
+		c.observeBlockHeight(10)
+		v, _ := cached(c, "block", perBlock, query)
+		assert.Equal(t, 1, v)
+		immutable, _ := cached(c, "immutable", cacheImmutable, query)
+		assert.Equal(t, 2, immutable)
+
+		c.observeBlockHeight(10)
+		v, _ = cached(c, "block", perBlock, query)
+		assert.Equal(t, 1, v)
+
+		c.observeBlockHeight(11)
+		v, _ = cached(c, "block", perBlock, query)
+		assert.Equal(t, 3, v)
+
+		now = now.Add(24 * time.Hour)
+		immutable, _ = cached(c, "immutable", cacheImmutable, query)
+		assert.Equal(t, 2, immutable, "immutable results never expire")
+	})
+
+	t.Run("errors are not cached", func(t *testing.T) {
+		errQuery := errors.New("boom")
+		_, err := cached(c, "err", cacheImmutable, func() (int, error) { return 0, errQuery })
+		assert.ErrorIs(t, err, errQuery)
+
+		v, err := cached(c, "err", cacheImmutable, func() (int, error) { return 42, nil })
+		require.NoError(t, err)
+		assert.Equal(t, 42, v)
+	})
+
+	t.Run("a nil cache runs every query", func(t *testing.T) {
+		calls = 0
+		var nilCache *queryCache
+		cached(nilCache, "nil", cacheImmutable, query)
+		v, _ := cached(nilCache, "nil", cacheImmutable, query)
+		assert.Equal(t, 2, v)
+		nilCache.observeBlockHeight(1)
+	})
+
+	t.Run("the cache is bounded", func(t *testing.T) {
+		c := newQueryCache()
+		c.now = func() time.Time { return now }
+		for i := 0; i < cacheMaxEntries; i++ {
+			c.set(fmt.Sprint(i), i, cachePolicy{ttl: time.Duration(i+1) * time.Second})
+		}
+
+		c.set("new", 0, cacheImmutable)
+		assert.Len(t, c.entries, cacheMaxEntries)
+		_, ok := c.get("0")
+		assert.False(t, ok, "the entry closest to expiring is evicted")
+		_, ok = c.get("new")
+		assert.True(t, ok)
+
+		now = now.Add(time.Duration(cacheMaxEntries/2)*time.Second + time.Millisecond)
+		c.set("newer", 0, cacheImmutable)
+		assert.Len(t, c.entries, cacheMaxEntries/2+2)
+	})
+
+	t.Run("the least recently used immutable result is evicted", func(t *testing.T) {
+		c := newQueryCache()
+		c.now = func() time.Time { return now }
+		for i := 0; i < cacheMaxEntries; i++ {
+			c.set(fmt.Sprint(i), i, cacheImmutable)
+		}
+		_, ok := c.get("0")
+		require.True(t, ok)
+
+		c.set("new", 0, cacheImmutable)
+		assert.Len(t, c.entries, cacheMaxEntries)
+		_, ok = c.get("1")
+		assert.False(t, ok, "the least recently used entry is evicted")
+		_, ok = c.get("0")
+		assert.True(t, ok, "recently used entries are kept")
+	})
+
+	t.Run("results with a TTL are evicted before immutable ones", func(t *testing.T) {
+		c := newQueryCache()
+		c.now = func() time.Time { return now }
+		c.set("ttl", 0, cachePolicy{ttl: time.Hour})
+		for i := 1; i < cacheMaxEntries; i++ {
+			c.set(fmt.Sprint(i), i, cacheImmutable)
+		}
+
+		c.set("new", 0, cacheImmutable)
+		_, ok := c.get("ttl")
+		assert.False(t, ok)
+		_, ok = c.get("1")
+		assert.True(t, ok)
+	})
+
+	t.Run("snapshots with a known ID are immutable", func(t *testing.T) {
+		assert.Equal(t, cacheLatest, snapshotCachePolicy(0))
+		assert.Equal(t, cacheImmutable, snapshotCachePolicy(5))
+	})
+}
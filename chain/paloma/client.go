@@ -3,7 +3,6 @@ package paloma
 import (
 	"context"
 
-	"github.com/VolumeFi/whoops"
 	coretypes "github.com/cometbft/cometbft/rpc/core/types"
 	codectypes "github.com/cosmos/cosmos-sdk/codec/types"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
@@ -38,9 +37,11 @@ type Client struct {
 	GRPCClient   grpc.ClientConn
 
 	ic            IonClient
+	rpcFailover   RPCFailover
 	unpacker      codectypes.AnyUnpacker
 	messageSender MessageSender
 	sendingOpts   []ion.SendMsgOption
+	cache         *queryCache
 
 	creator        string
 	creatorValoper string
@@ -54,6 +55,7 @@ func NewClient(cfg config.Paloma, grpcWrapper grpc.ClientConn, ion IonClient, se
 		ic:            ion,
 		unpacker:      unpacker,
 		messageSender: sender,
+		cache:         newQueryCache(),
 	}).init()
 }
 
@@ -65,6 +67,13 @@ func (c *Client) init() *Client {
 	return c
 }
 
+// WithRPCFailover makes status queries try all known Paloma RPC endpoints
+// before reporting Paloma as being down.
+func (c *Client) WithRPCFailover(f RPCFailover) *Client {
+	c.rpcFailover = f
+	return c
+}
+
 type BroadcastMessageSignatureIn struct {
 	ID              uint64
 	QueueTypeName   string
@@ -79,7 +88,7 @@ func (c *Client) BroadcastMessageSignatures(ctx context.Context, signatures ...B
 }
 
 func (c *Client) BlockHeight(ctx context.Context) (int64, error) {
-	res, err := c.ic.Status(ctx)
+	res, err := c.Status(ctx)
 	if err != nil {
 		return 0, err
 	}
@@ -210,26 +219,25 @@ func (c *Client) KeepValidatorAlive(ctx context.Context, appVersion string) erro
 }
 
 func (c *Client) Status(ctx context.Context) (*coretypes.ResultStatus, error) {
-	res, err := c.ic.Status(ctx)
+	var res *coretypes.ResultStatus
+	err := withFailover(ctx, c.rpcFailover, func() (err error) {
+		res, err = c.ic.Status(ctx)
+		return err
+	})
 	if err != nil {
 		return nil, err
 	}
 
+	c.cache.observeBlockHeight(res.SyncInfo.LatestBlockHeight)
+
 	return res, nil
 }
 
+// PalomaStatus returns ErrPalomaIsDown only if none of the configured
+// Paloma RPC endpoints can be reached.
 func (c *Client) PalomaStatus(ctx context.Context) error {
-	res, err := c.Status(ctx)
-
-	if IsPalomaDown(err) {
-		return whoops.Wrap(ErrPalomaIsDown, err)
-	}
-
-	if err != nil {
-		return err
-	}
-	_ = res
-	return nil
+	_, err := c.Status(ctx)
+	return err
 }
 
 func (c *Client) GetValidator(ctx context.Context) (*stakingtypes.Validator, error) {
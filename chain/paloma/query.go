@@ -93,34 +93,38 @@ func (c *Client) QueryValidatorInfo(ctx context.Context) ([]*valset.ExternalChai
 
 // QueryGetSnapshotByID returns the snapshot by id. If the EventNonce is zero, then it returns the last snapshot.
 func (c *Client) QueryGetSnapshotByID(ctx context.Context, id uint64) (*valset.Snapshot, error) {
-	qc := valset.NewQueryClient(c.GRPCClient)
-	snapshotRes, err := qc.GetSnapshotByID(ctx, &valset.QueryGetSnapshotByIDRequest{
-		SnapshotId: id,
-	})
-	if err != nil {
-		if strings.Contains(err.Error(), "item not found in store") {
-			return nil, whoops.Enrich(
-				chain.ErrNotFound,
-				chain.EnrichedItemType.Val("snapshot"),
-				chain.EnrichedID.Val(id),
-			)
+	return cached(c.cache, querySnapshot, snapshotCachePolicy(id), func() (*valset.Snapshot, error) {
+		qc := valset.NewQueryClient(c.GRPCClient)
+		snapshotRes, err := qc.GetSnapshotByID(ctx, &valset.QueryGetSnapshotByIDRequest{
+			SnapshotId: id,
+		})
+		if err != nil {
+			if strings.Contains(err.Error(), "item not found in store") {
+				return nil, whoops.Enrich(
+					chain.ErrNotFound,
+					chain.EnrichedItemType.Val("snapshot"),
+					chain.EnrichedID.Val(id),
+				)
+			}
+			return nil, err
 		}
-		return nil, err
-	}
 
-	return snapshotRes.Snapshot, nil
+		return snapshotRes.Snapshot, nil
+	}, id)
 }
 
 func (c *Client) QueryGetLatestPublishedSnapshot(ctx context.Context, chainReferenceID string) (*valset.Snapshot, error) {
-	qc := valset.NewQueryClient(c.GRPCClient)
-	res, err := qc.GetLatestPublishedSnapshot(ctx, &valset.QueryGetLatestPublishedSnapshotRequest{
-		ChainReferenceID: chainReferenceID,
-	})
-	if err != nil {
-		return nil, err
-	}
+	return cached(c.cache, queryPublishedSnapshot, cacheLatest, func() (*valset.Snapshot, error) {
+		qc := valset.NewQueryClient(c.GRPCClient)
+		res, err := qc.GetLatestPublishedSnapshot(ctx, &valset.QueryGetLatestPublishedSnapshotRequest{
+			ChainReferenceID: chainReferenceID,
+		})
+		if err != nil {
+			return nil, err
+		}
 
-	return res.Snapshot, nil
+		return res.Snapshot, nil
+	}, chainReferenceID)
 }
 
 func (c *Client) QueryLastObservedSkywayNonceByAddr(ctx context.Context, chainReferenceID string, orchestrator string) (uint64, error) {
@@ -148,42 +152,46 @@ func (c *Client) QueryBatchRequestByNonce(ctx context.Context, nonce uint64, con
 }
 
 func (c *Client) QueryGetEVMValsetByID(ctx context.Context, id uint64, chainReferenceID string) (*evm.Valset, error) {
-	logger := liblog.WithContext(ctx)
-	qc := evm.NewQueryClient(c.GRPCClient)
-	valsetRes, err := qc.GetValsetByID(ctx, &evm.QueryGetValsetByIDRequest{
-		ValsetID:         id,
-		ChainReferenceID: chainReferenceID,
-	})
-	logger.WithFields(logrus.Fields{
-		"valset-length":      len(valsetRes.Valset.Validators),
-		"power-length":       len(valsetRes.Valset.Powers),
-		"valset-id-out":      valsetRes.Valset.ValsetID,
-		"valset-id-in":       id,
-		"chain-reference-id": chainReferenceID,
-	}).Debug("got valset by id")
-	if err != nil {
-		if strings.Contains(err.Error(), "item not found in store") {
-			return nil, whoops.Enrich(
-				chain.ErrNotFound,
-				chain.EnrichedChainReferenceID.Val(chainReferenceID),
-				chain.EnrichedID.Val(id),
-				chain.EnrichedItemType.Val("valset"),
-			)
+	return cached(c.cache, queryEVMValset, snapshotCachePolicy(id), func() (*evm.Valset, error) {
+		logger := liblog.WithContext(ctx)
+		qc := evm.NewQueryClient(c.GRPCClient)
+		valsetRes, err := qc.GetValsetByID(ctx, &evm.QueryGetValsetByIDRequest{
+			ValsetID:         id,
+			ChainReferenceID: chainReferenceID,
+		})
+		logger.WithFields(logrus.Fields{
+			"valset-length":      len(valsetRes.Valset.Validators),
+			"power-length":       len(valsetRes.Valset.Powers),
+			"valset-id-out":      valsetRes.Valset.ValsetID,
+			"valset-id-in":       id,
+			"chain-reference-id": chainReferenceID,
+		}).Debug("got valset by id")
+		if err != nil {
+			if strings.Contains(err.Error(), "item not found in store") {
+				return nil, whoops.Enrich(
+					chain.ErrNotFound,
+					chain.EnrichedChainReferenceID.Val(chainReferenceID),
+					chain.EnrichedID.Val(id),
+					chain.EnrichedItemType.Val("valset"),
+				)
+			}
+			return nil, err
 		}
-		return nil, err
-	}
 
-	return valsetRes.Valset, nil
+		return valsetRes.Valset, nil
+	}, id, chainReferenceID)
 }
 
 func (c *Client) QueryGetEVMChainInfos(ctx context.Context) ([]*evm.ChainInfo, error) {
-	qc := evm.NewQueryClient(c.GRPCClient)
-	chainInfosRes, err := qc.ChainsInfos(ctx, &evm.QueryChainsInfosRequest{})
-	if err != nil {
-		return nil, err
-	}
+	return cached(c.cache, queryChainInfos, cacheChainInfos, func() ([]*evm.ChainInfo, error) {
+		qc := evm.NewQueryClient(c.GRPCClient)
+		chainInfosRes, err := qc.ChainsInfos(ctx, &evm.QueryChainsInfosRequest{})
+		if err != nil {
+			return nil, err
+		}
 
-	return chainInfosRes.ChainsInfos, nil
+		return chainInfosRes.ChainsInfos, nil
+	})
 }
 
 func (c *Client) QueryGetValidatorAliveUntilBlockHeight(ctx context.Context) (int64, error) {
@@ -2,6 +2,7 @@ package paloma
 
 import (
 	"context"
+	"errors"
 	"fmt"
 	"reflect"
 	"sync"
@@ -11,7 +12,9 @@ import (
 	"github.com/cosmos/gogoproto/grpc"
 	vtypes "github.com/palomachain/paloma/v2/x/valset/types"
 	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/metrics"
 	"github.com/palomachain/pigeon/util/ion"
+	log "github.com/sirupsen/logrus"
 	ggrpc "google.golang.org/grpc"
 )
 
@@ -21,6 +24,21 @@ var _ MessageSender = &PalomaMessageSender{}
 
 type GRPCClientWrapper struct {
 	W grpc.ClientConn
+	F RPCFailover
+}
+
+// RPCFailover is implemented by clients which are able to switch
+// to a different Paloma RPC endpoint once the active one goes down.
+type RPCFailover interface {
+	ActiveRPCEndpoint() string
+	RPCEndpointCount() int
+	FailoverRPCEndpoint(ctx context.Context, failed string)
+}
+
+// TxLookup is implemented by clients which are able to tell whether
+// Paloma knows a transaction.
+type TxLookup interface {
+	IsTxKnown(ctx context.Context, hash []byte) (bool, error)
 }
 
 type KeyRotator interface {
@@ -32,25 +50,67 @@ type PalomaMessageSender struct {
 	W          MessageSender
 	GetCreator func() string
 	GetSigner  func() string
+	F          RPCFailover
+	T          TxLookup
 	m          *sync.Mutex
+
+	// dryRun makes the sender log messages instead of broadcasting them.
+	dryRun bool
+}
+
+// withFailover runs fn and retries it against the next Paloma RPC endpoint
+// for as long as it fails due to Paloma being unreachable. ErrPalomaIsDown
+// is only returned after all endpoints have been tried.
+func withFailover(ctx context.Context, f RPCFailover, fn func() error) error {
+	attempts := 1
+	if f != nil {
+		attempts = f.RPCEndpointCount()
+	}
+
+	var err error
+	for range attempts {
+		var endpoint string
+		if f != nil {
+			endpoint = f.ActiveRPCEndpoint()
+		}
+
+		err = fn()
+		if !IsPalomaDown(err) {
+			return err
+		}
+
+		if f != nil {
+			f.FailoverRPCEndpoint(ctx, endpoint)
+		}
+	}
+
+	return whoops.Wrap(ErrPalomaIsDown, err)
 }
 
+// broadcastTxMethod isn't retried by the gRPC wrapper, as a failed
+// broadcast may still have reached the chain. See sendWithFailover.
+const broadcastTxMethod = "/cosmos.tx.v1beta1.Service/BroadcastTx"
+
 func (g GRPCClientWrapper) Invoke(ctx context.Context, method string, args, reply interface{}, opts ...ggrpc.CallOption) error {
-	err := g.W.Invoke(ctx, method, args, reply, opts...)
-	if IsPalomaDown(err) {
-		return whoops.Wrap(ErrPalomaIsDown, err)
+	if method == broadcastTxMethod {
+		return g.W.Invoke(ctx, method, args, reply, opts...)
 	}
-	return err
+	return withFailover(ctx, g.F, func() error {
+		return g.W.Invoke(ctx, method, args, reply, opts...)
+	})
 }
 
 func (g GRPCClientWrapper) NewStream(ctx context.Context, desc *ggrpc.StreamDesc, method string, opts ...ggrpc.CallOption) (ggrpc.ClientStream, error) {
-	stream, err := g.W.NewStream(ctx, desc, method, opts...)
-
-	if IsPalomaDown(err) {
-		return nil, whoops.Wrap(ErrPalomaIsDown, err)
+	var stream ggrpc.ClientStream
+	err := withFailover(ctx, g.F, func() (err error) {
+		stream, err = g.W.NewStream(ctx, desc, method, opts...)
+		return err
+	})
+	if err != nil {
+		return nil, err
 	}
 
-	return stream, err
+	return stream, nil
 }
 
 func NewPalomaMessageSender(R KeyRotator, W MessageSender) *PalomaMessageSender {
@@ -71,6 +131,23 @@ func (m *PalomaMessageSender) WithSignerProvider(p func() string) *PalomaMessage
 	return m
 }
 
+func (m *PalomaMessageSender) WithRPCFailover(f RPCFailover) *PalomaMessageSender {
+	m.F = f
+	return m
+}
+
+// WithTxLookup allows broadcasts which failed while being sent to fail
+// over, once the next endpoint confirms that the transaction is unknown.
+func (m *PalomaMessageSender) WithTxLookup(t TxLookup) *PalomaMessageSender {
+	m.T = t
+	return m
+}
+
+func (m *PalomaMessageSender) WithDryRun(dryRun bool) *PalomaMessageSender {
+	m.dryRun = dryRun
+	return m
+}
+
 func (m *PalomaMessageSender) SendMsg(ctx context.Context, msg sdk.Msg, memo string, opts ...ion.SendMsgOption) (*sdk.TxResponse, error) {
 	logger := liblog.WithContext(ctx).WithField("component", "message-sender")
 
@@ -91,15 +168,103 @@ func (m *PalomaMessageSender) SendMsg(ctx context.Context, msg sdk.Msg, memo str
 		return nil, fmt.Errorf("failed to inject metadata: %w", err)
 	}
 
-	logger.WithField("msg", msg.String()).Debug("Sending message...")
-	res, err := m.W.SendMsg(ctx, msg, memo, opts...)
-	if IsPalomaDown(err) {
-		return nil, whoops.Wrap(ErrPalomaIsDown, err)
+	if m.dryRun {
+		logger.WithFields(log.Fields{
+			"msg-type": sdk.MsgTypeURL(msg),
+			"msg":      msg.String(),
+		}).Info("Dry run: skipping message broadcast.")
+		return &sdk.TxResponse{}, nil
 	}
 
+	logger.WithField("msg", msg.String()).Debug("Sending message...")
+	res, err := m.sendWithFailover(ctx, msg, memo, opts...)
+	recordBroadcast(msg, err)
+
 	return res, err
 }
 
+// sendWithFailover sends msg and retries it against the next Paloma RPC
+// endpoint for as long as it fails due to Paloma being unreachable. Unlike
+// queries, a broadcast may have reached the chain although it failed, so
+// it's only retried if it failed before the transaction was broadcast, or
+// if the next endpoint confirms that the transaction is unknown. The retry
+// signs the message again with a fresh sequence, so at most one of the
+// transactions can be included.
+func (m *PalomaMessageSender) sendWithFailover(ctx context.Context, msg sdk.Msg, memo string, opts ...ion.SendMsgOption) (*sdk.TxResponse, error) {
+	attempts := 1
+	if m.F != nil {
+		attempts = m.F.RPCEndpointCount()
+	}
+
+	var res *sdk.TxResponse
+	var err error
+	for attempt := 1; ; attempt++ {
+		var endpoint string
+		if m.F != nil {
+			endpoint = m.F.ActiveRPCEndpoint()
+		}
+
+		res, err = m.W.SendMsg(ctx, msg, memo, opts...)
+		if !IsPalomaDown(err) {
+			return res, err
+		}
+		if attempt >= attempts {
+			break
+		}
+
+		m.F.FailoverRPCEndpoint(ctx, endpoint)
+		if !m.canResend(ctx, err) {
+			break
+		}
+	}
+
+	return res, whoops.Wrap(ErrPalomaIsDown, err)
+}
+
+// canResend reports whether a message whose broadcast failed with err can
+// be sent again without risking a duplicate.
+func (m *PalomaMessageSender) canResend(ctx context.Context, err error) bool {
+	var broadcastErr *ion.BroadcastError
+	if !errors.As(err, &broadcastErr) {
+		// The transaction was never broadcast.
+		return true
+	}
+
+	logger := liblog.WithContext(ctx).WithField("tx-hash", fmt.Sprintf("%X", broadcastErr.TxHash))
+	if m.T == nil {
+		logger.Warn("Broadcast failed, not retrying as the transaction can't be looked up.")
+		return false
+	}
+
+	known, lookupErr := m.T.IsTxKnown(ctx, broadcastErr.TxHash)
+	if lookupErr != nil {
+		logger.WithError(lookupErr).Warn("Broadcast failed, not retrying as the transaction lookup failed.")
+		return false
+	}
+	if known {
+		logger.Warn("Broadcast failed, but the transaction reached the chain.")
+		return false
+	}
+	return true
+}
+
+func recordBroadcast(msg sdk.Msg, err error) {
+	outcome := metrics.OutcomeSuccess
+	switch {
+	case errors.Is(err, ErrPalomaIsDown):
+		outcome = metrics.OutcomePalomaDown
+	case err != nil:
+		outcome = metrics.OutcomeFailure
+	}
+
+	var chainReferenceID string
+	if m, ok := msg.(interface{ GetChainReferenceId() string }); ok {
+		chainReferenceID = m.GetChainReferenceId()
+	}
+
+	metrics.AddPalomaBroadcast(chainReferenceID, sdk.MsgTypeURL(msg), outcome)
+}
+
 func tryInjectMetadata(msg sdk.Msg, md vtypes.MsgMetadata) error {
 	val := reflect.ValueOf(msg)
 	if val.Kind() == reflect.Ptr {
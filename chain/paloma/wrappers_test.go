@@ -2,6 +2,8 @@ package paloma_test
 
 import (
 	"context"
+	"errors"
+	"net"
 	"reflect"
 	"sync"
 	"testing"
@@ -12,6 +14,7 @@ import (
 	"github.com/palomachain/pigeon/chain/paloma"
 	"github.com/palomachain/pigeon/util/ion"
 	"github.com/stretchr/testify/require"
+	ggrpc "google.golang.org/grpc"
 )
 
 var (
@@ -103,6 +106,25 @@ func Test_PalomaMessageSender_SendMsg(t *testing.T) {
 		require.Equal(t, []string{signer}, msg.GetMetadata().GetSigners(), "must inject signer address")
 	})
 
+	t.Run("must not send messages in dry run mode", func(t *testing.T) {
+		ctx := context.Background()
+		sender := &mockMsgSender{t: t}
+		testee := paloma.NewPalomaMessageSender(&mockKeyRotator{}, sender).
+			WithCreatorProvider(func() string { return "creator" }).
+			WithSignerProvider(func() string { return "signer" }).
+			WithDryRun(true)
+
+		msg := &palomatypes.MsgAddStatusUpdate{
+			Status: "bar",
+			Level:  palomatypes.MsgAddStatusUpdate_LEVEL_INFO,
+		}
+
+		res, err := testee.SendMsg(ctx, msg, "")
+		require.NoError(t, err)
+		require.NotNil(t, res)
+		require.Nil(t, sender.calledMsg, "must not send the message")
+	})
+
 	t.Run("must not reuse same key when called concurrently", func(t *testing.T) {
 		ctx := context.Background()
 		sender := &mockMsgSender{t: t}
@@ -140,3 +162,164 @@ func Test_PalomaMessageSender_SendMsg(t *testing.T) {
 		}
 	})
 }
+
+type mockRPCFailover struct {
+	endpoints []string
+	active    int
+}
+
+func (m *mockRPCFailover) ActiveRPCEndpoint() string { return m.endpoints[m.active] }
+func (m *mockRPCFailover) RPCEndpointCount() int     { return len(m.endpoints) }
+
+func (m *mockRPCFailover) FailoverRPCEndpoint(_ context.Context, failed string) {
+	if m.ActiveRPCEndpoint() == failed {
+		m.active = (m.active + 1) % len(m.endpoints)
+	}
+}
+
+type mockClientConn struct {
+	down map[string]bool
+	f    *mockRPCFailover
+	used []string
+}
+
+func (m *mockClientConn) Invoke(context.Context, string, interface{}, interface{}, ...ggrpc.CallOption) error {
+	endpoint := m.f.ActiveRPCEndpoint()
+	m.used = append(m.used, endpoint)
+	if m.down[endpoint] {
+		return &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	}
+	return nil
+}
+
+func (m *mockClientConn) NewStream(context.Context, *ggrpc.StreamDesc, string, ...ggrpc.CallOption) (ggrpc.ClientStream, error) {
+	return nil, nil
+}
+
+func Test_GRPCClientWrapper_Failover(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("must switch to the next endpoint if paloma is down", func(t *testing.T) {
+		f := &mockRPCFailover{endpoints: []string{"a", "b", "c"}}
+		conn := &mockClientConn{f: f, down: map[string]bool{"a": true}}
+		testee := paloma.GRPCClientWrapper{W: conn, F: f}
+
+		err := testee.Invoke(ctx, "foo", nil, nil)
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, conn.used)
+		require.Equal(t, "b", f.ActiveRPCEndpoint())
+	})
+
+	t.Run("must return paloma is down only if all endpoints are down", func(t *testing.T) {
+		f := &mockRPCFailover{endpoints: []string{"a", "b", "c"}}
+		conn := &mockClientConn{f: f, down: map[string]bool{"a": true, "b": true, "c": true}}
+		testee := paloma.GRPCClientWrapper{W: conn, F: f}
+
+		err := testee.Invoke(ctx, "foo", nil, nil)
+		require.ErrorIs(t, err, paloma.ErrPalomaIsDown)
+		require.Equal(t, []string{"a", "b", "c"}, conn.used)
+	})
+
+	t.Run("must not retry broadcasts", func(t *testing.T) {
+		f := &mockRPCFailover{endpoints: []string{"a", "b"}}
+		conn := &mockClientConn{f: f, down: map[string]bool{"a": true}}
+		testee := paloma.GRPCClientWrapper{W: conn, F: f}
+
+		err := testee.Invoke(ctx, "/cosmos.tx.v1beta1.Service/BroadcastTx", nil, nil)
+		require.True(t, paloma.IsPalomaDown(err))
+		require.Equal(t, []string{"a"}, conn.used)
+	})
+
+	t.Run("must not retry without failover", func(t *testing.T) {
+		f := &mockRPCFailover{endpoints: []string{"a"}}
+		conn := &mockClientConn{f: f, down: map[string]bool{"a": true}}
+		testee := paloma.GRPCClientWrapper{W: conn}
+
+		err := testee.Invoke(ctx, "foo", nil, nil)
+		require.ErrorIs(t, err, paloma.ErrPalomaIsDown)
+		require.Equal(t, []string{"a"}, conn.used)
+	})
+}
+
+type failoverMsgSender struct {
+	f      *mockRPCFailover
+	errors map[string]error
+	used   []string
+}
+
+func (m *failoverMsgSender) SendMsg(context.Context, sdk.Msg, string, ...ion.SendMsgOption) (*sdk.TxResponse, error) {
+	endpoint := m.f.ActiveRPCEndpoint()
+	m.used = append(m.used, endpoint)
+	return &sdk.TxResponse{}, m.errors[endpoint]
+}
+
+type mockTxLookup struct {
+	known   bool
+	err     error
+	queried [][]byte
+}
+
+func (m *mockTxLookup) IsTxKnown(_ context.Context, hash []byte) (bool, error) {
+	m.queried = append(m.queried, hash)
+	return m.known, m.err
+}
+
+func Test_PalomaMessageSender_Failover(t *testing.T) {
+	ctx := context.Background()
+	netErr := &net.OpError{Op: "dial", Err: errors.New("connection refused")}
+	broadcastErr := &ion.BroadcastError{TxHash: []byte{0xab}, Err: netErr}
+
+	newTestee := func(sender *failoverMsgSender) *paloma.PalomaMessageSender {
+		return paloma.NewPalomaMessageSender(&mockKeyRotator{}, sender).
+			WithCreatorProvider(func() string { return "creator" }).
+			WithSignerProvider(func() string { return keys[keyIdx] }).
+			WithRPCFailover(sender.f)
+	}
+
+	t.Run("must retry messages which failed before being broadcast", func(t *testing.T) {
+		f := &mockRPCFailover{endpoints: []string{"a", "b"}}
+		sender := &failoverMsgSender{f: f, errors: map[string]error{"a": netErr}}
+
+		_, err := newTestee(sender).SendMsg(ctx, &palomatypes.MsgAddStatusUpdate{}, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, sender.used)
+	})
+
+	t.Run("must retry broadcasts which the next endpoint doesn't know", func(t *testing.T) {
+		f := &mockRPCFailover{endpoints: []string{"a", "b"}}
+		sender := &failoverMsgSender{f: f, errors: map[string]error{"a": broadcastErr}}
+		lookup := &mockTxLookup{}
+
+		_, err := newTestee(sender).WithTxLookup(lookup).SendMsg(ctx, &palomatypes.MsgAddStatusUpdate{}, "")
+		require.NoError(t, err)
+		require.Equal(t, []string{"a", "b"}, sender.used)
+		require.Equal(t, [][]byte{{0xab}}, lookup.queried)
+	})
+
+	t.Run("must not retry broadcasts which reached the chain", func(t *testing.T) {
+		f := &mockRPCFailover{endpoints: []string{"a", "b"}}
+		sender := &failoverMsgSender{f: f, errors: map[string]error{"a": broadcastErr}}
+
+		_, err := newTestee(sender).WithTxLookup(&mockTxLookup{known: true}).SendMsg(ctx, &palomatypes.MsgAddStatusUpdate{}, "")
+		require.ErrorIs(t, err, paloma.ErrPalomaIsDown)
+		require.Equal(t, []string{"a"}, sender.used)
+	})
+
+	t.Run("must not retry broadcasts if the lookup fails", func(t *testing.T) {
+		f := &mockRPCFailover{endpoints: []string{"a", "b"}}
+		sender := &failoverMsgSender{f: f, errors: map[string]error{"a": broadcastErr}}
+
+		_, err := newTestee(sender).WithTxLookup(&mockTxLookup{err: netErr}).SendMsg(ctx, &palomatypes.MsgAddStatusUpdate{}, "")
+		require.ErrorIs(t, err, paloma.ErrPalomaIsDown)
+		require.Equal(t, []string{"a"}, sender.used)
+	})
+
+	t.Run("must not retry broadcasts without a lookup", func(t *testing.T) {
+		f := &mockRPCFailover{endpoints: []string{"a", "b"}}
+		sender := &failoverMsgSender{f: f, errors: map[string]error{"a": broadcastErr}}
+
+		_, err := newTestee(sender).SendMsg(ctx, &palomatypes.MsgAddStatusUpdate{}, "")
+		require.ErrorIs(t, err, paloma.ErrPalomaIsDown)
+		require.Equal(t, []string{"a"}, sender.used)
+	})
+}
@@ -0,0 +1,56 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/liblog"
+	log "github.com/sirupsen/logrus"
+)
+
+// Builder builds processors which are served by the plugin configured for
+// their chain type.
+type Builder struct {
+	host   Host
+	dryRun bool
+	// launcher launches the plugin binary. It's replaced in tests.
+	launcher func(cfg config.Plugin, host Host, logger *log.Entry) launcher
+}
+
+var _ chain.ProcessorBuilder = Builder{}
+
+func NewBuilder(host Host) Builder {
+	return Builder{host: host, launcher: processLauncher}
+}
+
+// WithDryRun makes the plugins of all processors built by the builder
+// skip sending transactions.
+func (b Builder) WithDryRun(dryRun bool) Builder {
+	b.dryRun = dryRun
+	return b
+}
+
+// Build launches a plugin process for the chain. The plugin gets the
+// chain's section of the chains config.
+func (b Builder) Build(info chain.ChainInfo, cfg *config.Config) (chain.Processor, error) {
+	pluginCfg, ok := cfg.Plugins[info.ChainType()]
+	if !ok {
+		return nil, ErrMissingPluginConfig.Format(info.ChainType())
+	}
+	chainCfg, ok := cfg.ChainConfig(info.ChainType(), info.ChainReferenceID())
+	if !ok {
+		return nil, ErrMissingChainConfig.Format(info.ChainReferenceID())
+	}
+
+	logger := liblog.WithContext(context.Background()).WithFields(log.Fields{
+		"component":          "plugin",
+		"chain-type":         info.ChainType(),
+		"chain-reference-id": info.ChainReferenceID(),
+	})
+	p := newProcessor(info, chainCfg, b.dryRun, pluginCfg.HealthCheckInterval, b.launcher(pluginCfg, b.host, logger), logger)
+	if err := p.start(context.Background()); err != nil {
+		return nil, err
+	}
+	return p, nil
+}
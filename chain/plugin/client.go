@@ -0,0 +1,169 @@
+package plugin
+
+import (
+	"context"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	skyway "github.com/palomachain/paloma/v2/x/skyway/types"
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/queue"
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v2"
+)
+
+// rpcClient is pigeon's side of the processor service of a single plugin
+// process.
+type rpcClient struct {
+	conn   *grpc.ClientConn
+	broker *goplugin.GRPCBroker
+	host   Host
+}
+
+func (c *rpcClient) call(ctx context.Context, name string, req, resp any) error {
+	return invoke(ctx, c.conn, processorServiceName, name, req, resp)
+}
+
+// configure serves the host to the plugin and has it build its processor.
+func (c *rpcClient) configure(ctx context.Context, info chain.ChainInfo, cfg config.ChainConfig, dryRun bool) (*configureResponse, error) {
+	raw, err := yaml.Marshal(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	brokerID := c.broker.NextId()
+	go c.broker.AcceptAndServe(brokerID, func(opts []grpc.ServerOption) *grpc.Server {
+		s := grpc.NewServer(opts...)
+		s.RegisterService(&hostServiceDesc, &hostServer{host: c.host, chainReferenceID: info.ChainReferenceID()})
+		return s
+	})
+
+	resp := &configureResponse{}
+	err = c.call(ctx, "Configure", &configureRequest{
+		Info:         toChainInfo(info),
+		Config:       raw,
+		HostBrokerID: brokerID,
+		DryRun:       dryRun,
+	}, resp)
+	if err != nil {
+		return nil, err
+	}
+	return resp, nil
+}
+
+func (c *rpcClient) HealthCheck(ctx context.Context) error {
+	return c.call(ctx, "HealthCheck", &empty{}, &empty{})
+}
+
+func (c *rpcClient) Probe(ctx context.Context) error {
+	return c.call(ctx, "Probe", &empty{}, &empty{})
+}
+
+func (c *rpcClient) Status(ctx context.Context) (chain.ProcessorStatus, error) {
+	var res chain.ProcessorStatus
+	err := c.call(ctx, "Status", &empty{}, &res)
+	return res, err
+}
+
+func (c *rpcClient) IsRightChain(ctx context.Context) error {
+	return c.call(ctx, "IsRightChain", &empty{}, &empty{})
+}
+
+func (c *rpcClient) SignMessages(ctx context.Context, messages ...chain.QueuedMessage) ([]chain.SignedQueuedMessage, error) {
+	req := &signMessagesRequest{Messages: make([]queuedMessage, 0, len(messages))}
+	for _, m := range messages {
+		msg, err := toQueuedMessage(m)
+		if err != nil {
+			return nil, err
+		}
+		req.Messages = append(req.Messages, msg)
+	}
+
+	resp := &signMessagesResponse{}
+	if err := c.call(ctx, "SignMessages", req, resp); err != nil {
+		return nil, err
+	}
+
+	res := make([]chain.SignedQueuedMessage, 0, len(resp.Signed))
+	for _, m := range resp.Signed {
+		msg, err := m.Message.decode()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, chain.SignedQueuedMessage{QueuedMessage: msg, Signature: m.Signature, SignedByAddress: m.SignedByAddress})
+	}
+	return res, nil
+}
+
+func (c *rpcClient) callWithMessages(ctx context.Context, name string, queueTypeName queue.TypeName, msgs []chain.MessageWithSignatures, resp any) error {
+	wire, err := toMessagesWithSignatures(msgs)
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, name, &messagesRequest{Queue: string(queueTypeName), Messages: wire}, resp)
+}
+
+func (c *rpcClient) ProcessMessages(ctx context.Context, queueTypeName queue.TypeName, msgs []chain.MessageWithSignatures) error {
+	return c.callWithMessages(ctx, "ProcessMessages", queueTypeName, msgs, &empty{})
+}
+
+func (c *rpcClient) ProvideEvidence(ctx context.Context, queueTypeName queue.TypeName, msgs []chain.MessageWithSignatures) error {
+	return c.callWithMessages(ctx, "ProvideEvidence", queueTypeName, msgs, &empty{})
+}
+
+func (c *rpcClient) EstimateMessages(ctx context.Context, queueTypeName queue.TypeName, msgs []chain.MessageWithSignatures) ([]chain.MessageWithEstimate, error) {
+	resp := &estimateMessagesResponse{}
+	if err := c.callWithMessages(ctx, "EstimateMessages", queueTypeName, msgs, resp); err != nil {
+		return nil, err
+	}
+
+	res := make([]chain.MessageWithEstimate, 0, len(resp.Estimates))
+	for _, e := range resp.Estimates {
+		msg, err := fromMessagesWithSignatures([]messageWithSignatures{e.Message})
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, chain.MessageWithEstimate{
+			MessageWithSignatures: msg[0],
+			Estimate:              e.Estimate,
+			EstimatedByAddress:    e.EstimatedByAddress,
+		})
+	}
+	return res, nil
+}
+
+func (c *rpcClient) SubmitEventClaims(ctx context.Context, events []chain.SkywayEventer, orchestrator string) error {
+	wire, err := toSkywayEvents(events)
+	if err != nil {
+		return err
+	}
+	return c.call(ctx, "SubmitEventClaims", &skywayEventsRequest{Events: wire, Orchestrator: orchestrator}, &empty{})
+}
+
+func (c *rpcClient) GetSkywayEvents(ctx context.Context, orchestrator string) ([]chain.SkywayEventer, error) {
+	resp := &getSkywayEventsResponse{}
+	if err := c.call(ctx, "GetSkywayEvents", &getSkywayEventsRequest{Orchestrator: orchestrator}, resp); err != nil {
+		return nil, err
+	}
+	return fromSkywayEvents(resp.Events)
+}
+
+func (c *rpcClient) SkywaySignBatches(ctx context.Context, batches ...skyway.OutgoingTxBatch) ([]chain.SignedSkywayOutgoingTxBatch, error) {
+	resp := &skywaySignBatchesResponse{}
+	if err := c.call(ctx, "SkywaySignBatches", &skywaySignBatchesRequest{Batches: batches}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Signed, nil
+}
+
+func (c *rpcClient) SkywayEstimateBatches(ctx context.Context, batches []chain.SkywayBatchWithSignatures) ([]chain.EstimatedSkywayBatch, error) {
+	resp := &skywayEstimateBatchesResponse{}
+	if err := c.call(ctx, "SkywayEstimateBatches", &skywayBatchesRequest{Batches: toSkywayBatches(batches)}, resp); err != nil {
+		return nil, err
+	}
+	return resp.Estimates, nil
+}
+
+func (c *rpcClient) SkywayRelayBatches(ctx context.Context, batches []chain.SkywayBatchWithSignatures) error {
+	return c.call(ctx, "SkywayRelayBatches", &skywayBatchesRequest{Batches: toSkywayBatches(batches)}, &empty{})
+}
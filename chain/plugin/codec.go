@@ -0,0 +1,21 @@
+package plugin
+
+import (
+	"encoding/json"
+
+	"google.golang.org/grpc/encoding"
+)
+
+// codecName is the gRPC content subtype of the plugin protocol. Messages
+// are encoded as JSON, so that plugins don't need generated protobuf code.
+const codecName = "pigeon-json"
+
+type jsonCodec struct{}
+
+func (jsonCodec) Marshal(v any) ([]byte, error)      { return json.Marshal(v) }
+func (jsonCodec) Unmarshal(data []byte, v any) error { return json.Unmarshal(data, v) }
+func (jsonCodec) Name() string                       { return codecName }
+
+func init() {
+	encoding.RegisterCodec(jsonCodec{})
+}
@@ -0,0 +1,16 @@
+package plugin
+
+import (
+	"github.com/VolumeFi/whoops"
+)
+
+const (
+	ErrUnsupportedMessage  = whoops.Errorf("plugin protocol does not support message %T")
+	ErrUnknownMessageType  = whoops.Errorf("unknown message type %s")
+	ErrInvalidEstimate     = whoops.Errorf("invalid estimate: %s")
+	ErrNotConfigured       = whoops.String("plugin was not configured yet")
+	ErrMissingPluginConfig = whoops.Errorf("missing plugin config for chain type %s")
+	ErrPluginNotRunning    = whoops.Errorf("plugin for chain %s is not running")
+	ErrPluginExited        = whoops.String("plugin process exited")
+	ErrMissingChainConfig  = whoops.Errorf("missing config for chain %s")
+)
@@ -0,0 +1,131 @@
+package plugin
+
+import (
+	"context"
+
+	"github.com/cosmos/gogoproto/proto"
+	skyway "github.com/palomachain/paloma/v2/x/skyway/types"
+	"github.com/palomachain/pigeon/internal/metrics"
+	"google.golang.org/grpc"
+)
+
+// Host is served by pigeon to its plugins, so that processors running
+// in a plugin are able to report back to Paloma.
+type Host interface {
+	SetPublicAccessData(ctx context.Context, queueTypeName string, messageID uint64, valsetID uint64, data []byte) error
+	SetErrorData(ctx context.Context, queueTypeName string, messageID uint64, data []byte) error
+	AddMessageEvidence(ctx context.Context, queueTypeName string, messageID uint64, proof proto.Message) error
+	SendBatchSendToEVMClaim(ctx context.Context, claim skyway.MsgBatchSendToRemoteClaim) error
+	SendSendToPalomaClaim(ctx context.Context, claim skyway.MsgSendToPalomaClaim) error
+	SendLightNodeSaleClaim(ctx context.Context, claim skyway.MsgLightNodeSaleClaim) error
+}
+
+// hostClient is the plugin's side of the host.
+type hostClient struct {
+	conn *grpc.ClientConn
+}
+
+var _ Host = &hostClient{}
+
+func (h *hostClient) call(ctx context.Context, name string, req any) error {
+	return invoke(ctx, h.conn, hostServiceName, name, req, &empty{})
+}
+
+func (h *hostClient) SetPublicAccessData(ctx context.Context, queueTypeName string, messageID uint64, valsetID uint64, data []byte) error {
+	return h.call(ctx, "SetPublicAccessData", &publicAccessDataRequest{
+		Queue:     queueTypeName,
+		MessageID: messageID,
+		ValsetID:  valsetID,
+		Data:      data,
+	})
+}
+
+func (h *hostClient) SetErrorData(ctx context.Context, queueTypeName string, messageID uint64, data []byte) error {
+	return h.call(ctx, "SetErrorData", &errorDataRequest{Queue: queueTypeName, MessageID: messageID, Data: data})
+}
+
+func (h *hostClient) AddMessageEvidence(ctx context.Context, queueTypeName string, messageID uint64, proof proto.Message) error {
+	packed, err := packMsg(proof)
+	if err != nil {
+		return err
+	}
+	return h.call(ctx, "AddMessageEvidence", &messageEvidenceRequest{Queue: queueTypeName, MessageID: messageID, Proof: packed})
+}
+
+func (h *hostClient) sendClaim(ctx context.Context, claim proto.Message) error {
+	packed, err := packMsg(claim)
+	if err != nil {
+		return err
+	}
+	return h.call(ctx, "SendClaim", &claimRequest{Claim: packed})
+}
+
+func (h *hostClient) SendBatchSendToEVMClaim(ctx context.Context, claim skyway.MsgBatchSendToRemoteClaim) error {
+	return h.sendClaim(ctx, &claim)
+}
+
+func (h *hostClient) SendSendToPalomaClaim(ctx context.Context, claim skyway.MsgSendToPalomaClaim) error {
+	return h.sendClaim(ctx, &claim)
+}
+
+func (h *hostClient) SendLightNodeSaleClaim(ctx context.Context, claim skyway.MsgLightNodeSaleClaim) error {
+	return h.sendClaim(ctx, &claim)
+}
+
+// hostServer is pigeon's side of the host.
+type hostServer struct {
+	host             Host
+	chainReferenceID string
+}
+
+// setPublicAccessData is called once per relayed message, so that is where
+// relayed messages of plugins are counted.
+func (s *hostServer) setPublicAccessData(ctx context.Context, req *publicAccessDataRequest) (*empty, error) {
+	if err := s.host.SetPublicAccessData(ctx, req.Queue, req.MessageID, req.ValsetID, req.Data); err != nil {
+		return nil, err
+	}
+	metrics.AddMessages(s.chainReferenceID, req.Queue, metrics.ActionRelayed, 1)
+	return &empty{}, nil
+}
+
+func (s *hostServer) setErrorData(ctx context.Context, req *errorDataRequest) (*empty, error) {
+	return &empty{}, s.host.SetErrorData(ctx, req.Queue, req.MessageID, req.Data)
+}
+
+func (s *hostServer) addMessageEvidence(ctx context.Context, req *messageEvidenceRequest) (*empty, error) {
+	proof, err := unpackMsg(req.Proof)
+	if err != nil {
+		return nil, err
+	}
+	return &empty{}, s.host.AddMessageEvidence(ctx, req.Queue, req.MessageID, proof)
+}
+
+func (s *hostServer) sendClaim(ctx context.Context, req *claimRequest) (*empty, error) {
+	claim, err := unpackMsg(req.Claim)
+	if err != nil {
+		return nil, err
+	}
+
+	switch claim := claim.(type) {
+	case *skyway.MsgBatchSendToRemoteClaim:
+		err = s.host.SendBatchSendToEVMClaim(ctx, *claim)
+	case *skyway.MsgSendToPalomaClaim:
+		err = s.host.SendSendToPalomaClaim(ctx, *claim)
+	case *skyway.MsgLightNodeSaleClaim:
+		err = s.host.SendLightNodeSaleClaim(ctx, *claim)
+	default:
+		err = ErrUnsupportedMessage.Format(claim)
+	}
+	return &empty{}, err
+}
+
+var hostServiceDesc = grpc.ServiceDesc{
+	ServiceName: hostServiceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "SetPublicAccessData", Handler: unary((*hostServer).setPublicAccessData)},
+		{MethodName: "SetErrorData", Handler: unary((*hostServer).setErrorData)},
+		{MethodName: "AddMessageEvidence", Handler: unary((*hostServer).addMessageEvidence)},
+		{MethodName: "SendClaim", Handler: unary((*hostServer).sendClaim)},
+	},
+}
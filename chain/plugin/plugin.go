@@ -0,0 +1,47 @@
+// Package plugin runs chain processors out of process. A plugin is a
+// separate binary which implements chain.Processor and calls Serve from
+// its main function. Pigeon launches one plugin process per chain, talks
+// to it over gRPC on a local unix socket, health checks it and restarts
+// it when it dies. Plugins report back to Paloma through the Host which
+// pigeon serves to them.
+package plugin
+
+import (
+	"context"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"google.golang.org/grpc"
+)
+
+// ProtocolVersion is bumped on every incompatible change to the plugin
+// protocol.
+const ProtocolVersion = 2
+
+const processorPluginName = "processor"
+
+// Handshake is shared by pigeon and its plugins. It prevents plugin
+// binaries from being run on their own, and makes sure that both sides
+// speak the same protocol version.
+var Handshake = goplugin.HandshakeConfig{
+	ProtocolVersion:  ProtocolVersion,
+	MagicCookieKey:   "PIGEON_PLUGIN",
+	MagicCookieValue: "chain-processor",
+}
+
+// processorPlugin is used on both sides of the connection. The plugin
+// sets factory, pigeon sets host.
+type processorPlugin struct {
+	goplugin.NetRPCUnsupportedPlugin
+
+	factory Factory
+	host    Host
+}
+
+func (p *processorPlugin) GRPCServer(broker *goplugin.GRPCBroker, s *grpc.Server) error {
+	s.RegisterService(&processorServiceDesc, &processorServer{factory: p.factory, broker: broker})
+	return nil
+}
+
+func (p *processorPlugin) GRPCClient(_ context.Context, broker *goplugin.GRPCBroker, conn *grpc.ClientConn) (any, error) {
+	return &rpcClient{conn: conn, broker: broker, host: p.host}, nil
+}
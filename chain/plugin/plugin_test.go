@@ -0,0 +1,245 @@
+package plugin
+
+import (
+	"context"
+	goerrors "errors"
+	"math/big"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/cosmos/gogoproto/proto"
+	goplugin "github.com/hashicorp/go-plugin"
+	evmtypes "github.com/palomachain/paloma/v2/x/evm/types"
+	skyway "github.com/palomachain/paloma/v2/x/skyway/types"
+	"github.com/palomachain/pigeon/chain"
+	chainmocks "github.com/palomachain/pigeon/chain/mocks"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/errors"
+	"github.com/palomachain/pigeon/internal/queue"
+	log "github.com/sirupsen/logrus"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeHost struct {
+	mu        sync.Mutex
+	errorData map[uint64][]byte
+	claims    []skyway.MsgSendToPalomaClaim
+}
+
+func (h *fakeHost) SetPublicAccessData(context.Context, string, uint64, uint64, []byte) error {
+	return nil
+}
+
+func (h *fakeHost) SetErrorData(_ context.Context, _ string, messageID uint64, data []byte) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	if h.errorData == nil {
+		h.errorData = make(map[uint64][]byte)
+	}
+	h.errorData[messageID] = data
+	return nil
+}
+
+func (h *fakeHost) AddMessageEvidence(context.Context, string, uint64, proto.Message) error {
+	return nil
+}
+
+func (h *fakeHost) SendBatchSendToEVMClaim(context.Context, skyway.MsgBatchSendToRemoteClaim) error {
+	return nil
+}
+
+func (h *fakeHost) SendSendToPalomaClaim(_ context.Context, claim skyway.MsgSendToPalomaClaim) error {
+	h.mu.Lock()
+	defer h.mu.Unlock()
+	h.claims = append(h.claims, claim)
+	return nil
+}
+
+func (h *fakeHost) SendLightNodeSaleClaim(context.Context, skyway.MsgLightNodeSaleClaim) error {
+	return nil
+}
+
+type testChainInfo struct{}
+
+func (testChainInfo) ChainReferenceID() string { return "sol-main" }
+func (testChainInfo) ChainID() string          { return "101" }
+func (testChainInfo) ChainType() string        { return "solana" }
+
+// testLauncher serves the processor built by factory in process.
+func testLauncher(t *testing.T, factory Factory, host Host, launches *atomic.Int32) launcher {
+	return func(context.Context) (*session, error) {
+		launches.Add(1)
+		client, _ := goplugin.TestPluginGRPCConn(t, map[string]goplugin.Plugin{
+			processorPluginName: &processorPlugin{factory: factory, host: host},
+		})
+		raw, err := client.Dispense(processorPluginName)
+		if err != nil {
+			return nil, err
+		}
+
+		var killed atomic.Bool
+		return &session{
+			rpc: raw.(*rpcClient),
+			alive: func() error {
+				if killed.Load() {
+					return ErrPluginExited
+				}
+				return client.Ping()
+			},
+			kill: func() {
+				killed.Store(true)
+				// Closing the client shuts the server down as well.
+				client.Close()
+			},
+		}, nil
+	}
+}
+
+func TestProcessor(t *testing.T) {
+	ctx := context.Background()
+	host := &fakeHost{}
+	remote := chainmocks.NewProcessor(t)
+	remote.On("SupportedQueues").Return([]string{"solana/sol-main/turnstone"})
+	remote.On("ExternalAccount").Return(chain.ExternalAccount{ChainType: "solana", ChainReferenceID: "sol-main", Address: "abc"})
+
+	var pluginHost Host
+	var gotCfg config.ChainConfig
+	var gotInfo chain.ChainInfo
+	var gotOpts Options
+	factory := func(_ context.Context, info chain.ChainInfo, cfg config.ChainConfig, h Host, opts Options) (chain.Processor, error) {
+		gotInfo, gotCfg, pluginHost, gotOpts = info, cfg, h, opts
+		return remote, nil
+	}
+
+	var launches atomic.Int32
+	p := newProcessor(testChainInfo{}, config.ChainConfig{"rpc-url": "http://sol"}, false, time.Hour, testLauncher(t, factory, host, &launches), log.NewEntry(log.StandardLogger()))
+	require.NoError(t, p.start(ctx))
+	defer p.Close()
+
+	t.Run("the plugin is configured for the chain", func(t *testing.T) {
+		assert.Equal(t, "sol-main", gotInfo.ChainReferenceID())
+		assert.Equal(t, "101", gotInfo.ChainID())
+		assert.Equal(t, "solana", gotInfo.ChainType())
+		assert.Equal(t, config.ChainConfig{"rpc-url": "http://sol"}, gotCfg)
+		assert.False(t, gotOpts.DryRun)
+		assert.Equal(t, []string{"solana/sol-main/turnstone"}, p.SupportedQueues())
+		assert.Equal(t, "abc", p.ExternalAccount().Address)
+		assert.Equal(t, "sol-main", p.GetChainReferenceID())
+	})
+
+	t.Run("messages keep their type", func(t *testing.T) {
+		msg := chain.QueuedMessage{
+			ID:          7,
+			BytesToSign: []byte("sign me"),
+			Msg:         &evmtypes.Message{ChainReferenceID: "sol-main", TurnstoneID: "abc"},
+			Estimate:    big.NewInt(42),
+		}
+		remote.On("SignMessages", mock.Anything, mock.MatchedBy(func(m chain.QueuedMessage) bool {
+			got, ok := m.Msg.(*evmtypes.Message)
+			return ok && got.TurnstoneID == "abc" && m.Estimate.Int64() == 42
+		})).Return([]chain.SignedQueuedMessage{{QueuedMessage: msg, Signature: []byte("sig")}}, nil).Once()
+
+		signed, err := p.SignMessages(ctx, msg)
+		require.NoError(t, err)
+		require.Len(t, signed, 1)
+		assert.Equal(t, []byte("sig"), signed[0].Signature)
+		assert.Equal(t, uint64(7), signed[0].ID)
+		assert.Equal(t, "abc", signed[0].Msg.(*evmtypes.Message).TurnstoneID)
+	})
+
+	t.Run("plugins report back through the host", func(t *testing.T) {
+		remote.On("ProcessMessages", mock.Anything, queue.TypeName("q"), mock.Anything).Run(func(args mock.Arguments) {
+			require.NoError(t, pluginHost.SetErrorData(ctx, "q", 7, []byte("boom")))
+		}).Return(errors.Unrecoverable(goerrors.New("bad message"))).Once()
+
+		err := p.ProcessMessages(ctx, "q", []chain.MessageWithSignatures{{QueuedMessage: chain.QueuedMessage{ID: 7}}})
+		assert.True(t, errors.IsUnrecoverable(err), "unrecoverable errors must stay unrecoverable")
+		assert.Equal(t, []byte("boom"), host.errorData[7])
+	})
+
+	t.Run("skyway events and claims round trip", func(t *testing.T) {
+		events := []chain.SkywayEventer{
+			chain.SendToPalomaEvent{EventNonce: 1, Amount: 5},
+			chain.BatchSendEvent{EventNonce: 2, BatchNonce: 3},
+		}
+		remote.On("GetSkywayEvents", mock.Anything, "orchestrator").Return(events, nil).Once()
+		remote.On("SubmitEventClaims", mock.Anything, events, "orchestrator").Run(func(mock.Arguments) {
+			require.NoError(t, pluginHost.SendSendToPalomaClaim(ctx, skyway.MsgSendToPalomaClaim{EventNonce: 1}))
+		}).Return(nil).Once()
+
+		got, err := p.GetSkywayEvents(ctx, "orchestrator")
+		require.NoError(t, err)
+		assert.Equal(t, events, got)
+
+		require.NoError(t, p.SubmitEventClaims(ctx, got, "orchestrator"))
+		require.Len(t, host.claims, 1)
+		assert.Equal(t, uint64(1), host.claims[0].EventNonce)
+	})
+
+	t.Run("health checks reach the plugin", func(t *testing.T) {
+		remote.On("HealthCheck", mock.Anything).Return(nil).Once()
+		assert.NoError(t, p.HealthCheck(ctx))
+	})
+
+	assert.Equal(t, int32(1), launches.Load())
+}
+
+func TestBuilderDryRun(t *testing.T) {
+	remote := chainmocks.NewProcessor(t)
+	remote.On("SupportedQueues").Return([]string(nil))
+	remote.On("ExternalAccount").Return(chain.ExternalAccount{})
+
+	var gotOpts Options
+	factory := func(_ context.Context, _ chain.ChainInfo, _ config.ChainConfig, _ Host, opts Options) (chain.Processor, error) {
+		gotOpts = opts
+		return remote, nil
+	}
+
+	var launches atomic.Int32
+	b := NewBuilder(&fakeHost{}).WithDryRun(true)
+	b.launcher = func(_ config.Plugin, host Host, _ *log.Entry) launcher {
+		return testLauncher(t, factory, host, &launches)
+	}
+
+	p, err := b.Build(testChainInfo{}, &config.Config{
+		Chains:  map[string]map[string]config.ChainConfig{"solana": {"sol-main": {}}},
+		Plugins: map[string]config.Plugin{"solana": {}},
+	})
+	require.NoError(t, err)
+	defer p.(chain.Closer).Close()
+
+	assert.True(t, gotOpts.DryRun, "plugins must be told to skip sending transactions")
+	assert.Equal(t, int32(1), launches.Load())
+}
+
+func TestProcessorRestartsDeadPlugins(t *testing.T) {
+	remote := chainmocks.NewProcessor(t)
+	remote.On("SupportedQueues").Return([]string(nil))
+	remote.On("ExternalAccount").Return(chain.ExternalAccount{})
+	factory := func(context.Context, chain.ChainInfo, config.ChainConfig, Host, Options) (chain.Processor, error) {
+		return remote, nil
+	}
+
+	var launches atomic.Int32
+	p := newProcessor(testChainInfo{}, nil, false, 10*time.Millisecond, testLauncher(t, factory, &fakeHost{}, &launches), log.NewEntry(log.StandardLogger()))
+	require.NoError(t, p.start(context.Background()))
+	defer p.Close()
+
+	s, err := p.session()
+	require.NoError(t, err)
+	s.kill()
+
+	require.Eventually(t, func() bool {
+		cur, err := p.session()
+		return err == nil && cur != s
+	}, 5*time.Second, 10*time.Millisecond)
+	assert.Equal(t, int32(2), launches.Load())
+
+	p.Close()
+	_, err = p.session()
+	assert.ErrorIs(t, err, ErrPluginNotRunning)
+}
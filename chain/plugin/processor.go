@@ -0,0 +1,350 @@
+package plugin
+
+import (
+	"context"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/hashicorp/go-hclog"
+	goplugin "github.com/hashicorp/go-plugin"
+	skyway "github.com/palomachain/paloma/v2/x/skyway/types"
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/queue"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	defaultHealthCheckInterval = 10 * time.Second
+	launchTimeout              = 30 * time.Second
+	minRestartBackoff          = time.Second
+	maxRestartBackoff          = time.Minute
+)
+
+// session is a single run of a plugin process.
+type session struct {
+	rpc *rpcClient
+	// alive returns an error once the plugin process died or stopped
+	// responding.
+	alive func() error
+	kill  func()
+}
+
+type launcher func(ctx context.Context) (*session, error)
+
+// Processor is a chain.Processor which is served by a plugin process.
+// It restarts the plugin whenever it fails a health check. While the
+// plugin is down, all calls fail.
+type Processor struct {
+	info     chain.ChainInfo
+	chainCfg config.ChainConfig
+	dryRun   bool
+	launch   launcher
+	interval time.Duration
+	logger   *log.Entry
+
+	mu       sync.RWMutex
+	current  *session
+	queues   []string
+	account  chain.ExternalAccount
+	restarts int
+
+	cancel context.CancelFunc
+	done   chan struct{}
+}
+
+var (
+	_ chain.Processor      = &Processor{}
+	_ chain.Closer         = &Processor{}
+	_ chain.Prober         = &Processor{}
+	_ chain.StatusReporter = &Processor{}
+)
+
+func newProcessor(info chain.ChainInfo, chainCfg config.ChainConfig, dryRun bool, interval time.Duration, launch launcher, logger *log.Entry) *Processor {
+	if interval <= 0 {
+		interval = defaultHealthCheckInterval
+	}
+	return &Processor{
+		info:     info,
+		chainCfg: chainCfg,
+		dryRun:   dryRun,
+		launch:   launch,
+		interval: interval,
+		logger:   logger,
+	}
+}
+
+// start launches the plugin and keeps it running until the processor is
+// closed.
+func (p *Processor) start(ctx context.Context) error {
+	if err := p.restart(ctx); err != nil {
+		return err
+	}
+
+	ctx, p.cancel = context.WithCancel(context.Background())
+	p.done = make(chan struct{})
+	go p.supervise(ctx)
+	return nil
+}
+
+func (p *Processor) restart(ctx context.Context) error {
+	ctx, cancel := context.WithTimeout(ctx, launchTimeout)
+	defer cancel()
+
+	s, err := p.launch(ctx)
+	if err != nil {
+		return err
+	}
+
+	resp, err := s.rpc.configure(ctx, p.info, p.chainCfg, p.dryRun)
+	if err != nil {
+		s.kill()
+		return err
+	}
+
+	p.mu.Lock()
+	defer p.mu.Unlock()
+	p.current = s
+	p.queues = resp.SupportedQueues
+	p.account = resp.ExternalAccount
+	return nil
+}
+
+func (p *Processor) supervise(ctx context.Context) {
+	defer close(p.done)
+
+	ticker := time.NewTicker(p.interval)
+	defer ticker.Stop()
+	backoff := minRestartBackoff
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+
+		s, err := p.session()
+		if err == nil {
+			if err = s.alive(); err == nil {
+				backoff = minRestartBackoff
+				continue
+			}
+			p.logger.WithError(err).Warn("Plugin failed health check, restarting.")
+			p.stopSession(s)
+		}
+
+		if err := p.restart(ctx); err != nil {
+			p.logger.WithError(err).WithField("backoff", backoff).Error("Failed to restart plugin.")
+			select {
+			case <-ctx.Done():
+				return
+			case <-time.After(backoff):
+			}
+			backoff = min(2*backoff, maxRestartBackoff)
+			continue
+		}
+
+		p.mu.Lock()
+		p.restarts++
+		restarts := p.restarts
+		p.mu.Unlock()
+		p.logger.WithField("restarts", restarts).Info("Plugin restarted.")
+	}
+}
+
+func (p *Processor) session() (*session, error) {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	if p.current == nil {
+		return nil, ErrPluginNotRunning.Format(p.info.ChainReferenceID())
+	}
+	return p.current, nil
+}
+
+func (p *Processor) stopSession(s *session) {
+	p.mu.Lock()
+	if p.current == s {
+		p.current = nil
+	}
+	p.mu.Unlock()
+	s.kill()
+}
+
+// Close stops the plugin for good.
+func (p *Processor) Close() {
+	if p.cancel != nil {
+		p.cancel()
+		<-p.done
+	}
+	if s, err := p.session(); err == nil {
+		p.stopSession(s)
+	}
+}
+
+func (p *Processor) GetChainReferenceID() string {
+	return p.info.ChainReferenceID()
+}
+
+func (p *Processor) SupportedQueues() []string {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.queues
+}
+
+func (p *Processor) ExternalAccount() chain.ExternalAccount {
+	p.mu.RLock()
+	defer p.mu.RUnlock()
+	return p.account
+}
+
+func (p *Processor) HealthCheck(ctx context.Context) error {
+	s, err := p.session()
+	if err != nil {
+		return err
+	}
+	if err := s.alive(); err != nil {
+		return err
+	}
+	return s.rpc.HealthCheck(ctx)
+}
+
+func (p *Processor) Probe(ctx context.Context) error {
+	s, err := p.session()
+	if err != nil {
+		return err
+	}
+	return s.rpc.Probe(ctx)
+}
+
+func (p *Processor) Status(ctx context.Context) chain.ProcessorStatus {
+	s, err := p.session()
+	if err != nil {
+		return chain.ProcessorStatus{Errors: []string{err.Error()}}
+	}
+	res, err := s.rpc.Status(ctx)
+	if err != nil {
+		res.Errors = append(res.Errors, err.Error())
+	}
+	return res
+}
+
+func (p *Processor) IsRightChain(ctx context.Context) error {
+	s, err := p.session()
+	if err != nil {
+		return err
+	}
+	return s.rpc.IsRightChain(ctx)
+}
+
+func (p *Processor) SignMessages(ctx context.Context, messages ...chain.QueuedMessage) ([]chain.SignedQueuedMessage, error) {
+	s, err := p.session()
+	if err != nil {
+		return nil, err
+	}
+	return s.rpc.SignMessages(ctx, messages...)
+}
+
+func (p *Processor) ProcessMessages(ctx context.Context, queueTypeName queue.TypeName, msgs []chain.MessageWithSignatures) error {
+	s, err := p.session()
+	if err != nil {
+		return err
+	}
+	return s.rpc.ProcessMessages(ctx, queueTypeName, msgs)
+}
+
+func (p *Processor) EstimateMessages(ctx context.Context, queueTypeName queue.TypeName, msgs []chain.MessageWithSignatures) ([]chain.MessageWithEstimate, error) {
+	s, err := p.session()
+	if err != nil {
+		return nil, err
+	}
+	return s.rpc.EstimateMessages(ctx, queueTypeName, msgs)
+}
+
+func (p *Processor) ProvideEvidence(ctx context.Context, queueTypeName queue.TypeName, msgs []chain.MessageWithSignatures) error {
+	s, err := p.session()
+	if err != nil {
+		return err
+	}
+	return s.rpc.ProvideEvidence(ctx, queueTypeName, msgs)
+}
+
+func (p *Processor) SubmitEventClaims(ctx context.Context, events []chain.SkywayEventer, orchestrator string) error {
+	s, err := p.session()
+	if err != nil {
+		return err
+	}
+	return s.rpc.SubmitEventClaims(ctx, events, orchestrator)
+}
+
+func (p *Processor) GetSkywayEvents(ctx context.Context, orchestrator string) ([]chain.SkywayEventer, error) {
+	s, err := p.session()
+	if err != nil {
+		return nil, err
+	}
+	return s.rpc.GetSkywayEvents(ctx, orchestrator)
+}
+
+func (p *Processor) SkywaySignBatches(ctx context.Context, batches ...skyway.OutgoingTxBatch) ([]chain.SignedSkywayOutgoingTxBatch, error) {
+	s, err := p.session()
+	if err != nil {
+		return nil, err
+	}
+	return s.rpc.SkywaySignBatches(ctx, batches...)
+}
+
+func (p *Processor) SkywayEstimateBatches(ctx context.Context, batches []chain.SkywayBatchWithSignatures) ([]chain.EstimatedSkywayBatch, error) {
+	s, err := p.session()
+	if err != nil {
+		return nil, err
+	}
+	return s.rpc.SkywayEstimateBatches(ctx, batches)
+}
+
+func (p *Processor) SkywayRelayBatches(ctx context.Context, batches []chain.SkywayBatchWithSignatures) error {
+	s, err := p.session()
+	if err != nil {
+		return err
+	}
+	return s.rpc.SkywayRelayBatches(ctx, batches)
+}
+
+// processLauncher launches the plugin binary as a child process.
+func processLauncher(cfg config.Plugin, host Host, logger *log.Entry) launcher {
+	return func(context.Context) (*session, error) {
+		client := goplugin.NewClient(&goplugin.ClientConfig{
+			HandshakeConfig:  Handshake,
+			Plugins:          goplugin.PluginSet{processorPluginName: &processorPlugin{host: host}},
+			Cmd:              exec.Command(cfg.Path.Path(), cfg.Args...),
+			AllowedProtocols: []goplugin.Protocol{goplugin.ProtocolGRPC},
+			Logger: hclog.New(&hclog.LoggerOptions{
+				Name:        "plugin",
+				Output:      logger.Writer(),
+				DisableTime: true,
+			}),
+		})
+
+		protocol, err := client.Client()
+		if err != nil {
+			client.Kill()
+			return nil, err
+		}
+		raw, err := protocol.Dispense(processorPluginName)
+		if err != nil {
+			client.Kill()
+			return nil, err
+		}
+
+		return &session{
+			rpc: raw.(*rpcClient),
+			alive: func() error {
+				if client.Exited() {
+					return ErrPluginExited
+				}
+				return protocol.Ping()
+			},
+			kill: client.Kill,
+		}, nil
+	}
+}
@@ -0,0 +1,189 @@
+package plugin
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+
+	skyway "github.com/palomachain/paloma/v2/x/skyway/types"
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/errors"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+const (
+	processorServiceName = "pigeon.plugin.v1.Processor"
+	hostServiceName      = "pigeon.plugin.v1.Host"
+)
+
+type empty struct{}
+
+type chainInfo struct {
+	ChainReferenceID string `json:"chain-reference-id"`
+	ChainID          string `json:"chain-id"`
+	ChainType        string `json:"chain-type"`
+}
+
+func toChainInfo(info chain.ChainInfo) chainInfo {
+	return chainInfo{
+		ChainReferenceID: info.ChainReferenceID(),
+		ChainID:          info.ChainID(),
+		ChainType:        info.ChainType(),
+	}
+}
+
+// pluginChainInfo implements chain.ChainInfo for plugins.
+type pluginChainInfo struct {
+	info chainInfo
+}
+
+func (c pluginChainInfo) ChainReferenceID() string { return c.info.ChainReferenceID }
+func (c pluginChainInfo) ChainID() string          { return c.info.ChainID }
+func (c pluginChainInfo) ChainType() string        { return c.info.ChainType }
+
+type configureRequest struct {
+	Info chainInfo `json:"info"`
+	// Config is the chain's section of the config, as YAML.
+	Config       []byte `json:"config"`
+	HostBrokerID uint32 `json:"host-broker-id"`
+	DryRun       bool   `json:"dry-run"`
+}
+
+type configureResponse struct {
+	SupportedQueues []string              `json:"supported-queues"`
+	ExternalAccount chain.ExternalAccount `json:"external-account"`
+}
+
+type messagesRequest struct {
+	Queue    string                  `json:"queue"`
+	Messages []messageWithSignatures `json:"messages"`
+}
+
+type signMessagesRequest struct {
+	Messages []queuedMessage `json:"messages"`
+}
+
+type signMessagesResponse struct {
+	Signed []signedQueuedMessage `json:"signed"`
+}
+
+type estimateMessagesResponse struct {
+	Estimates []messageWithEstimate `json:"estimates"`
+}
+
+type skywayEventsRequest struct {
+	Events       []skywayEvent `json:"events"`
+	Orchestrator string        `json:"orchestrator"`
+}
+
+type getSkywayEventsRequest struct {
+	Orchestrator string `json:"orchestrator"`
+}
+
+type getSkywayEventsResponse struct {
+	Events []skywayEvent `json:"events"`
+}
+
+type skywaySignBatchesRequest struct {
+	Batches []skyway.OutgoingTxBatch `json:"batches"`
+}
+
+type skywaySignBatchesResponse struct {
+	Signed []chain.SignedSkywayOutgoingTxBatch `json:"signed"`
+}
+
+type skywayBatchesRequest struct {
+	Batches []skywayBatchWithSignatures `json:"batches"`
+}
+
+type skywayEstimateBatchesResponse struct {
+	Estimates []chain.EstimatedSkywayBatch `json:"estimates"`
+}
+
+type publicAccessDataRequest struct {
+	Queue     string `json:"queue"`
+	MessageID uint64 `json:"message-id"`
+	ValsetID  uint64 `json:"valset-id"`
+	Data      []byte `json:"data"`
+}
+
+type errorDataRequest struct {
+	Queue     string `json:"queue"`
+	MessageID uint64 `json:"message-id"`
+	Data      []byte `json:"data"`
+}
+
+type messageEvidenceRequest struct {
+	Queue     string  `json:"queue"`
+	MessageID uint64  `json:"message-id"`
+	Proof     *anyMsg `json:"proof"`
+}
+
+type claimRequest struct {
+	Claim *anyMsg `json:"claim"`
+}
+
+// method returns the full gRPC method name of a method of a service.
+func method(service, name string) string {
+	return fmt.Sprintf("/%s/%s", service, name)
+}
+
+type methodHandler = func(srv any, ctx context.Context, dec func(any) error, interceptor grpc.UnaryServerInterceptor) (any, error)
+
+// unary adapts fn to a gRPC method handler of a service implemented by S.
+func unary[S, Req, Resp any](fn func(S, context.Context, *Req) (*Resp, error)) methodHandler {
+	return func(srv any, ctx context.Context, dec func(any) error, _ grpc.UnaryServerInterceptor) (any, error) {
+		req := new(Req)
+		if err := dec(req); err != nil {
+			return nil, err
+		}
+		resp, err := fn(srv.(S), ctx, req)
+		if err != nil {
+			return nil, toStatus(err)
+		}
+		return resp, nil
+	}
+}
+
+func invoke(ctx context.Context, conn grpc.ClientConnInterface, service, name string, req, resp any) error {
+	err := conn.Invoke(ctx, method(service, name), req, resp, grpc.CallContentSubtype(codecName))
+	return fromStatus(err)
+}
+
+// toStatus keeps the errors which change how the relayer reacts to them
+// distinguishable on the other side of the connection.
+func toStatus(err error) error {
+	code := codes.Unknown
+	switch {
+	case goerrors.Is(err, context.Canceled):
+		code = codes.Canceled
+	case goerrors.Is(err, context.DeadlineExceeded):
+		code = codes.DeadlineExceeded
+	case errors.IsUnrecoverable(err):
+		code = codes.Aborted
+	}
+	return status.Error(code, err.Error())
+}
+
+func fromStatus(err error) error {
+	if err == nil {
+		return nil
+	}
+	s, ok := status.FromError(err)
+	if !ok {
+		return err
+	}
+
+	msg := goerrors.New(s.Message())
+	switch s.Code() {
+	case codes.Canceled:
+		return fmt.Errorf("%w: %w", context.Canceled, msg)
+	case codes.DeadlineExceeded:
+		return fmt.Errorf("%w: %w", context.DeadlineExceeded, msg)
+	case codes.Aborted:
+		return errors.Unrecoverable(msg)
+	}
+	return msg
+}
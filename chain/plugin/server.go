@@ -0,0 +1,287 @@
+package plugin
+
+import (
+	"context"
+	"sync"
+
+	goplugin "github.com/hashicorp/go-plugin"
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/queue"
+	"google.golang.org/grpc"
+	"gopkg.in/yaml.v2"
+)
+
+// Options are the settings pigeon runs with, which a plugin's processor
+// must honor.
+type Options struct {
+	// DryRun is set when pigeon runs with --dry-run. The processor must
+	// then never send a transaction to its chain, but log it instead.
+	// Calls to the host are already skipped by pigeon.
+	DryRun bool
+}
+
+// Factory builds the processor of a plugin once pigeon configured it for
+// a chain. The host is used to report back to Paloma.
+type Factory func(ctx context.Context, info chain.ChainInfo, cfg config.ChainConfig, host Host, opts Options) (chain.Processor, error)
+
+// Serve serves the processor built by factory to pigeon. It's called from
+// the main function of a plugin binary and blocks until pigeon stops the
+// plugin.
+func Serve(factory Factory) {
+	goplugin.Serve(&goplugin.ServeConfig{
+		HandshakeConfig: Handshake,
+		Plugins:         goplugin.PluginSet{processorPluginName: &processorPlugin{factory: factory}},
+		GRPCServer:      goplugin.DefaultGRPCServer,
+	})
+}
+
+// processorServer serves the processor of a plugin.
+type processorServer struct {
+	factory Factory
+	broker  *goplugin.GRPCBroker
+
+	mu        sync.RWMutex
+	processor chain.Processor
+}
+
+func (s *processorServer) get() (chain.Processor, error) {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	if s.processor == nil {
+		return nil, ErrNotConfigured
+	}
+	return s.processor, nil
+}
+
+func (s *processorServer) configure(ctx context.Context, req *configureRequest) (*configureResponse, error) {
+	var cfg config.ChainConfig
+	if err := yaml.Unmarshal(req.Config, &cfg); err != nil {
+		return nil, err
+	}
+
+	conn, err := s.broker.Dial(req.HostBrokerID)
+	if err != nil {
+		return nil, err
+	}
+
+	p, err := s.factory(ctx, pluginChainInfo{info: req.Info}, cfg, &hostClient{conn: conn}, Options{DryRun: req.DryRun})
+	if err != nil {
+		conn.Close()
+		return nil, err
+	}
+
+	s.mu.Lock()
+	old := s.processor
+	s.processor = p
+	s.mu.Unlock()
+	if c, ok := old.(chain.Closer); ok {
+		c.Close()
+	}
+
+	return &configureResponse{
+		SupportedQueues: p.SupportedQueues(),
+		ExternalAccount: p.ExternalAccount(),
+	}, nil
+}
+
+func (s *processorServer) healthCheck(ctx context.Context, _ *empty) (*empty, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	return &empty{}, p.HealthCheck(ctx)
+}
+
+func (s *processorServer) probe(ctx context.Context, _ *empty) (*empty, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	if prober, ok := p.(chain.Prober); ok {
+		return &empty{}, prober.Probe(ctx)
+	}
+	return &empty{}, p.HealthCheck(ctx)
+}
+
+func (s *processorServer) status(ctx context.Context, _ *empty) (*chain.ProcessorStatus, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	var res chain.ProcessorStatus
+	if reporter, ok := p.(chain.StatusReporter); ok {
+		res = reporter.Status(ctx)
+	}
+	return &res, nil
+}
+
+func (s *processorServer) isRightChain(ctx context.Context, _ *empty) (*empty, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	return &empty{}, p.IsRightChain(ctx)
+}
+
+func (s *processorServer) signMessages(ctx context.Context, req *signMessagesRequest) (*signMessagesResponse, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	msgs := make([]chain.QueuedMessage, 0, len(req.Messages))
+	for _, m := range req.Messages {
+		msg, err := m.decode()
+		if err != nil {
+			return nil, err
+		}
+		msgs = append(msgs, msg)
+	}
+
+	signed, err := p.SignMessages(ctx, msgs...)
+	if err != nil {
+		return nil, err
+	}
+
+	res := &signMessagesResponse{Signed: make([]signedQueuedMessage, 0, len(signed))}
+	for _, m := range signed {
+		msg, err := toQueuedMessage(m.QueuedMessage)
+		if err != nil {
+			return nil, err
+		}
+		res.Signed = append(res.Signed, signedQueuedMessage{Message: msg, Signature: m.Signature, SignedByAddress: m.SignedByAddress})
+	}
+	return res, nil
+}
+
+// withMessages decodes the messages of the request and calls fn with them.
+func (s *processorServer) withMessages(req *messagesRequest, fn func(chain.Processor, []chain.MessageWithSignatures) error) error {
+	p, err := s.get()
+	if err != nil {
+		return err
+	}
+	msgs, err := fromMessagesWithSignatures(req.Messages)
+	if err != nil {
+		return err
+	}
+	return fn(p, msgs)
+}
+
+func (s *processorServer) processMessages(ctx context.Context, req *messagesRequest) (*empty, error) {
+	return &empty{}, s.withMessages(req, func(p chain.Processor, msgs []chain.MessageWithSignatures) error {
+		return p.ProcessMessages(ctx, queue.TypeName(req.Queue), msgs)
+	})
+}
+
+func (s *processorServer) provideEvidence(ctx context.Context, req *messagesRequest) (*empty, error) {
+	return &empty{}, s.withMessages(req, func(p chain.Processor, msgs []chain.MessageWithSignatures) error {
+		return p.ProvideEvidence(ctx, queue.TypeName(req.Queue), msgs)
+	})
+}
+
+func (s *processorServer) estimateMessages(ctx context.Context, req *messagesRequest) (*estimateMessagesResponse, error) {
+	res := &estimateMessagesResponse{}
+	err := s.withMessages(req, func(p chain.Processor, msgs []chain.MessageWithSignatures) error {
+		estimates, err := p.EstimateMessages(ctx, queue.TypeName(req.Queue), msgs)
+		if err != nil {
+			return err
+		}
+		for _, e := range estimates {
+			msg, err := toMessagesWithSignatures([]chain.MessageWithSignatures{e.MessageWithSignatures})
+			if err != nil {
+				return err
+			}
+			res.Estimates = append(res.Estimates, messageWithEstimate{
+				Message:            msg[0],
+				Estimate:           e.Estimate,
+				EstimatedByAddress: e.EstimatedByAddress,
+			})
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+	return res, nil
+}
+
+func (s *processorServer) submitEventClaims(ctx context.Context, req *skywayEventsRequest) (*empty, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	events, err := fromSkywayEvents(req.Events)
+	if err != nil {
+		return nil, err
+	}
+	return &empty{}, p.SubmitEventClaims(ctx, events, req.Orchestrator)
+}
+
+func (s *processorServer) getSkywayEvents(ctx context.Context, req *getSkywayEventsRequest) (*getSkywayEventsResponse, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	events, err := p.GetSkywayEvents(ctx, req.Orchestrator)
+	if err != nil {
+		return nil, err
+	}
+	wire, err := toSkywayEvents(events)
+	if err != nil {
+		return nil, err
+	}
+	return &getSkywayEventsResponse{Events: wire}, nil
+}
+
+func (s *processorServer) skywaySignBatches(ctx context.Context, req *skywaySignBatchesRequest) (*skywaySignBatchesResponse, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	signed, err := p.SkywaySignBatches(ctx, req.Batches...)
+	if err != nil {
+		return nil, err
+	}
+	return &skywaySignBatchesResponse{Signed: signed}, nil
+}
+
+func (s *processorServer) skywayEstimateBatches(ctx context.Context, req *skywayBatchesRequest) (*skywayEstimateBatchesResponse, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	estimates, err := p.SkywayEstimateBatches(ctx, fromSkywayBatches(req.Batches))
+	if err != nil {
+		return nil, err
+	}
+	return &skywayEstimateBatchesResponse{Estimates: estimates}, nil
+}
+
+func (s *processorServer) skywayRelayBatches(ctx context.Context, req *skywayBatchesRequest) (*empty, error) {
+	p, err := s.get()
+	if err != nil {
+		return nil, err
+	}
+	return &empty{}, p.SkywayRelayBatches(ctx, fromSkywayBatches(req.Batches))
+}
+
+var processorServiceDesc = grpc.ServiceDesc{
+	ServiceName: processorServiceName,
+	HandlerType: (*any)(nil),
+	Methods: []grpc.MethodDesc{
+		{MethodName: "Configure", Handler: unary((*processorServer).configure)},
+		{MethodName: "HealthCheck", Handler: unary((*processorServer).healthCheck)},
+		{MethodName: "Probe", Handler: unary((*processorServer).probe)},
+		{MethodName: "Status", Handler: unary((*processorServer).status)},
+		{MethodName: "IsRightChain", Handler: unary((*processorServer).isRightChain)},
+		{MethodName: "SignMessages", Handler: unary((*processorServer).signMessages)},
+		{MethodName: "ProcessMessages", Handler: unary((*processorServer).processMessages)},
+		{MethodName: "EstimateMessages", Handler: unary((*processorServer).estimateMessages)},
+		{MethodName: "ProvideEvidence", Handler: unary((*processorServer).provideEvidence)},
+		{MethodName: "SubmitEventClaims", Handler: unary((*processorServer).submitEventClaims)},
+		{MethodName: "GetSkywayEvents", Handler: unary((*processorServer).getSkywayEvents)},
+		{MethodName: "SkywaySignBatches", Handler: unary((*processorServer).skywaySignBatches)},
+		{MethodName: "SkywayEstimateBatches", Handler: unary((*processorServer).skywayEstimateBatches)},
+		{MethodName: "SkywayRelayBatches", Handler: unary((*processorServer).skywayRelayBatches)},
+	},
+}
@@ -0,0 +1,239 @@
+package plugin
+
+import (
+	"math/big"
+	"reflect"
+
+	"github.com/cosmos/gogoproto/proto"
+	skyway "github.com/palomachain/paloma/v2/x/skyway/types"
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/util/slice"
+)
+
+// anyMsg carries a protobuf message together with its type, like
+// google.protobuf.Any.
+type anyMsg struct {
+	TypeURL string `json:"type-url"`
+	Value   []byte `json:"value"`
+}
+
+func packMsg(msg any) (*anyMsg, error) {
+	if msg == nil {
+		return nil, nil
+	}
+	m, ok := msg.(proto.Message)
+	if !ok {
+		return nil, ErrUnsupportedMessage.Format(msg)
+	}
+	value, err := proto.Marshal(m)
+	if err != nil {
+		return nil, err
+	}
+	return &anyMsg{TypeURL: "/" + proto.MessageName(m), Value: value}, nil
+}
+
+func unpackMsg(a *anyMsg) (proto.Message, error) {
+	if a == nil {
+		return nil, nil
+	}
+	name := a.TypeURL
+	if len(name) > 0 && name[0] == '/' {
+		name = name[1:]
+	}
+	t := proto.MessageType(name)
+	if t == nil {
+		return nil, ErrUnknownMessageType.Format(a.TypeURL)
+	}
+	m := reflect.New(t.Elem()).Interface().(proto.Message)
+	if err := proto.Unmarshal(a.Value, m); err != nil {
+		return nil, err
+	}
+	return m, nil
+}
+
+// queuedMessage is chain.QueuedMessage with its message packed.
+type queuedMessage struct {
+	ID               uint64  `json:"id"`
+	Nonce            []byte  `json:"nonce"`
+	BytesToSign      []byte  `json:"bytes-to-sign"`
+	PublicAccessData []byte  `json:"public-access-data"`
+	ErrorData        []byte  `json:"error-data"`
+	Msg              *anyMsg `json:"msg"`
+	Estimate         string  `json:"estimate,omitempty"`
+}
+
+func toQueuedMessage(m chain.QueuedMessage) (queuedMessage, error) {
+	msg, err := packMsg(m.Msg)
+	if err != nil {
+		return queuedMessage{}, err
+	}
+	res := queuedMessage{
+		ID:               m.ID,
+		Nonce:            m.Nonce,
+		BytesToSign:      m.BytesToSign,
+		PublicAccessData: m.PublicAccessData,
+		ErrorData:        m.ErrorData,
+		Msg:              msg,
+	}
+	if m.Estimate != nil {
+		res.Estimate = m.Estimate.String()
+	}
+	return res, nil
+}
+
+func (m queuedMessage) decode() (chain.QueuedMessage, error) {
+	msg, err := unpackMsg(m.Msg)
+	if err != nil {
+		return chain.QueuedMessage{}, err
+	}
+	res := chain.QueuedMessage{
+		ID:               m.ID,
+		Nonce:            m.Nonce,
+		BytesToSign:      m.BytesToSign,
+		PublicAccessData: m.PublicAccessData,
+		ErrorData:        m.ErrorData,
+	}
+	// Keep a nil message an untyped nil.
+	if msg != nil {
+		res.Msg = msg
+	}
+	if len(m.Estimate) > 0 {
+		estimate, ok := new(big.Int).SetString(m.Estimate, 10)
+		if !ok {
+			return chain.QueuedMessage{}, ErrInvalidEstimate.Format(m.Estimate)
+		}
+		res.Estimate = estimate
+	}
+	return res, nil
+}
+
+// validatorSignature is chain.ValidatorSignature with a raw validator
+// address. Bech32 encoding it would depend on the address prefixes
+// configured in each process.
+type validatorSignature struct {
+	ValAddress      []byte `json:"val-address"`
+	Signature       []byte `json:"signature"`
+	SignedByAddress string `json:"signed-by-address"`
+	PublicKey       []byte `json:"public-key"`
+}
+
+func toValidatorSignatures(sigs []chain.ValidatorSignature) []validatorSignature {
+	return slice.Map(sigs, func(s chain.ValidatorSignature) validatorSignature {
+		return validatorSignature{
+			ValAddress:      s.ValAddress,
+			Signature:       s.Signature,
+			SignedByAddress: s.SignedByAddress,
+			PublicKey:       s.PublicKey,
+		}
+	})
+}
+
+func fromValidatorSignatures(sigs []validatorSignature) []chain.ValidatorSignature {
+	return slice.Map(sigs, func(s validatorSignature) chain.ValidatorSignature {
+		return chain.ValidatorSignature{
+			ValAddress:      s.ValAddress,
+			Signature:       s.Signature,
+			SignedByAddress: s.SignedByAddress,
+			PublicKey:       s.PublicKey,
+		}
+	})
+}
+
+type messageWithSignatures struct {
+	Message    queuedMessage        `json:"message"`
+	Signatures []validatorSignature `json:"signatures"`
+}
+
+func toMessagesWithSignatures(msgs []chain.MessageWithSignatures) ([]messageWithSignatures, error) {
+	res := make([]messageWithSignatures, 0, len(msgs))
+	for _, m := range msgs {
+		msg, err := toQueuedMessage(m.QueuedMessage)
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, messageWithSignatures{Message: msg, Signatures: toValidatorSignatures(m.Signatures)})
+	}
+	return res, nil
+}
+
+func fromMessagesWithSignatures(msgs []messageWithSignatures) ([]chain.MessageWithSignatures, error) {
+	res := make([]chain.MessageWithSignatures, 0, len(msgs))
+	for _, m := range msgs {
+		msg, err := m.Message.decode()
+		if err != nil {
+			return nil, err
+		}
+		res = append(res, chain.MessageWithSignatures{QueuedMessage: msg, Signatures: fromValidatorSignatures(m.Signatures)})
+	}
+	return res, nil
+}
+
+type signedQueuedMessage struct {
+	Message         queuedMessage `json:"message"`
+	Signature       []byte        `json:"signature"`
+	SignedByAddress string        `json:"signed-by-address"`
+}
+
+type messageWithEstimate struct {
+	Message            messageWithSignatures `json:"message"`
+	Estimate           uint64                `json:"estimate"`
+	EstimatedByAddress string                `json:"estimated-by-address"`
+}
+
+type skywayBatchWithSignatures struct {
+	Batch      skyway.OutgoingTxBatch `json:"batch"`
+	Signatures []validatorSignature   `json:"signatures"`
+}
+
+func toSkywayBatches(batches []chain.SkywayBatchWithSignatures) []skywayBatchWithSignatures {
+	return slice.Map(batches, func(b chain.SkywayBatchWithSignatures) skywayBatchWithSignatures {
+		return skywayBatchWithSignatures{Batch: b.OutgoingTxBatch, Signatures: toValidatorSignatures(b.Signatures)}
+	})
+}
+
+func fromSkywayBatches(batches []skywayBatchWithSignatures) []chain.SkywayBatchWithSignatures {
+	return slice.Map(batches, func(b skywayBatchWithSignatures) chain.SkywayBatchWithSignatures {
+		return chain.SkywayBatchWithSignatures{OutgoingTxBatch: b.Batch, Signatures: fromValidatorSignatures(b.Signatures)}
+	})
+}
+
+// skywayEvent holds exactly one of the skyway event types.
+type skywayEvent struct {
+	BatchSend     *chain.BatchSendEvent     `json:"batch-send,omitempty"`
+	SendToPaloma  *chain.SendToPalomaEvent  `json:"send-to-paloma,omitempty"`
+	LightNodeSale *chain.LightNodeSaleEvent `json:"light-node-sale,omitempty"`
+}
+
+func toSkywayEvents(events []chain.SkywayEventer) ([]skywayEvent, error) {
+	res := make([]skywayEvent, 0, len(events))
+	for _, e := range events {
+		switch e := e.(type) {
+		case chain.BatchSendEvent:
+			res = append(res, skywayEvent{BatchSend: &e})
+		case chain.SendToPalomaEvent:
+			res = append(res, skywayEvent{SendToPaloma: &e})
+		case chain.LightNodeSaleEvent:
+			res = append(res, skywayEvent{LightNodeSale: &e})
+		default:
+			return nil, ErrUnsupportedMessage.Format(e)
+		}
+	}
+	return res, nil
+}
+
+func fromSkywayEvents(events []skywayEvent) ([]chain.SkywayEventer, error) {
+	res := make([]chain.SkywayEventer, 0, len(events))
+	for _, e := range events {
+		switch {
+		case e.BatchSend != nil:
+			res = append(res, *e.BatchSend)
+		case e.SendToPaloma != nil:
+			res = append(res, *e.SendToPaloma)
+		case e.LightNodeSale != nil:
+			res = append(res, *e.LightNodeSale)
+		default:
+			return nil, ErrUnsupportedMessage.Format(e)
+		}
+	}
+	return res, nil
+}
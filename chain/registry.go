@@ -0,0 +1,56 @@
+package chain
+
+import (
+	"sort"
+	"sync"
+
+	"github.com/palomachain/pigeon/config"
+	"golang.org/x/exp/maps"
+)
+
+// Registry dispatches building processors to the ProcessorBuilder
+// registered for the chain type of the chain info.
+type Registry struct {
+	mu       sync.RWMutex
+	builders map[string]ProcessorBuilder
+}
+
+func NewRegistry() *Registry {
+	return &Registry{builders: make(map[string]ProcessorBuilder)}
+}
+
+// Register registers the builder for the chain type. Every chain type can
+// only be registered once.
+func (r *Registry) Register(chainType string, b ProcessorBuilder) error {
+	r.mu.Lock()
+	defer r.mu.Unlock()
+
+	if _, ok := r.builders[chainType]; ok {
+		return ErrBuilderAlreadyRegistered.Format(chainType)
+	}
+	r.builders[chainType] = b
+	return nil
+}
+
+// Build builds a processor for the chain info using the builder of its
+// chain type.
+func (r *Registry) Build(info ChainInfo, cfg *config.Config) (Processor, error) {
+	r.mu.RLock()
+	b, ok := r.builders[info.ChainType()]
+	r.mu.RUnlock()
+	if !ok {
+		return nil, ErrUnsupportedChainType.Format(info.ChainType(), info.ChainReferenceID())
+	}
+
+	return b.Build(info, cfg)
+}
+
+// ChainTypes returns the registered chain types, sorted.
+func (r *Registry) ChainTypes() []string {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	types := maps.Keys(r.builders)
+	sort.Strings(types)
+	return types
+}
@@ -0,0 +1,55 @@
+package chain
+
+import (
+	"testing"
+
+	"github.com/palomachain/pigeon/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeChainInfo struct {
+	chainType string
+}
+
+func (fakeChainInfo) ChainReferenceID() string { return "chain-1" }
+func (fakeChainInfo) ChainID() string          { return "1" }
+func (c fakeChainInfo) ChainType() string      { return c.chainType }
+
+type fakeBuilder struct {
+	cfg  *config.Config
+	info ChainInfo
+}
+
+func (b *fakeBuilder) Build(info ChainInfo, cfg *config.Config) (Processor, error) {
+	b.info, b.cfg = info, cfg
+	return nil, nil
+}
+
+func TestRegistry(t *testing.T) {
+	cfg := &config.Config{}
+	r := NewRegistry()
+	solana, cosmos := &fakeBuilder{}, &fakeBuilder{}
+	require.NoError(t, r.Register("solana", solana))
+	require.NoError(t, r.Register("cosmos", cosmos))
+
+	t.Run("builds with the builder of the chain type", func(t *testing.T) {
+		info := fakeChainInfo{chainType: "solana"}
+		_, err := r.Build(info, cfg)
+		require.NoError(t, err)
+		assert.Equal(t, info, solana.info)
+		assert.Same(t, cfg, solana.cfg)
+		assert.Nil(t, cosmos.info)
+	})
+
+	t.Run("rejects unknown chain types", func(t *testing.T) {
+		_, err := r.Build(fakeChainInfo{chainType: "bitcoin"}, cfg)
+		assert.ErrorIs(t, err, ErrUnsupportedChainType)
+	})
+
+	t.Run("rejects registering a chain type twice", func(t *testing.T) {
+		assert.ErrorIs(t, r.Register("solana", &fakeBuilder{}), ErrBuilderAlreadyRegistered)
+	})
+
+	assert.Equal(t, []string{"cosmos", "solana"}, r.ChainTypes())
+}
@@ -6,6 +6,7 @@ import (
 
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	skyway "github.com/palomachain/paloma/v2/x/skyway/types"
+	"github.com/palomachain/pigeon/config"
 	"github.com/palomachain/pigeon/health"
 	"github.com/palomachain/pigeon/internal/queue"
 )
@@ -178,6 +179,48 @@ type Processor interface {
 	GetSkywayEvents(context.Context, string) ([]SkywayEventer, error)
 }
 
+// ProcessorBuilder builds processors for one chain type. The builder is
+// given the whole config so that it can read its own section of it, e.g.
+// with config.Config.ChainConfig and config.ChainConfig.Decode. It is
+// called for every chain of its type in the chains config.
 type ProcessorBuilder interface {
-	Build(ChainInfo) (Processor, error)
+	Build(ChainInfo, *config.Config) (Processor, error)
+}
+
+// ProcessorStatus is a snapshot of a processor's chain specific state,
+// used for diagnostics.
+type ProcessorStatus struct {
+	Balance                 string   `json:"balance,omitempty"`
+	MinOnChainBalance       string   `json:"min-on-chain-balance,omitempty"`
+	BalanceSufficient       bool     `json:"balance-sufficient"`
+	CompassValsetID         uint64   `json:"compass-valset-id"`
+	PalomaSnapshotID        uint64   `json:"paloma-snapshot-id"`
+	ValsetInSync            bool     `json:"valset-in-sync"`
+	LastObservedBlockHeight uint64   `json:"last-observed-block-height"`
+	Errors                  []string `json:"errors,omitempty"`
+}
+
+// StatusReporter is implemented by processors which are able to
+// report their current status.
+type StatusReporter interface {
+	Status(ctx context.Context) ProcessorStatus
+}
+
+// Closer is implemented by processors holding resources, such as RPC
+// connections, which must be released once the processor is replaced.
+type Closer interface {
+	Close()
+}
+
+// TxReplacer is implemented by processors which watch the transactions
+// they sent and can replace the ones stuck in the mempool.
+type TxReplacer interface {
+	ReplaceStuckTransactions(ctx context.Context) error
+}
+
+// Prober is implemented by processors which can cheaply check whether
+// their chain is reachable, e.g. to decide whether to resume work after
+// repeated failures.
+type Prober interface {
+	Probe(ctx context.Context) error
 }
@@ -1,10 +1,18 @@
 package main
 
 import (
+	"fmt"
+	"os"
+	"text/tabwriter"
+
+	"github.com/VolumeFi/whoops"
 	"github.com/palomachain/pigeon/app"
+	"github.com/palomachain/pigeon/internal/configcheck"
 	"github.com/spf13/cobra"
 )
 
+const errConfigValidationFailed = whoops.Errorf("config validation failed: %d of %d checks failed")
+
 var (
 	configCmd = &cobra.Command{
 		Use:   "config",
@@ -14,11 +22,32 @@ var (
 		Use:   "validate",
 		Short: "validates configuration",
 		RunE: func(cmd *cobra.Command, args []string) error {
-			_, err := app.PalomaClient().Keyring().List()
-			if err != nil {
+			palomaClient := app.PalomaClient()
+			results := configcheck.Validator{
+				Config:      app.Config(),
+				ChainInfos:  palomaClient.QueryGetEVMChainInfos,
+				DialChainID: configcheck.DialChainID,
+				Keyring:     palomaClient.Keyring(),
+			}.Run(cmd.Context())
+
+			failed := 0
+			w := tabwriter.NewWriter(os.Stdout, 0, 0, 2, ' ', 0)
+			fmt.Fprintln(w, "CHECK\tSUBJECT\tRESULT\tDETAILS")
+			for _, r := range results {
+				outcome, details := "pass", ""
+				if !r.Passed() {
+					failed++
+					outcome, details = "FAIL", r.Err.Error()
+				}
+				fmt.Fprintf(w, "%s\t%s\t%s\t%s\n", r.Check, r.Subject, outcome, details)
+			}
+			if err := w.Flush(); err != nil {
 				return err
 			}
-			// TODO: add more checks!
+
+			if failed > 0 {
+				return errConfigValidationFailed.Format(failed, len(results))
+			}
 
 			return nil
 		},
@@ -28,4 +57,5 @@ var (
 func init() {
 	rootCmd.AddCommand(configCmd)
 	configCmd.AddCommand(validateConfigCmd)
+	configRequired(validateConfigCmd)
 }
@@ -9,8 +9,10 @@ var healthCheckVerify = &cobra.Command{
 	Use:   "health-check",
 	Short: "Verifies the health of pigeon.",
 	RunE: func(cmd *cobra.Command, args []string) error {
-		app.HealthCheckService().BootChecker(cmd.Context())
-		app.HealthCheckService().Check(cmd.Context())
+		svc := app.HealthCheckService()
+		svc.ExitOnFailure = true
+		svc.BootChecker(cmd.Context())
+		svc.Check(cmd.Context())
 		return nil
 	},
 }
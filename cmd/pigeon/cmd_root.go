@@ -9,6 +9,7 @@ import (
 // flags
 var (
 	flagConfigPath     string
+	flagDryRun         bool
 	configRequiredCmds []*cobra.Command
 )
 
@@ -6,10 +6,13 @@ import (
 	"fmt"
 	"os"
 	"os/signal"
+	"syscall"
 	"time"
 
 	"github.com/palomachain/pigeon/app"
 	"github.com/palomachain/pigeon/health"
+	"github.com/palomachain/pigeon/internal/eventbus"
+	"github.com/palomachain/pigeon/internal/metrics"
 	"github.com/palomachain/pigeon/internal/mev"
 	log "github.com/sirupsen/logrus"
 	"github.com/spf13/cobra"
@@ -31,6 +34,20 @@ var startCmd = &cobra.Command{
 
 		ctx := catchKillSignal(cmd.Context(), 30*time.Second)
 
+		if flagDryRun {
+			log.Warn("running in dry run mode, nothing will be sent to Paloma or any target chain")
+		}
+		app.SetDryRun(flagDryRun)
+
+		if !app.DryRun() {
+			// A dry run keeps its state in memory, so it can't lock or
+			// overwrite the state file of a live pigeon.
+			app.OpenStore()
+		}
+		defer app.Store().Close()
+		relayer := app.Relayer()
+		readiness := app.Readiness()
+
 		// start healthcheck server
 		go func() {
 			health.StartHTTPServer(
@@ -40,6 +57,8 @@ var startCmd = &cobra.Command{
 				pid,
 				app.Version(),
 				app.Commit(),
+				relayer,
+				readiness,
 			)
 		}()
 
@@ -51,13 +70,24 @@ var startCmd = &cobra.Command{
 			log.WithError(err).Fatal("exiting as paloma was not detected to be running")
 			return err
 		}
+		readiness.Set(health.ConditionPaloma, nil)
 
 		// build a context that will get canceled if paloma ever goes offline
 		ctx = health.CancelContextIfPalomaIsDown(ctx, app.PalomaClient())
 
-		relayer := app.Relayer()
 		relayer.SetAppVersion(app.Version())
-		relayer.SetMevClient(mev.New(app.Config()))
+		if !app.DryRun() {
+			// The MEV client's heartbeats would announce this pigeon
+			relayer.SetMevClient(mev.New(app.Config()))
+		}
+		relayer.SetReadiness(readiness)
+
+		go app.HealthCheckService().HealthCheckInBackground(ctx)
+		go app.WatchConfig(ctx)
+		// Keeps counting while in-flight work is drained on shutdown.
+		go eventbus.Handle(context.Background(), app.EventBus().Subscribe(0), func(e eventbus.Event) {
+			metrics.AddEvent(string(e.Kind()))
+		})
 
 		err = relayer.Start(ctx)
 		if errors.Is(err, context.Canceled) || errors.Is(err, context.DeadlineExceeded) {
@@ -69,6 +99,7 @@ var startCmd = &cobra.Command{
 
 func init() {
 	rootCmd.AddCommand(startCmd)
+	startCmd.Flags().BoolVar(&flagDryRun, "dry-run", false, "log all writes to Paloma and the target chains instead of sending them")
 
 	configRequired(startCmd)
 }
@@ -76,7 +107,7 @@ func init() {
 func catchKillSignal(ctx context.Context, waitTimeout time.Duration) context.Context {
 	retCtx, closeCtx := context.WithCancel(ctx)
 	signalCh := make(chan os.Signal, 1)
-	signal.Notify(signalCh, os.Interrupt, os.Kill)
+	signal.Notify(signalCh, os.Interrupt, syscall.SIGTERM)
 	nextSignalShouldKillTheProcess := false
 	go func() {
 		for range signalCh {
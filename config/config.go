@@ -7,6 +7,7 @@ import (
 	"os/user"
 	"path"
 	"strings"
+	"time"
 
 	"github.com/VolumeFi/whoops"
 	"gopkg.in/yaml.v2"
@@ -16,6 +17,12 @@ const (
 	ChainName                          = "paloma"
 	Name                               = "pigeon"
 	cDefaultHealthServerAddressBinding = "127.0.0.1"
+	cDefaultStateFile                  = "~/.pigeon/state.db"
+
+	// HealthCheckFailurePolicyNotReady marks pigeon as not ready when
+	// health checks fail, while HealthCheckFailurePolicyExit terminates it.
+	HealthCheckFailurePolicyNotReady = "not-ready"
+	HealthCheckFailurePolicyExit     = "exit"
 )
 
 type CosmosSpecificClientConfig struct {
@@ -25,17 +32,114 @@ type CosmosSpecificClientConfig struct {
 }
 
 type EVMSpecificClientConfig struct {
-	TxType                      uint8 `yaml:"tx-type"`
-	BloxrouteIntegrationEnabled bool  `yaml:"bloxroute-mev-enabled"`
+	TxType                      uint8               `yaml:"tx-type"`
+	BloxrouteIntegrationEnabled bool                `yaml:"bloxroute-mev-enabled"`
+	RPCFailover                 RPCFailoverConfig   `yaml:"rpc-failover"`
+	TxReplacement               TxReplacementConfig `yaml:"tx-replacement"`
+	GasPrice                    GasPriceConfig      `yaml:"gas-price"`
+	GasSpend                    GasSpendConfig      `yaml:"gas-spend"`
+	PreflightSimulation         bool                `yaml:"preflight-simulation"`
+}
+
+// GasSpendConfig limits what pigeon pays for transactions. All values are
+// in wei, empty values mean no limit. The daily budget is a rolling 24 hour
+// window.
+type GasSpendConfig struct {
+	MaxGasPrice string `yaml:"max-gas-price"`
+	MaxTxCost   string `yaml:"max-tx-cost"`
+	DailyBudget string `yaml:"daily-budget"`
+}
+
+// GasPriceConfig selects how the fees of transactions are determined.
+// The multiplier strategy is used by default.
+type GasPriceConfig struct {
+	Strategy   string               `yaml:"strategy"`
+	FeeHistory FeeHistoryConfig     `yaml:"fee-history"`
+	Static     StaticGasPriceConfig `yaml:"static"`
+}
+
+// FeeHistoryConfig configures the fee-history strategy, which derives the
+// tip from a percentile of the tips paid in recent blocks. Zero values fall
+// back to sensible defaults.
+type FeeHistoryConfig struct {
+	Blocks            uint64  `yaml:"blocks"`
+	Percentile        float64 `yaml:"percentile"`
+	BaseFeeMultiplier float64 `yaml:"base-fee-multiplier"`
+}
+
+// StaticGasPriceConfig configures the static strategy. Both values are in
+// wei. GasPrice is the gas price of legacy transactions and the fee cap of
+// EIP-1559 transactions.
+type StaticGasPriceConfig struct {
+	GasPrice  string `yaml:"gas-price"`
+	GasTipCap string `yaml:"gas-tip-cap"`
+}
+
+// TxReplacementConfig controls when a transaction stuck in the mempool is
+// replaced by one with the same nonce and a higher fee. Replacements are
+// off unless enabled. Zero values fall back to sensible defaults.
+type TxReplacementConfig struct {
+	Enabled         bool   `yaml:"enabled"`
+	Timeout         string `yaml:"timeout"`
+	BumpPercent     uint64 `yaml:"bump-percent"`
+	MaxReplacements int    `yaml:"max-replacements"`
+}
+
+// RPCFailoverConfig controls when an RPC endpoint is considered unhealthy
+// and traffic is moved to the next configured endpoint. Zero values fall
+// back to sensible defaults.
+type RPCFailoverConfig struct {
+	MaxBlockLag   uint64  `yaml:"max-block-lag"`
+	MaxErrorRate  float64 `yaml:"max-error-rate"`
+	MaxLatency    string  `yaml:"max-latency"`
+	ProbeInterval string  `yaml:"probe-interval"`
 }
 
 type ChainClientConfig struct {
-	BaseRPCURL         string   `yaml:"base-rpc-url"`
-	KeyringPassEnvName string   `yaml:"keyring-pass-env-name"`
-	SigningKey         string   `yaml:"signing-key"`
-	KeyringDirectory   Filepath `yaml:"keyring-dir"`
-	CallTimeout        string   `yaml:"call-timeout"`
-	GasAdjustment      float64  `yaml:"gas-adjustment"`
+	BaseRPCURL         string       `yaml:"base-rpc-url"`
+	BaseRPCURLs        []string     `yaml:"base-rpc-urls"`
+	KeyringPassEnvName string       `yaml:"keyring-pass-env-name"`
+	KeyringPass        SecretConfig `yaml:"keyring-pass"`
+	SigningKey         string       `yaml:"signing-key"`
+	KeyringDirectory   Filepath     `yaml:"keyring-dir"`
+	CallTimeout        string       `yaml:"call-timeout"`
+	GasAdjustment      float64      `yaml:"gas-adjustment"`
+}
+
+// RPCURLs returns all configured RPC endpoints, starting with the primary
+// base-rpc-url, without duplicates.
+func (c ChainClientConfig) RPCURLs() []string {
+	urls := make([]string, 0, len(c.BaseRPCURLs)+1)
+	seen := make(map[string]struct{}, len(c.BaseRPCURLs)+1)
+	for _, u := range append([]string{c.BaseRPCURL}, c.BaseRPCURLs...) {
+		u = strings.TrimSpace(u)
+		if len(u) < 1 {
+			continue
+		}
+		if _, ok := seen[u]; ok {
+			continue
+		}
+		seen[u] = struct{}{}
+		urls = append(urls, u)
+	}
+	return urls
+}
+
+// KeyringPassword returns the keyring password from the configured secret
+// provider, falling back to the keyring-pass-env-name environment variable.
+func (c ChainClientConfig) KeyringPassword() (string, error) {
+	p, err := c.secretProvider()
+	if err != nil {
+		return "", err
+	}
+	return p.Secret()
+}
+
+func (c ChainClientConfig) secretProvider() (SecretProvider, error) {
+	if len(c.KeyringPass.Type) < 1 {
+		return EnvSecretProvider{Name: c.KeyringPassEnvName}, nil
+	}
+	return NewSecretProvider(c.KeyringPass)
 }
 
 type Filepath string
@@ -48,14 +152,48 @@ func (f Filepath) Path() string {
 }
 
 type Config struct {
-	HealthCheckPort    int    `yaml:"health-check-port"`
-	HealthCheckAddress string `yaml:"health-check-address"`
+	HealthCheckPort          int    `yaml:"health-check-port"`
+	HealthCheckAddress       string `yaml:"health-check-address"`
+	HealthCheckFailurePolicy string `yaml:"health-check-failure-policy"`
 
 	BloxrouteAuthorizationHeader string `yaml:"bloxroute-auth-header"`
 
+	// MaxConcurrentChains limits how many chains are processed at the
+	// same time by each relayer loop.
+	MaxConcurrentChains int `yaml:"max-concurrent-chains"`
+
+	// StateFile is where the relayer persists its progress between
+	// restarts.
+	StateFile Filepath `yaml:"state-file"`
+
+	CircuitBreaker CircuitBreakerConfig `yaml:"circuit-breaker"`
+
 	Paloma Paloma `yaml:"paloma"`
 
 	EVM map[string]EVM `yaml:"evm"`
+
+	// Chains holds the config of chains which are not EVM chains, by
+	// chain type and chain reference ID. Its layout is up to the processor
+	// builder of each chain type.
+	Chains map[string]map[string]ChainConfig `yaml:"chains"`
+
+	// Plugins holds the processor plugin to run for chains of a chain
+	// type, by chain type.
+	Plugins map[string]Plugin `yaml:"plugins"`
+
+	envOverrides []string
+}
+
+// EnvOverrides returns the names of all environment variables which
+// overrode a config field.
+func (c *Config) EnvOverrides() []string {
+	return c.envOverrides
+}
+
+// ChainConfig returns the config of the chain of the given type.
+func (c *Config) ChainConfig(chainType, chainReferenceID string) (ChainConfig, bool) {
+	cfg, ok := c.Chains[chainType][chainReferenceID]
+	return cfg, ok
 }
 
 func (c *Config) defaults() *Config {
@@ -63,6 +201,14 @@ func (c *Config) defaults() *Config {
 		c.HealthCheckAddress = cDefaultHealthServerAddressBinding
 	}
 
+	if len(c.HealthCheckFailurePolicy) < 1 {
+		c.HealthCheckFailurePolicy = HealthCheckFailurePolicyNotReady
+	}
+
+	if len(c.StateFile) < 1 {
+		c.StateFile = cDefaultStateFile
+	}
+
 	return c
 }
 
@@ -71,9 +217,71 @@ func (c *Config) validate() (*Config, error) {
 		return nil, fmt.Errorf("invalid health server port binding: %d", c.HealthCheckPort)
 	}
 
+	switch c.HealthCheckFailurePolicy {
+	case HealthCheckFailurePolicyNotReady, HealthCheckFailurePolicyExit:
+	default:
+		return nil, fmt.Errorf("invalid health check failure policy: %s", c.HealthCheckFailurePolicy)
+	}
+
+	if _, err := c.Paloma.secretProvider(); err != nil {
+		return nil, fmt.Errorf("invalid paloma keyring-pass: %w", err)
+	}
+	for k, v := range c.EVM {
+		if _, err := v.secretProvider(); err != nil {
+			return nil, fmt.Errorf("invalid keyring-pass for %s: %w", k, err)
+		}
+	}
+	if _, ok := c.Chains[evmChainType]; ok {
+		return nil, fmt.Errorf("evm chains are configured in the evm section, not in chains")
+	}
+	for k, v := range c.Plugins {
+		if k == evmChainType {
+			return nil, fmt.Errorf("evm chains can't be served by a plugin")
+		}
+		if len(v.Path) < 1 {
+			return nil, fmt.Errorf("missing path of plugin for chain type %s", k)
+		}
+	}
+
 	return c, nil
 }
 
+// evmChainType is the chain type of the chains in the evm section, which
+// are always processed by pigeon itself.
+const evmChainType = "evm"
+
+// ChainConfig is the undecoded config of a single chain.
+type ChainConfig map[string]any
+
+// Decode decodes the chain config into v, which is expected to be a
+// pointer to a struct with yaml tags.
+func (c ChainConfig) Decode(v any) error {
+	raw, err := yaml.Marshal(c)
+	if err != nil {
+		return err
+	}
+	return yaml.UnmarshalStrict(raw, v)
+}
+
+// CircuitBreakerConfig configures the circuit breakers of all chains and
+// queues. Zero values fall back to the defaults.
+type CircuitBreakerConfig struct {
+	// Threshold is the amount of consecutive failures after which a
+	// breaker opens.
+	Threshold int `yaml:"threshold"`
+	// Cooldown is how long an open breaker pauses work, e.g. "1m".
+	Cooldown string `yaml:"cooldown"`
+}
+
+// Plugin configures the binary which processes chains of a chain type.
+type Plugin struct {
+	Path Filepath `yaml:"path"`
+	Args []string `yaml:"args"`
+	// HealthCheckInterval is how often a running plugin is checked.
+	// Dead plugins are restarted.
+	HealthCheckInterval time.Duration `yaml:"health-check-interval"`
+}
+
 type EVM struct {
 	ChainClientConfig       `yaml:",inline"`
 	EVMSpecificClientConfig `yaml:",inline"`
@@ -87,14 +295,6 @@ type Paloma struct {
 	SigningKeys                []string `yaml:"signing-keys"`
 }
 
-func KeyringPassword(envKey string) string {
-	envVal, ok := os.LookupEnv(envKey)
-	if !ok {
-		panic(ErrUnableToLocateKeyringEnvironmentVar)
-	}
-	return envVal
-}
-
 func FromReader(r io.Reader) (*Config, error) {
 	rawBody, err := io.ReadAll(r)
 	if err != nil {
@@ -109,5 +309,10 @@ func FromReader(r io.Reader) (*Config, error) {
 		return nil, err
 	}
 
+	cfg.envOverrides, err = applyEnvOverrides(&cfg, os.Environ())
+	if err != nil {
+		return nil, err
+	}
+
 	return cfg.defaults().validate()
 }
@@ -0,0 +1,83 @@
+package config
+
+import (
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChainConfig(t *testing.T) {
+	cfg, err := FromReader(strings.NewReader(envTestConfig + `
+chains:
+  solana:
+    sol-main:
+      rpc-url: http://sol
+      commitment: finalized
+      retries: 3
+`))
+	require.NoError(t, err)
+
+	raw, ok := cfg.ChainConfig("solana", "sol-main")
+	require.True(t, ok)
+
+	var sol struct {
+		RPCURL     string `yaml:"rpc-url"`
+		Commitment string `yaml:"commitment"`
+		Retries    int    `yaml:"retries"`
+	}
+	require.NoError(t, raw.Decode(&sol))
+	assert.Equal(t, "http://sol", sol.RPCURL)
+	assert.Equal(t, "finalized", sol.Commitment)
+	assert.Equal(t, 3, sol.Retries)
+
+	var strict struct {
+		RPCURL string `yaml:"rpc-url"`
+	}
+	assert.Error(t, raw.Decode(&strict), "unknown keys must be rejected")
+
+	_, ok = cfg.ChainConfig("solana", "sol-test")
+	assert.False(t, ok)
+	_, ok = cfg.ChainConfig("cosmos", "sol-main")
+	assert.False(t, ok)
+
+	_, err = FromReader(strings.NewReader(envTestConfig + `
+chains:
+  evm:
+    eth-main:
+      rpc-url: http://eth
+`))
+	assert.ErrorContains(t, err, "evm chains are configured in the evm section")
+}
+
+func TestPluginConfig(t *testing.T) {
+	cfg, err := FromReader(strings.NewReader(envTestConfig + `
+plugins:
+  solana:
+    path: /usr/local/bin/pigeon-solana
+    args: [--verbose]
+    health-check-interval: 5s
+`))
+	require.NoError(t, err)
+	assert.Equal(t, Plugin{
+		Path:                "/usr/local/bin/pigeon-solana",
+		Args:                []string{"--verbose"},
+		HealthCheckInterval: 5 * time.Second,
+	}, cfg.Plugins["solana"])
+
+	_, err = FromReader(strings.NewReader(envTestConfig + `
+plugins:
+  solana:
+    args: [--verbose]
+`))
+	assert.ErrorContains(t, err, "missing path of plugin for chain type solana")
+
+	_, err = FromReader(strings.NewReader(envTestConfig + `
+plugins:
+  evm:
+    path: /usr/local/bin/pigeon-evm
+`))
+	assert.ErrorContains(t, err, "evm chains can't be served by a plugin")
+}
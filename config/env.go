@@ -0,0 +1,224 @@
+package config
+
+import (
+	"fmt"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+)
+
+// EnvOverridePrefix is the prefix of all environment variables overriding
+// config fields.
+//
+// The name of a variable is derived from the path of the field's yaml keys,
+// upper cased and with dashes replaced by underscores, e.g.
+// PIGEON_HEALTH_CHECK_PORT, PIGEON_PALOMA_GAS_PRICES or
+// PIGEON_EVM_ETH_MAIN_BASE_RPC_URL. List values are comma separated.
+const EnvOverridePrefix = "PIGEON"
+
+const evmEnvSegment = "EVM"
+
+// applyEnvOverrides overrides config fields with the values of matching
+// environment variables and returns the names of all applied variables.
+func applyEnvOverrides(c *Config, environ []string) ([]string, error) {
+	env := make(map[string]string, len(environ))
+	for _, kv := range environ {
+		k, v, ok := strings.Cut(kv, "=")
+		if ok && strings.HasPrefix(k, EnvOverridePrefix+"_") {
+			env[k] = v
+		}
+	}
+	if len(env) < 1 {
+		return nil, nil
+	}
+
+	var applied []string
+	lookup := func(name string) (string, bool) {
+		v, ok := env[name]
+		if ok {
+			applied = append(applied, name)
+		}
+		return v, ok
+	}
+
+	if err := overrideFields(reflect.ValueOf(c).Elem(), EnvOverridePrefix, lookup); err != nil {
+		return nil, err
+	}
+
+	evmPrefix := EnvOverridePrefix + "_" + evmEnvSegment + "_"
+	suffixes := fieldEnvNames(reflect.TypeOf(EVM{}), "")
+	chains := make(map[string]struct{})
+	for name := range env {
+		if rest, ok := strings.CutPrefix(name, evmPrefix); ok {
+			// Variables not matching any field are ignored, just like
+			// unrelated top level variables sharing the prefix.
+			if chain, ok := evmChainFromEnv(rest, suffixes); ok {
+				chains[chain] = struct{}{}
+			}
+		}
+	}
+
+	for chain := range chains {
+		key := chainKey(c.EVM, chain)
+		if c.EVM == nil {
+			c.EVM = make(map[string]EVM)
+		}
+		entry := c.EVM[key]
+		if err := overrideFields(reflect.ValueOf(&entry).Elem(), evmPrefix+chain, lookup); err != nil {
+			return nil, err
+		}
+		c.EVM[key] = entry
+	}
+
+	sort.Strings(applied)
+	return applied, nil
+}
+
+// overrideFields walks all yaml tagged fields of v and sets those with a
+// matching environment variable. Maps are skipped.
+func overrideFields(v reflect.Value, prefix string, lookup func(string) (string, bool)) error {
+	t := v.Type()
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, inline, ok := yamlField(f)
+		if !ok {
+			continue
+		}
+
+		fv := v.Field(i)
+		switch f.Type.Kind() {
+		case reflect.Map:
+			continue
+		case reflect.Struct:
+			p := prefix
+			if !inline {
+				p = prefix + "_" + envName(name)
+			}
+			if err := overrideFields(fv, p, lookup); err != nil {
+				return err
+			}
+			continue
+		}
+
+		envVar := prefix + "_" + envName(name)
+		raw, ok := lookup(envVar)
+		if !ok {
+			continue
+		}
+		if err := setFromEnv(fv, raw); err != nil {
+			return fmt.Errorf("invalid value for %s: %w", envVar, err)
+		}
+	}
+
+	return nil
+}
+
+// fieldEnvNames returns the environment variable suffixes of all leaf
+// fields of t.
+func fieldEnvNames(t reflect.Type, prefix string) []string {
+	var names []string
+	for i := 0; i < t.NumField(); i++ {
+		f := t.Field(i)
+		name, inline, ok := yamlField(f)
+		if !ok {
+			continue
+		}
+
+		p := prefix
+		if !inline {
+			p = strings.TrimPrefix(prefix+"_"+envName(name), "_")
+		}
+		switch f.Type.Kind() {
+		case reflect.Struct:
+			names = append(names, fieldEnvNames(f.Type, p)...)
+		case reflect.Map:
+		default:
+			names = append(names, p)
+		}
+	}
+	return names
+}
+
+// evmChainFromEnv splits the remainder of an EVM variable into the chain
+// and field part, preferring the longest matching field name.
+func evmChainFromEnv(rest string, suffixes []string) (string, bool) {
+	var chain string
+	var longest int
+	for _, s := range suffixes {
+		if len(s) > longest && strings.HasSuffix(rest, "_"+s) && len(rest) > len(s)+1 {
+			chain, longest = strings.TrimSuffix(rest, "_"+s), len(s)
+		}
+	}
+	return chain, longest > 0
+}
+
+// chainKey returns the existing key of m matching the chain segment of an
+// environment variable, or derives a new key from it.
+func chainKey(m map[string]EVM, chain string) string {
+	for k := range m {
+		if envName(k) == chain {
+			return k
+		}
+	}
+	return strings.ToLower(strings.ReplaceAll(chain, "_", "-"))
+}
+
+func yamlField(f reflect.StructField) (name string, inline bool, ok bool) {
+	tag, ok := f.Tag.Lookup("yaml")
+	if !ok || tag == "-" || !f.IsExported() {
+		return "", false, false
+	}
+	name, opts, _ := strings.Cut(tag, ",")
+	return name, strings.Contains(opts, "inline"), true
+}
+
+func envName(s string) string {
+	return strings.ToUpper(strings.NewReplacer("-", "_", ".", "_").Replace(s))
+}
+
+func setFromEnv(v reflect.Value, raw string) error {
+	switch v.Kind() {
+	case reflect.String:
+		v.SetString(raw)
+	case reflect.Bool:
+		b, err := strconv.ParseBool(raw)
+		if err != nil {
+			return err
+		}
+		v.SetBool(b)
+	case reflect.Int, reflect.Int8, reflect.Int16, reflect.Int32, reflect.Int64:
+		i, err := strconv.ParseInt(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetInt(i)
+	case reflect.Uint, reflect.Uint8, reflect.Uint16, reflect.Uint32, reflect.Uint64:
+		u, err := strconv.ParseUint(raw, 10, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetUint(u)
+	case reflect.Float32, reflect.Float64:
+		f, err := strconv.ParseFloat(raw, v.Type().Bits())
+		if err != nil {
+			return err
+		}
+		v.SetFloat(f)
+	case reflect.Slice:
+		if v.Type().Elem().Kind() != reflect.String {
+			return fmt.Errorf("unsupported type %s", v.Type())
+		}
+		var items []string
+		for _, s := range strings.Split(raw, ",") {
+			if s = strings.TrimSpace(s); len(s) > 0 {
+				items = append(items, s)
+			}
+		}
+		v.Set(reflect.ValueOf(items).Convert(v.Type()))
+	default:
+		return fmt.Errorf("unsupported type %s", v.Type())
+	}
+
+	return nil
+}
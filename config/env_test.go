@@ -0,0 +1,67 @@
+package config
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+const envTestConfig = `
+health-check-port: 5757
+paloma:
+  gas-prices: 0.01ugrain
+  signing-keys: [a]
+evm:
+  eth-main:
+    base-rpc-url: http://eth
+    tx-type: 2
+`
+
+func TestFromReaderAppliesEnvOverrides(t *testing.T) {
+	t.Setenv("PIGEON_HEALTH_CHECK_PORT", "6000")
+	t.Setenv("PIGEON_PALOMA_GAS_PRICES", "0.02ugrain")
+	t.Setenv("PIGEON_PALOMA_SIGNING_KEYS", "b, c")
+	t.Setenv("PIGEON_EVM_ETH_MAIN_BASE_RPC_URL", "http://eth-override")
+	t.Setenv("PIGEON_EVM_ETH_MAIN_BASE_RPC_URLS", "http://eth-2,http://eth-3")
+	t.Setenv("PIGEON_EVM_ETH_MAIN_RPC_FAILOVER_MAX_BLOCK_LAG", "7")
+	t.Setenv("PIGEON_EVM_ETH_MAIN_BLOXROUTE_MEV_ENABLED", "true")
+	t.Setenv("PIGEON_EVM_BNB_MAIN_TX_TYPE", "1")
+	t.Setenv("PIGEON_EVM_UNRELATED", "ignored")
+
+	cfg, err := FromReader(strings.NewReader(envTestConfig))
+	require.NoError(t, err)
+
+	assert.Equal(t, 6000, cfg.HealthCheckPort)
+	assert.Equal(t, "0.02ugrain", cfg.Paloma.GasPrices)
+	assert.Equal(t, []string{"b", "c"}, cfg.Paloma.SigningKeys)
+
+	eth := cfg.EVM["eth-main"]
+	assert.Equal(t, "http://eth-override", eth.BaseRPCURL)
+	assert.Equal(t, []string{"http://eth-2", "http://eth-3"}, eth.BaseRPCURLs)
+	assert.Equal(t, uint64(7), eth.RPCFailover.MaxBlockLag)
+	assert.True(t, eth.BloxrouteIntegrationEnabled)
+	assert.Equal(t, uint8(2), eth.TxType)
+
+	require.Contains(t, cfg.EVM, "bnb-main")
+	assert.Equal(t, uint8(1), cfg.EVM["bnb-main"].TxType)
+
+	assert.Equal(t, []string{
+		"PIGEON_EVM_BNB_MAIN_TX_TYPE",
+		"PIGEON_EVM_ETH_MAIN_BASE_RPC_URL",
+		"PIGEON_EVM_ETH_MAIN_BASE_RPC_URLS",
+		"PIGEON_EVM_ETH_MAIN_BLOXROUTE_MEV_ENABLED",
+		"PIGEON_EVM_ETH_MAIN_RPC_FAILOVER_MAX_BLOCK_LAG",
+		"PIGEON_HEALTH_CHECK_PORT",
+		"PIGEON_PALOMA_GAS_PRICES",
+		"PIGEON_PALOMA_SIGNING_KEYS",
+	}, cfg.EnvOverrides())
+}
+
+func TestFromReaderRejectsInvalidEnvOverrides(t *testing.T) {
+	t.Setenv("PIGEON_EVM_ETH_MAIN_TX_TYPE", "dynamic")
+
+	_, err := FromReader(strings.NewReader(envTestConfig))
+	assert.ErrorContains(t, err, "PIGEON_EVM_ETH_MAIN_TX_TYPE")
+}
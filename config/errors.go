@@ -4,4 +4,9 @@ import "github.com/VolumeFi/whoops"
 
 const (
 	ErrUnableToLocateKeyringEnvironmentVar = whoops.String("unable to locate keyring ENV variable")
+	ErrUnknownSecretProvider               = whoops.Errorf("unknown secret provider: '%s'")
+	ErrInvalidSecretName                   = whoops.Errorf("invalid secret name: '%s'")
+	ErrMissingSecretCommand                = whoops.String("secret command is not set")
+	ErrSecretCommandFailed                 = whoops.Errorf("secret command '%s' failed: %v")
+	ErrInsecureSecretFile                  = whoops.Errorf("secret file %s has insecure permissions %v")
 )
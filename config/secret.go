@@ -0,0 +1,141 @@
+package config
+
+import (
+	"bytes"
+	"context"
+	"os"
+	"os/exec"
+	"path/filepath"
+	"strings"
+	"time"
+)
+
+const (
+	SecretProviderEnv        = "env"
+	SecretProviderFile       = "file"
+	SecretProviderSecretsDir = "secrets-dir"
+	SecretProviderCommand    = "command"
+
+	defaultSecretsDir     = "/run/secrets"
+	defaultCommandTimeout = 30 * time.Second
+)
+
+// SecretConfig configures where a secret, e.g. a keyring password, is read
+// from. Depending on the type, Source is the name of an environment
+// variable, the path of a file or the name of a file in the secrets
+// directory.
+type SecretConfig struct {
+	Type    string   `yaml:"type"`
+	Source  string   `yaml:"source"`
+	Dir     Filepath `yaml:"dir"`
+	Command []string `yaml:"command"`
+}
+
+// SecretProvider returns a secret.
+type SecretProvider interface {
+	Secret() (string, error)
+}
+
+// NewSecretProvider returns the provider described by the config.
+func NewSecretProvider(c SecretConfig) (SecretProvider, error) {
+	switch c.Type {
+	case SecretProviderEnv:
+		return EnvSecretProvider{Name: c.Source}, nil
+	case SecretProviderFile:
+		return FileSecretProvider{Path: Filepath(c.Source).Path()}, nil
+	case SecretProviderSecretsDir:
+		dir := c.Dir.Path()
+		if len(c.Dir) < 1 {
+			dir = defaultSecretsDir
+		}
+		if len(c.Source) < 1 || strings.ContainsRune(c.Source, filepath.Separator) {
+			return nil, ErrInvalidSecretName.Format(c.Source)
+		}
+		return SecretsDirProvider{Dir: dir, Name: c.Source}, nil
+	case SecretProviderCommand:
+		if len(c.Command) < 1 {
+			return nil, ErrMissingSecretCommand
+		}
+		return CommandSecretProvider{Command: c.Command}, nil
+	default:
+		return nil, ErrUnknownSecretProvider.Format(c.Type)
+	}
+}
+
+// EnvSecretProvider reads the secret from an environment variable.
+type EnvSecretProvider struct {
+	Name string
+}
+
+func (p EnvSecretProvider) Secret() (string, error) {
+	v, ok := os.LookupEnv(p.Name)
+	if !ok {
+		return "", ErrUnableToLocateKeyringEnvironmentVar.WrapS("%s", p.Name)
+	}
+	return v, nil
+}
+
+// FileSecretProvider reads the secret from a file, which must not be
+// accessible by group or others.
+type FileSecretProvider struct {
+	Path string
+}
+
+func (p FileSecretProvider) Secret() (string, error) {
+	return readSecretFile(p.Path, 0o077)
+}
+
+// SecretsDirProvider reads the secret from a file in a secrets directory
+// such as /run/secrets. Secrets mounted by container runtimes are usually
+// world readable, so only world writable files are rejected.
+type SecretsDirProvider struct {
+	Dir  string
+	Name string
+}
+
+func (p SecretsDirProvider) Secret() (string, error) {
+	return readSecretFile(filepath.Join(p.Dir, p.Name), 0o002)
+}
+
+// CommandSecretProvider runs an external command and uses its stdout as
+// the secret.
+type CommandSecretProvider struct {
+	Command []string
+}
+
+func (p CommandSecretProvider) Secret() (string, error) {
+	ctx, cancel := context.WithTimeout(context.Background(), defaultCommandTimeout)
+	defer cancel()
+
+	var stdout bytes.Buffer
+	cmd := exec.CommandContext(ctx, p.Command[0], p.Command[1:]...)
+	cmd.Stdout = &stdout
+	cmd.Stderr = os.Stderr
+	if err := cmd.Run(); err != nil {
+		return "", ErrSecretCommandFailed.Format(p.Command[0], err)
+	}
+
+	return trimSecret(stdout.String()), nil
+}
+
+func readSecretFile(path string, forbiddenPerm os.FileMode) (string, error) {
+	info, err := os.Stat(path)
+	if err != nil {
+		return "", err
+	}
+	if perm := info.Mode().Perm(); perm&forbiddenPerm != 0 {
+		return "", ErrInsecureSecretFile.Format(path, perm)
+	}
+
+	b, err := os.ReadFile(path)
+	if err != nil {
+		return "", err
+	}
+
+	return trimSecret(string(b)), nil
+}
+
+// trimSecret removes the trailing newline most tools append.
+func trimSecret(s string) string {
+	return strings.TrimRight(s, "\r\n")
+}
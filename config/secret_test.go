@@ -0,0 +1,103 @@
+package config
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestKeyringPassword(t *testing.T) {
+	dir := t.TempDir()
+	writeSecret := func(name string, perm os.FileMode) string {
+		p := filepath.Join(dir, name)
+		require.NoError(t, os.WriteFile(p, []byte("s3cret\n"), perm))
+		require.NoError(t, os.Chmod(p, perm))
+		return p
+	}
+
+	t.Setenv("TEST_KEYRING_PASS", "s3cret")
+
+	testcases := []struct {
+		name   string
+		cfg    ChainClientConfig
+		expErr error
+	}{
+		{
+			name: "it falls back to the keyring-pass-env-name variable",
+			cfg:  ChainClientConfig{KeyringPassEnvName: "TEST_KEYRING_PASS"},
+		},
+		{
+			name:   "with a missing env variable it returns an error",
+			cfg:    ChainClientConfig{KeyringPassEnvName: "TEST_KEYRING_PASS_MISSING"},
+			expErr: ErrUnableToLocateKeyringEnvironmentVar,
+		},
+		{
+			name: "it reads the variable from the env provider",
+			cfg:  ChainClientConfig{KeyringPass: SecretConfig{Type: SecretProviderEnv, Source: "TEST_KEYRING_PASS"}},
+		},
+		{
+			name: "it reads a private file",
+			cfg:  ChainClientConfig{KeyringPass: SecretConfig{Type: SecretProviderFile, Source: writeSecret("private", 0o600)}},
+		},
+		{
+			name:   "it refuses to read a group readable file",
+			cfg:    ChainClientConfig{KeyringPass: SecretConfig{Type: SecretProviderFile, Source: writeSecret("shared", 0o640)}},
+			expErr: ErrInsecureSecretFile,
+		},
+		{
+			name: "it reads a world readable file from the secrets directory",
+			cfg: ChainClientConfig{KeyringPass: SecretConfig{
+				Type:   SecretProviderSecretsDir,
+				Source: filepath.Base(writeSecret("mounted", 0o444)),
+				Dir:    Filepath(dir),
+			}},
+		},
+		{
+			name: "it refuses to read a world writable file from the secrets directory",
+			cfg: ChainClientConfig{KeyringPass: SecretConfig{
+				Type:   SecretProviderSecretsDir,
+				Source: filepath.Base(writeSecret("writable", 0o666)),
+				Dir:    Filepath(dir),
+			}},
+			expErr: ErrInsecureSecretFile,
+		},
+		{
+			name: "it refuses secret names containing a path",
+			cfg: ChainClientConfig{KeyringPass: SecretConfig{
+				Type:   SecretProviderSecretsDir,
+				Source: "../private",
+				Dir:    Filepath(dir),
+			}},
+			expErr: ErrInvalidSecretName,
+		},
+		{
+			name: "it reads the stdout of a command",
+			cfg:  ChainClientConfig{KeyringPass: SecretConfig{Type: SecretProviderCommand, Command: []string{"echo", "s3cret"}}},
+		},
+		{
+			name:   "with a failing command it returns an error",
+			cfg:    ChainClientConfig{KeyringPass: SecretConfig{Type: SecretProviderCommand, Command: []string{"false"}}},
+			expErr: ErrSecretCommandFailed,
+		},
+		{
+			name:   "with an unknown provider it returns an error",
+			cfg:    ChainClientConfig{KeyringPass: SecretConfig{Type: "vault"}},
+			expErr: ErrUnknownSecretProvider,
+		},
+	}
+
+	for _, tt := range testcases {
+		t.Run(tt.name, func(t *testing.T) {
+			pass, err := tt.cfg.KeyringPassword()
+			if tt.expErr != nil {
+				assert.ErrorIs(t, err, tt.expErr)
+				return
+			}
+			require.NoError(t, err)
+			assert.Equal(t, "s3cret", pass)
+		})
+	}
+}
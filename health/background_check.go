@@ -23,6 +23,13 @@ func (e *Errors) Error() string {
 
 type Service struct {
 	Checks []Checker
+
+	// Readiness is marked as not ready while health checks are failing.
+	Readiness *Readiness
+
+	// ExitOnFailure makes failing health checks terminate the process
+	// instead of only marking it as not ready.
+	ExitOnFailure bool
 }
 
 func (s Service) HealthCheckInBackground(
@@ -53,8 +60,15 @@ func (s Service) Check(ctx context.Context) {
 		for _, err := range g {
 			log.WithError(err).Error("health check failed")
 		}
-		log.Fatal("exiting due to health check failures")
+		if s.ExitOnFailure {
+			log.Fatal("exiting due to health check failures")
+		}
+		log.Error("marking pigeon as not ready due to health check failures")
+		s.Readiness.Set(ConditionHealthChecks, &Errors{All: g})
+		return
 	}
+
+	s.Readiness.Set(ConditionHealthChecks, nil)
 }
 
 func (s Service) BootChecker(ctx context.Context) {
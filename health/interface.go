@@ -9,3 +9,8 @@ type Checker interface {
 type BootChecker interface {
 	BootHealthCheck(ctx context.Context) error
 }
+
+// StatusReporter reports a detailed, JSON serializable status.
+type StatusReporter interface {
+	Status(ctx context.Context) any
+}
@@ -0,0 +1,67 @@
+package health
+
+import (
+	"sync"
+
+	"github.com/VolumeFi/whoops"
+)
+
+const (
+	ConditionPaloma       = "paloma"
+	ConditionStaking      = "staking"
+	ConditionProcessors   = "processors"
+	ConditionRightChain   = "right-chain"
+	ConditionHealthChecks = "health-checks"
+	ConditionProcessLoops = "process-loops"
+)
+
+const ErrNotYetChecked = whoops.String("not yet checked")
+
+// Readiness keeps track of all conditions which need to be met for
+// pigeon to be considered ready to relay.
+type Readiness struct {
+	mu         sync.RWMutex
+	conditions map[string]error
+}
+
+// NewReadiness returns a Readiness which won't be ready until all of
+// the given conditions have been reported as met.
+func NewReadiness(conditions ...string) *Readiness {
+	r := &Readiness{conditions: make(map[string]error, len(conditions))}
+	for _, c := range conditions {
+		r.conditions[c] = ErrNotYetChecked
+	}
+	return r
+}
+
+// Set marks the condition as met if err is nil, and as failing otherwise.
+// It's safe to call on a nil Readiness.
+func (r *Readiness) Set(condition string, err error) {
+	if r == nil {
+		return
+	}
+
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	r.conditions[condition] = err
+}
+
+// Ready returns whether all conditions are met, together with the state
+// of each condition.
+func (r *Readiness) Ready() (bool, map[string]string) {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	ready := true
+	res := make(map[string]string, len(r.conditions))
+	for c, err := range r.conditions {
+		if err != nil {
+			ready = false
+			res[c] = err.Error()
+			continue
+		}
+		res[c] = "ok"
+	}
+
+	return ready, res
+}
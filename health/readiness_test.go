@@ -0,0 +1,63 @@
+package health
+
+import (
+	"context"
+
+	"github.com/VolumeFi/whoops"
+	. "github.com/onsi/ginkgo/v2"
+	. "github.com/onsi/gomega"
+)
+
+type fakeChecker struct {
+	err error
+}
+
+func (f fakeChecker) HealthCheck(context.Context) error { return f.err }
+
+var _ = Describe("readiness", func() {
+	var r *Readiness
+
+	BeforeEach(func() {
+		r = NewReadiness(ConditionPaloma, ConditionStaking)
+	})
+
+	It("is not ready until all conditions are met", func() {
+		ready, conditions := r.Ready()
+		Expect(ready).To(BeFalse())
+		Expect(conditions).To(HaveKeyWithValue(ConditionPaloma, ErrNotYetChecked.Error()))
+
+		r.Set(ConditionPaloma, nil)
+		ready, _ = r.Ready()
+		Expect(ready).To(BeFalse())
+
+		r.Set(ConditionStaking, nil)
+		ready, conditions = r.Ready()
+		Expect(ready).To(BeTrue())
+		Expect(conditions).To(HaveKeyWithValue(ConditionStaking, "ok"))
+	})
+
+	It("ignores updates when nil", func() {
+		var nilReadiness *Readiness
+		Expect(func() { nilReadiness.Set(ConditionPaloma, nil) }).NotTo(Panic())
+	})
+
+	When("health checks fail", func() {
+		It("marks pigeon as not ready instead of exiting", func() {
+			r := NewReadiness(ConditionHealthChecks)
+			s := Service{
+				Checks:    []Checker{fakeChecker{err: whoops.String("balance too low")}},
+				Readiness: r,
+			}
+
+			s.Check(context.Background())
+			ready, conditions := r.Ready()
+			Expect(ready).To(BeFalse())
+			Expect(conditions[ConditionHealthChecks]).To(ContainSubstring("balance too low"))
+
+			s.Checks = []Checker{fakeChecker{}}
+			s.Check(context.Background())
+			ready, _ = r.Ready()
+			Expect(ready).To(BeTrue())
+		})
+	})
+})
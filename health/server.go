@@ -9,15 +9,31 @@ import (
 	"os"
 	"time"
 
+	"github.com/palomachain/pigeon/internal/metrics"
 	log "github.com/sirupsen/logrus"
 )
 
+const statusTimeout = 30 * time.Second
+
 type jsonResponse struct {
 	Pid     int    `json:"pid"`
 	Version string `json:"version"`
 	Commit  string `json:"commit"`
 }
 
+type probeResponse struct {
+	Ready      bool              `json:"ready"`
+	Conditions map[string]string `json:"conditions,omitempty"`
+}
+
+func writeJSON(w http.ResponseWriter, code int, v any) {
+	w.Header().Set("Content-Type", "application/json")
+	w.WriteHeader(code)
+	if err := json.NewEncoder(w).Encode(v); err != nil {
+		log.WithError(err).Error("responding to health probe")
+	}
+}
+
 func StartHTTPServer(
 	ctx context.Context,
 	addr string,
@@ -25,6 +41,8 @@ func StartHTTPServer(
 	pid int,
 	appVersion string,
 	commit string,
+	status StatusReporter,
+	readiness *Readiness,
 ) {
 	m := http.NewServeMux()
 	m.HandleFunc("/", func(w http.ResponseWriter, r *http.Request) {
@@ -38,6 +56,29 @@ func StartHTTPServer(
 		}
 	})
 
+	m.Handle("/metrics", metrics.Handler())
+	m.HandleFunc("/livez", func(w http.ResponseWriter, r *http.Request) {
+		writeJSON(w, http.StatusOK, jsonResponse{
+			Pid:     os.Getpid(),
+			Version: appVersion,
+			Commit:  commit,
+		})
+	})
+	m.HandleFunc("/readyz", func(w http.ResponseWriter, r *http.Request) {
+		ready, conditions := readiness.Ready()
+		code := http.StatusOK
+		if !ready {
+			code = http.StatusServiceUnavailable
+		}
+		writeJSON(w, code, probeResponse{Ready: ready, Conditions: conditions})
+	})
+	m.HandleFunc("/status", func(w http.ResponseWriter, r *http.Request) {
+		ctx, cancel := context.WithTimeout(r.Context(), statusTimeout)
+		defer cancel()
+
+		writeJSON(w, http.StatusOK, status.Status(ctx))
+	})
+
 	server := &http.Server{
 		Addr:    fmt.Sprintf("%s:%d", addr, port),
 		Handler: m,
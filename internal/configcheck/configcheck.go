@@ -0,0 +1,229 @@
+// Package configcheck performs an extensive validation of a pigeon
+// configuration, including all external resources it references.
+package configcheck
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"time"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	sdk "github.com/cosmos/cosmos-sdk/types"
+	"github.com/ethereum/go-ethereum/accounts"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/ethclient"
+	evmtypes "github.com/palomachain/paloma/v2/x/evm/types"
+	"github.com/palomachain/pigeon/chain/evm"
+	"github.com/palomachain/pigeon/config"
+)
+
+const (
+	CheckEVMConfig   = "evm-config"
+	CheckKeystore    = "keystore"
+	CheckRPC         = "rpc"
+	CheckSyntax      = "syntax"
+	CheckSigningKey  = "signing-key"
+	CheckChainInfos  = "chain-infos"
+	CheckPalomaChain = "paloma-chain"
+
+	defaultDialTimeout = 10 * time.Second
+)
+
+// Result is the outcome of a single check.
+type Result struct {
+	Check   string
+	Subject string
+	Err     error
+}
+
+func (r Result) Passed() bool {
+	return r.Err == nil
+}
+
+// ChainInfoQuerier returns the chains known to Paloma.
+type ChainInfoQuerier func(ctx context.Context) ([]*evmtypes.ChainInfo, error)
+
+// ChainIDDialer connects to an RPC endpoint and returns the chain ID it
+// reports.
+type ChainIDDialer func(ctx context.Context, url string) (*big.Int, error)
+
+// KeyLookup returns a key from the Paloma keyring.
+type KeyLookup interface {
+	Key(uid string) (*keyring.Record, error)
+}
+
+// Validator runs all checks against a configuration.
+type Validator struct {
+	Config      *config.Config
+	ChainInfos  ChainInfoQuerier
+	DialChainID ChainIDDialer
+	Keyring     KeyLookup
+}
+
+// Run executes all checks and returns their results. It never aborts
+// early, so that all problems are reported at once.
+func (v Validator) Run(ctx context.Context) []Result {
+	var res []Result
+
+	chainInfos, err := v.ChainInfos(ctx)
+	res = append(res, Result{Check: CheckChainInfos, Subject: "paloma", Err: err})
+	expected := make(map[string]*evmtypes.ChainInfo, len(chainInfos))
+	for _, ci := range chainInfos {
+		expected[ci.GetChainReferenceID()] = ci
+	}
+
+	res = append(res, v.checkPalomaSyntax()...)
+	res = append(res, v.checkSigningKeys()...)
+
+	for _, id := range sortedKeys(v.Config.EVM) {
+		cfg := v.Config.EVM[id]
+		res = append(res, checkEVMConfig(id, cfg))
+		res = append(res, checkKeystore(id, cfg))
+		res = append(res, checkEVMSyntax(id, cfg)...)
+		res = append(res, v.checkRPCs(ctx, id, cfg, expected[id])...)
+	}
+
+	if err == nil {
+		for _, ci := range chainInfos {
+			id := ci.GetChainReferenceID()
+			var err error
+			if _, ok := v.Config.EVM[id]; !ok {
+				err = ErrMissingChainConfig
+			}
+			res = append(res, Result{Check: CheckPalomaChain, Subject: id, Err: err})
+		}
+	}
+
+	return res
+}
+
+func (v Validator) checkPalomaSyntax() []Result {
+	p := v.Config.Paloma
+	res := []Result{
+		{Check: CheckSyntax, Subject: "paloma/call-timeout", Err: checkDuration(p.CallTimeout)},
+		{Check: CheckSyntax, Subject: "circuit-breaker/cooldown", Err: checkDuration(v.Config.CircuitBreaker.Cooldown)},
+	}
+
+	var err error
+	if len(p.GasPrices) > 0 {
+		_, err = sdk.ParseDecCoins(p.GasPrices)
+	}
+	res = append(res, Result{Check: CheckSyntax, Subject: "paloma/gas-prices", Err: err})
+
+	return res
+}
+
+func (v Validator) checkSigningKeys() []Result {
+	keys := v.Config.Paloma.SigningKeys
+	if len(keys) < 1 {
+		return []Result{{Check: CheckSigningKey, Subject: "paloma", Err: ErrMissingValue.Format("signing-keys")}}
+	}
+
+	res := make([]Result, 0, len(keys))
+	for _, k := range keys {
+		_, err := v.Keyring.Key(k)
+		res = append(res, Result{Check: CheckSigningKey, Subject: k, Err: err})
+	}
+	return res
+}
+
+func checkEVMConfig(id string, cfg config.EVM) Result {
+	r := Result{Check: CheckEVMConfig, Subject: id}
+	switch {
+	case len(cfg.RPCURLs()) < 1:
+		r.Err = ErrMissingValue.Format("base-rpc-url")
+	case len(cfg.KeyringPassEnvName) < 1 && len(cfg.KeyringPass.Type) < 1:
+		r.Err = ErrMissingValue.Format("keyring-pass-env-name or keyring-pass")
+	case len(cfg.KeyringDirectory) < 1:
+		r.Err = ErrMissingValue.Format("keyring-dir")
+	case !common.IsHexAddress(cfg.SigningKey):
+		r.Err = ErrInvalidAddress.Format(cfg.SigningKey)
+	}
+	return r
+}
+
+func checkKeystore(id string, cfg config.EVM) Result {
+	r := Result{Check: CheckKeystore, Subject: id}
+	if !common.IsHexAddress(cfg.SigningKey) {
+		r.Err = ErrInvalidAddress.Format(cfg.SigningKey)
+		return r
+	}
+
+	pass, err := cfg.KeyringPassword()
+	if err != nil {
+		r.Err = err
+		return r
+	}
+
+	addr := common.HexToAddress(cfg.SigningKey)
+	ks := evm.OpenKeystore(cfg.KeyringDirectory.Path())
+	if !ks.HasAddress(addr) {
+		r.Err = ErrAddressNotInKeystore.Format(cfg.SigningKey, cfg.KeyringDirectory.Path())
+		return r
+	}
+
+	r.Err = ks.Unlock(accounts.Account{Address: addr}, pass)
+	return r
+}
+
+func checkEVMSyntax(id string, cfg config.EVM) []Result {
+	var txTypeErr error
+	if cfg.TxType > 2 {
+		txTypeErr = ErrInvalidTxType.Format(cfg.TxType)
+	}
+
+	return []Result{
+		{Check: CheckSyntax, Subject: id + "/tx-type", Err: txTypeErr},
+		{Check: CheckSyntax, Subject: id + "/call-timeout", Err: checkDuration(cfg.CallTimeout)},
+		{Check: CheckSyntax, Subject: id + "/gas-price", Err: evm.CheckGasPriceConfig(cfg)},
+	}
+}
+
+func (v Validator) checkRPCs(ctx context.Context, id string, cfg config.EVM, ci *evmtypes.ChainInfo) []Result {
+	urls := cfg.RPCURLs()
+	res := make([]Result, 0, len(urls))
+	for _, url := range urls {
+		r := Result{Check: CheckRPC, Subject: id + " " + url}
+		chainID, err := v.DialChainID(ctx, url)
+		switch {
+		case err != nil:
+			r.Err = err
+		case ci != nil && chainID.Cmp(new(big.Int).SetUint64(ci.GetChainID())) != 0:
+			r.Err = ErrChainIDMismatch.Format(ci.GetChainID(), chainID)
+		}
+		res = append(res, r)
+	}
+	return res
+}
+
+// DialChainID connects to an EVM RPC endpoint and returns its eth_chainId.
+func DialChainID(ctx context.Context, url string) (*big.Int, error) {
+	ctx, cancel := context.WithTimeout(ctx, defaultDialTimeout)
+	defer cancel()
+
+	client, err := ethclient.DialContext(ctx, url)
+	if err != nil {
+		return nil, err
+	}
+	defer client.Close()
+
+	return client.ChainID(ctx)
+}
+
+func checkDuration(s string) error {
+	if len(s) < 1 {
+		return nil
+	}
+	_, err := time.ParseDuration(s)
+	return err
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
@@ -0,0 +1,155 @@
+package configcheck
+
+import (
+	"context"
+	"errors"
+	"math/big"
+	"testing"
+
+	"github.com/cosmos/cosmos-sdk/crypto/keyring"
+	evmtypes "github.com/palomachain/paloma/v2/x/evm/types"
+	"github.com/palomachain/pigeon/chain/evm"
+	"github.com/palomachain/pigeon/config"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeKeyring map[string]struct{}
+
+func (f fakeKeyring) Key(uid string) (*keyring.Record, error) {
+	if _, ok := f[uid]; !ok {
+		return nil, errors.New("key not found")
+	}
+	return &keyring.Record{Name: uid}, nil
+}
+
+func failures(results []Result) map[string]error {
+	res := make(map[string]error)
+	for _, r := range results {
+		if !r.Passed() {
+			res[r.Check+" "+r.Subject] = r.Err
+		}
+	}
+	return res
+}
+
+func TestValidator(t *testing.T) {
+	ctx := context.Background()
+	dir := t.TempDir()
+	acc, err := evm.OpenKeystore(dir).NewAccount("secret")
+	require.NoError(t, err)
+	t.Setenv("TEST_VALIDATE_PASS", "secret")
+	t.Setenv("TEST_VALIDATE_WRONG_PASS", "wrong")
+
+	chainInfos := func(context.Context) ([]*evmtypes.ChainInfo, error) {
+		return []*evmtypes.ChainInfo{
+			{ChainReferenceID: "eth-main", ChainID: 1},
+			{ChainReferenceID: "bnb-main", ChainID: 56},
+		}, nil
+	}
+	chainIDs := map[string]int64{
+		"http://eth-1": 1,
+		"http://eth-2": 5,
+	}
+	dial := func(_ context.Context, url string) (*big.Int, error) {
+		id, ok := chainIDs[url]
+		if !ok {
+			return nil, errors.New("connection refused")
+		}
+		return big.NewInt(id), nil
+	}
+
+	validEVM := config.EVM{
+		ChainClientConfig: config.ChainClientConfig{
+			BaseRPCURL:         "http://eth-1",
+			KeyringPassEnvName: "TEST_VALIDATE_PASS",
+			SigningKey:         acc.Address.Hex(),
+			KeyringDirectory:   config.Filepath(dir),
+			CallTimeout:        "20s",
+		},
+		EVMSpecificClientConfig: config.EVMSpecificClientConfig{TxType: 2},
+	}
+
+	t.Run("with a valid config all checks pass", func(t *testing.T) {
+		results := Validator{
+			Config: &config.Config{
+				Paloma: config.Paloma{
+					CosmosSpecificClientConfig: config.CosmosSpecificClientConfig{GasPrices: "0.01ugrain"},
+					SigningKeys:                []string{"key-1"},
+				},
+				EVM: map[string]config.EVM{
+					"eth-main": validEVM,
+					"bnb-main": func() config.EVM {
+						c := validEVM
+						c.BaseRPCURL = ""
+						c.BaseRPCURLs = []string{"http://bnb"}
+						return c
+					}(),
+				},
+			},
+			ChainInfos: chainInfos,
+			DialChainID: func(ctx context.Context, url string) (*big.Int, error) {
+				if url == "http://bnb" {
+					return big.NewInt(56), nil
+				}
+				return dial(ctx, url)
+			},
+			Keyring: fakeKeyring{"key-1": {}},
+		}.Run(ctx)
+
+		assert.NotEmpty(t, results)
+		assert.Empty(t, failures(results))
+	})
+
+	t.Run("it reports every failing check", func(t *testing.T) {
+		broken := validEVM
+		broken.BaseRPCURLs = []string{"http://eth-2", "http://eth-3"}
+		broken.KeyringPassEnvName = "TEST_VALIDATE_WRONG_PASS"
+		broken.CallTimeout = "twenty seconds"
+		broken.TxType = 3
+		broken.GasPrice = config.GasPriceConfig{Strategy: "fee-history"}
+
+		results := Validator{
+			Config: &config.Config{
+				Paloma: config.Paloma{
+					CosmosSpecificClientConfig: config.CosmosSpecificClientConfig{GasPrices: "ugrain"},
+					SigningKeys:                []string{"key-1", "key-2"},
+				},
+				EVM: map[string]config.EVM{
+					"eth-main": broken,
+				},
+			},
+			ChainInfos:  chainInfos,
+			DialChainID: dial,
+			Keyring:     fakeKeyring{"key-1": {}},
+		}.Run(ctx)
+
+		f := failures(results)
+		assert.Len(t, f, 9)
+		assert.Contains(t, f, "syntax paloma/gas-prices")
+		assert.Contains(t, f, "signing-key key-2")
+		assert.Contains(t, f, "keystore eth-main")
+		assert.Contains(t, f, "syntax eth-main/tx-type")
+		assert.Contains(t, f, "syntax eth-main/call-timeout")
+		assert.Contains(t, f, "syntax eth-main/gas-price")
+		assert.ErrorIs(t, f["rpc eth-main http://eth-2"], ErrChainIDMismatch)
+		assert.Contains(t, f, "rpc eth-main http://eth-3")
+		assert.ErrorIs(t, f["paloma-chain bnb-main"], ErrMissingChainConfig)
+	})
+
+	t.Run("with an invalid signing key", func(t *testing.T) {
+		broken := validEVM
+		broken.SigningKey = "not-an-address"
+
+		f := failures(Validator{
+			Config:      &config.Config{EVM: map[string]config.EVM{"eth-main": broken}, Paloma: config.Paloma{SigningKeys: []string{"key-1"}}},
+			ChainInfos:  func(context.Context) ([]*evmtypes.ChainInfo, error) { return nil, nil },
+			DialChainID: dial,
+			Keyring:     fakeKeyring{"key-1": {}},
+		}.Run(ctx))
+
+		assert.Len(t, f, 2)
+		assert.Contains(t, f, "evm-config eth-main")
+		assert.Contains(t, f, "keystore eth-main")
+	})
+}
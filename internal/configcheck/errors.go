@@ -0,0 +1,12 @@
+package configcheck
+
+import "github.com/VolumeFi/whoops"
+
+const (
+	ErrMissingValue         = whoops.Errorf("missing value: %s")
+	ErrInvalidAddress       = whoops.Errorf("invalid address: '%s'")
+	ErrAddressNotInKeystore = whoops.Errorf("address '%s' not found in keystore: %s")
+	ErrChainIDMismatch      = whoops.Errorf("chain ID mismatch: paloma expects %d, rpc reports %s")
+	ErrInvalidTxType        = whoops.Errorf("invalid tx-type: %d, must be 0, 1 or 2")
+	ErrMissingChainConfig   = whoops.String("chain is supported by paloma, but not configured")
+)
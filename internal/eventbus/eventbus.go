@@ -0,0 +1,135 @@
+// Package eventbus publishes typed relayer lifecycle events, such as
+// relayed messages or submitted claims, to any number of subscribers.
+package eventbus
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+
+	"github.com/palomachain/pigeon/internal/liblog"
+)
+
+// DefaultBuffer is the amount of events a subscription buffers before
+// new events are dropped for it.
+const DefaultBuffer = 256
+
+// Kind identifies the type of an event.
+type Kind string
+
+// Event is implemented by all events published on the bus.
+type Event interface {
+	Kind() Kind
+}
+
+// Bus delivers every published event to all subscriptions interested in
+// its kind. Publishing never blocks, so a slow subscriber can't hold up
+// the relayer. Events are dropped for subscribers which fall behind.
+// A nil bus drops all events.
+type Bus struct {
+	mu   sync.RWMutex
+	subs map[*Subscription]struct{}
+}
+
+func New() *Bus {
+	return &Bus{subs: make(map[*Subscription]struct{})}
+}
+
+// Subscription receives the events a subscriber is interested in.
+type Subscription struct {
+	bus     *Bus
+	kinds   map[Kind]struct{}
+	ch      chan Event
+	dropped atomic.Uint64
+	once    sync.Once
+}
+
+// Subscribe subscribes to events of the given kinds, or to all events if
+// no kinds are given. A buffer of 0 or less uses DefaultBuffer.
+func (b *Bus) Subscribe(buffer int, kinds ...Kind) *Subscription {
+	if buffer <= 0 {
+		buffer = DefaultBuffer
+	}
+	s := &Subscription{
+		bus:   b,
+		kinds: make(map[Kind]struct{}, len(kinds)),
+		ch:    make(chan Event, buffer),
+	}
+	for _, k := range kinds {
+		s.kinds[k] = struct{}{}
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	b.subs[s] = struct{}{}
+	return s
+}
+
+// Publish delivers the event to all interested subscriptions.
+func (b *Bus) Publish(ctx context.Context, e Event) {
+	if b == nil {
+		return
+	}
+
+	b.mu.RLock()
+	defer b.mu.RUnlock()
+	for s := range b.subs {
+		if !s.wants(e.Kind()) {
+			continue
+		}
+		select {
+		case s.ch <- e:
+		default:
+			if s.dropped.Add(1) == 1 {
+				liblog.WithContext(ctx).WithField("kind", e.Kind()).Warn("Event subscriber is falling behind, dropping events.")
+			}
+		}
+	}
+}
+
+func (s *Subscription) wants(k Kind) bool {
+	if len(s.kinds) == 0 {
+		return true
+	}
+	_, ok := s.kinds[k]
+	return ok
+}
+
+// Events returns the channel events are delivered on. It's closed once
+// the subscription is closed.
+func (s *Subscription) Events() <-chan Event {
+	return s.ch
+}
+
+// Dropped returns how many events were dropped because the subscriber
+// didn't keep up.
+func (s *Subscription) Dropped() uint64 {
+	return s.dropped.Load()
+}
+
+// Close unsubscribes from the bus.
+func (s *Subscription) Close() {
+	s.once.Do(func() {
+		s.bus.mu.Lock()
+		defer s.bus.mu.Unlock()
+		delete(s.bus.subs, s)
+		close(s.ch)
+	})
+}
+
+// Handle calls fn with every event of the subscription until the context
+// is done or the subscription is closed, and closes the subscription.
+func Handle(ctx context.Context, s *Subscription, fn func(Event)) {
+	defer s.Close()
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case e, ok := <-s.ch:
+			if !ok {
+				return
+			}
+			fn(e)
+		}
+	}
+}
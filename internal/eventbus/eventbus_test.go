@@ -0,0 +1,83 @@
+package eventbus
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestBus(t *testing.T) {
+	ctx := context.Background()
+
+	t.Run("events are delivered to interested subscribers", func(t *testing.T) {
+		b := New()
+		all := b.Subscribe(0)
+		relayed := b.Subscribe(0, KindMessageRelayed)
+		defer all.Close()
+		defer relayed.Close()
+
+		b.Publish(ctx, MessageSigned{MessageID: 1})
+		b.Publish(ctx, MessageRelayed{MessageID: 2, TxHash: "0xabc"})
+
+		assert.Equal(t, MessageSigned{MessageID: 1}, <-all.Events())
+		assert.Equal(t, MessageRelayed{MessageID: 2, TxHash: "0xabc"}, <-all.Events())
+		assert.Equal(t, MessageRelayed{MessageID: 2, TxHash: "0xabc"}, <-relayed.Events())
+		assert.Empty(t, relayed.Events())
+	})
+
+	t.Run("publishing never blocks on slow subscribers", func(t *testing.T) {
+		b := New()
+		s := b.Subscribe(1)
+		defer s.Close()
+
+		b.Publish(ctx, KeepAliveSent{})
+		b.Publish(ctx, KeepAliveSent{})
+		b.Publish(ctx, KeepAliveSent{})
+
+		assert.Len(t, s.Events(), 1)
+		assert.Equal(t, uint64(2), s.Dropped())
+	})
+
+	t.Run("closed subscriptions don't receive events", func(t *testing.T) {
+		b := New()
+		s := b.Subscribe(0)
+		s.Close()
+		s.Close()
+
+		b.Publish(ctx, KeepAliveSent{})
+		_, ok := <-s.Events()
+		assert.False(t, ok)
+	})
+
+	t.Run("a nil bus drops all events", func(t *testing.T) {
+		var b *Bus
+		assert.NotPanics(t, func() { b.Publish(ctx, KeepAliveSent{}) })
+	})
+
+	t.Run("handle calls the handler until the context is done", func(t *testing.T) {
+		b := New()
+		ctx, cancel := context.WithCancel(ctx)
+		got := make(chan Event, 1)
+		done := make(chan struct{})
+		s := b.Subscribe(0)
+		go func() {
+			Handle(ctx, s, func(e Event) { got <- e })
+			close(done)
+		}()
+
+		b.Publish(ctx, ProcessorRebuilt{ChainReferenceID: "eth-main"})
+		require.Equal(t, ProcessorRebuilt{ChainReferenceID: "eth-main"}, <-got)
+
+		cancel()
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Fatal("handle didn't return")
+		}
+		_, ok := <-s.Events()
+		assert.False(t, ok)
+	})
+}
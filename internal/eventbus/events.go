@@ -0,0 +1,90 @@
+package eventbus
+
+const (
+	KindMessageSigned      Kind = "message-signed"
+	KindMessageEstimated   Kind = "message-estimated"
+	KindMessageRelayed     Kind = "message-relayed"
+	KindMessageAttested    Kind = "message-attested"
+	KindSkywayBatchRelayed Kind = "skyway-batch-relayed"
+	KindClaimSubmitted     Kind = "claim-submitted"
+	KindProcessorRebuilt   Kind = "processor-rebuilt"
+	KindKeepAliveSent      Kind = "keep-alive-sent"
+)
+
+// MessageSigned is published once the signature of a message was sent to
+// Paloma.
+type MessageSigned struct {
+	ChainReferenceID string `json:"chain-reference-id"`
+	Queue            string `json:"queue"`
+	MessageID        uint64 `json:"message-id"`
+}
+
+func (MessageSigned) Kind() Kind { return KindMessageSigned }
+
+// MessageEstimated is published once the gas estimate of a message was
+// sent to Paloma.
+type MessageEstimated struct {
+	ChainReferenceID string `json:"chain-reference-id"`
+	Queue            string `json:"queue"`
+	MessageID        uint64 `json:"message-id"`
+	Estimate         uint64 `json:"estimate"`
+}
+
+func (MessageEstimated) Kind() Kind { return KindMessageEstimated }
+
+// MessageRelayed is published once the transaction relaying a message
+// was sent to the target chain.
+type MessageRelayed struct {
+	ChainReferenceID string `json:"chain-reference-id"`
+	Queue            string `json:"queue"`
+	MessageID        uint64 `json:"message-id"`
+	TxHash           string `json:"tx-hash"`
+}
+
+func (MessageRelayed) Kind() Kind { return KindMessageRelayed }
+
+// MessageAttested is published once the evidence of a message was sent to
+// Paloma.
+type MessageAttested struct {
+	ChainReferenceID string `json:"chain-reference-id"`
+	Queue            string `json:"queue"`
+	MessageID        uint64 `json:"message-id"`
+}
+
+func (MessageAttested) Kind() Kind { return KindMessageAttested }
+
+// SkywayBatchRelayed is published once the transaction relaying a Skyway
+// batch was sent to the target chain.
+type SkywayBatchRelayed struct {
+	ChainReferenceID string `json:"chain-reference-id"`
+	BatchNonce       uint64 `json:"batch-nonce"`
+	TxHash           string `json:"tx-hash"`
+}
+
+func (SkywayBatchRelayed) Kind() Kind { return KindSkywayBatchRelayed }
+
+// ClaimSubmitted is published once the claim for a Skyway event was sent
+// to Paloma.
+type ClaimSubmitted struct {
+	ChainReferenceID string `json:"chain-reference-id"`
+	EventNonce       uint64 `json:"event-nonce"`
+	SkywayNonce      uint64 `json:"skyway-nonce"`
+}
+
+func (ClaimSubmitted) Kind() Kind { return KindClaimSubmitted }
+
+// ProcessorRebuilt is published whenever the processor of a chain was
+// built, because the chain is new or its config changed.
+type ProcessorRebuilt struct {
+	ChainReferenceID string `json:"chain-reference-id"`
+}
+
+func (ProcessorRebuilt) Kind() Kind { return KindProcessorRebuilt }
+
+// KeepAliveSent is published whenever the validator was kept alive on
+// Paloma.
+type KeepAliveSent struct {
+	AppVersion string `json:"app-version"`
+}
+
+func (KeepAliveSent) Kind() Kind { return KindKeepAliveSent }
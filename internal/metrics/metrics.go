@@ -0,0 +1,237 @@
+package metrics
+
+import (
+	"math/big"
+	"net/http"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+	"github.com/prometheus/client_golang/prometheus/promhttp"
+)
+
+const namespace = "pigeon"
+
+const (
+	labelProcess          = "process"
+	labelChainReferenceID = "chain_reference_id"
+	labelQueue            = "queue"
+	labelAction           = "action"
+	labelAddress          = "address"
+	labelMsgType          = "msg_type"
+	labelOutcome          = "outcome"
+	labelQuery            = "query"
+	labelResult           = "result"
+	labelBreaker          = "breaker"
+	labelKind             = "kind"
+)
+
+const (
+	ActionSigned    = "signed"
+	ActionRelayed   = "relayed"
+	ActionEstimated = "estimated"
+	ActionAttested  = "attested"
+
+	OutcomeSuccess    = "success"
+	OutcomeFailure    = "failure"
+	OutcomePalomaDown = "paloma-down"
+
+	ResultHit  = "hit"
+	ResultMiss = "miss"
+)
+
+var (
+	processIterations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "process_iterations_total",
+		Help:      "Number of executions of a relayer process loop.",
+	}, []string{labelProcess})
+
+	processErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "process_errors_total",
+		Help:      "Number of failed executions of a relayer process loop.",
+	}, []string{labelProcess})
+
+	processDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "process_duration_seconds",
+		Help:      "Duration of a single relayer process loop execution.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{labelProcess})
+
+	chainProcessIterations = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chain_process_iterations_total",
+		Help:      "Number of executions of a relayer process loop for a single chain.",
+	}, []string{labelProcess, labelChainReferenceID})
+
+	chainProcessErrors = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "chain_process_errors_total",
+		Help:      "Number of failed executions of a relayer process loop for a single chain.",
+	}, []string{labelProcess, labelChainReferenceID})
+
+	chainProcessDuration = promauto.NewHistogramVec(prometheus.HistogramOpts{
+		Namespace: namespace,
+		Name:      "chain_process_duration_seconds",
+		Help:      "Duration of a single relayer process loop execution for a single chain.",
+		Buckets:   []float64{.01, .05, .1, .25, .5, 1, 2.5, 5, 10, 30, 60, 120},
+	}, []string{labelProcess, labelChainReferenceID})
+
+	messages = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "messages_total",
+		Help:      "Number of consensus queue messages handled, by action.",
+	}, []string{labelChainReferenceID, labelQueue, labelAction})
+
+	skywayBatches = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "skyway_batches_total",
+		Help:      "Number of Skyway batches handled, by action.",
+	}, []string{labelChainReferenceID, labelAction})
+
+	skywayClaims = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "skyway_claims_total",
+		Help:      "Number of Skyway event claims submitted to Paloma.",
+	}, []string{labelChainReferenceID})
+
+	evmGasUsed = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "evm_gas_used_total",
+		Help:      "Gas used by transactions sent by this pigeon.",
+	}, []string{labelChainReferenceID})
+
+	evmFeesPaid = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "evm_fees_paid_wei_total",
+		Help:      "Transaction fees in wei paid by this pigeon.",
+	}, []string{labelChainReferenceID})
+
+	evmGasSpend = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "evm_gas_spend_24h_wei",
+		Help:      "Maximum cost in wei of the transactions sent by this pigeon in the last 24 hours.",
+	}, []string{labelChainReferenceID})
+
+	balance = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "account_balance_wei",
+		Help:      "Last known balance of the pigeon's account in wei.",
+	}, []string{labelChainReferenceID, labelAddress})
+
+	palomaBroadcasts = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "paloma_tx_broadcasts_total",
+		Help:      "Number of transactions broadcast to Paloma, by outcome.",
+	}, []string{labelChainReferenceID, labelMsgType, labelOutcome})
+
+	palomaQueryCache = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "paloma_query_cache_total",
+		Help:      "Number of Paloma queries answered by the query cache, by result.",
+	}, []string{labelQuery, labelResult})
+
+	circuitBreakerOpen = promauto.NewGaugeVec(prometheus.GaugeOpts{
+		Namespace: namespace,
+		Name:      "circuit_breaker_open",
+		Help:      "Whether the circuit breaker of a chain or queue is open.",
+	}, []string{labelChainReferenceID, labelBreaker})
+
+	events = promauto.NewCounterVec(prometheus.CounterOpts{
+		Namespace: namespace,
+		Name:      "events_total",
+		Help:      "Number of relayer lifecycle events published, by kind.",
+	}, []string{labelKind})
+)
+
+// Handler returns the HTTP handler serving all registered metrics.
+func Handler() http.Handler {
+	return promhttp.Handler()
+}
+
+// ObserveProcess records a single execution of a relayer process loop.
+func ObserveProcess(process string, duration time.Duration, err error) {
+	processIterations.WithLabelValues(process).Inc()
+	processDuration.WithLabelValues(process).Observe(duration.Seconds())
+	if err != nil {
+		processErrors.WithLabelValues(process).Inc()
+	}
+}
+
+// ObserveChainProcess records the work of a single execution of a relayer
+// process loop on one chain.
+func ObserveChainProcess(process, chainReferenceID string, duration time.Duration, failed bool) {
+	chainProcessIterations.WithLabelValues(process, chainReferenceID).Inc()
+	chainProcessDuration.WithLabelValues(process, chainReferenceID).Observe(duration.Seconds())
+	if failed {
+		chainProcessErrors.WithLabelValues(process, chainReferenceID).Inc()
+	}
+}
+
+// AddMessages records n messages of a queue which went through the given action.
+func AddMessages(chainReferenceID, queueName, action string, n int) {
+	messages.WithLabelValues(chainReferenceID, queueName, action).Add(float64(n))
+}
+
+// AddSkywayBatches records n Skyway batches which went through the given action.
+func AddSkywayBatches(chainReferenceID, action string, n int) {
+	skywayBatches.WithLabelValues(chainReferenceID, action).Add(float64(n))
+}
+
+// AddSkywayClaims records n Skyway event claims submitted to Paloma.
+func AddSkywayClaims(chainReferenceID string, n int) {
+	skywayClaims.WithLabelValues(chainReferenceID).Add(float64(n))
+}
+
+// AddEvent records a published relayer lifecycle event.
+func AddEvent(kind string) {
+	events.WithLabelValues(kind).Inc()
+}
+
+// AddGasSpent records the gas used and fees paid by a mined EVM transaction.
+func AddGasSpent(chainReferenceID string, gasUsed uint64, fee *big.Int) {
+	evmGasUsed.WithLabelValues(chainReferenceID).Add(float64(gasUsed))
+	if fee != nil {
+		f, _ := new(big.Float).SetInt(fee).Float64()
+		evmFeesPaid.WithLabelValues(chainReferenceID).Add(f)
+	}
+}
+
+// SetGasSpend records what was spent on transactions in the last 24 hours.
+func SetGasSpend(chainReferenceID string, wei *big.Int) {
+	f, _ := new(big.Float).SetInt(wei).Float64()
+	evmGasSpend.WithLabelValues(chainReferenceID).Set(f)
+}
+
+// SetBalance records the current balance of an account.
+func SetBalance(chainReferenceID, address string, wei *big.Int) {
+	f, _ := new(big.Float).SetInt(wei).Float64()
+	balance.WithLabelValues(chainReferenceID, address).Set(f)
+}
+
+// AddPalomaBroadcast records the outcome of a transaction broadcast to Paloma.
+// The chain reference ID may be empty for messages unrelated to a chain.
+func AddPalomaBroadcast(chainReferenceID, msgType, outcome string) {
+	palomaBroadcasts.WithLabelValues(chainReferenceID, msgType, outcome).Inc()
+}
+
+// ObserveQueryCache records a hit or miss of the Paloma query cache.
+func ObserveQueryCache(query string, hit bool) {
+	result := ResultMiss
+	if hit {
+		result = ResultHit
+	}
+	palomaQueryCache.WithLabelValues(query, result).Inc()
+}
+
+// SetCircuitBreakerOpen records whether the circuit breaker of a chain or
+// queue is open.
+func SetCircuitBreakerOpen(chainReferenceID, breaker string, open bool) {
+	var v float64
+	if open {
+		v = 1
+	}
+	circuitBreakerOpen.WithLabelValues(chainReferenceID, breaker).Set(v)
+}
@@ -0,0 +1,56 @@
+package metrics
+
+import (
+	"errors"
+	"math/big"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/require"
+)
+
+func TestObserveProcess(t *testing.T) {
+	ObserveProcess("test-process", time.Second, nil)
+	ObserveProcess("test-process", time.Second, errors.New("boom"))
+
+	require.Equal(t, 2.0, testutil.ToFloat64(processIterations.WithLabelValues("test-process")))
+	require.Equal(t, 1.0, testutil.ToFloat64(processErrors.WithLabelValues("test-process")))
+}
+
+func TestObserveChainProcess(t *testing.T) {
+	ObserveChainProcess("test-process", "test-chain", time.Second, false)
+	ObserveChainProcess("test-process", "test-chain", time.Second, true)
+	ObserveChainProcess("test-process", "other-chain", time.Second, false)
+
+	require.Equal(t, 2.0, testutil.ToFloat64(chainProcessIterations.WithLabelValues("test-process", "test-chain")))
+	require.Equal(t, 1.0, testutil.ToFloat64(chainProcessErrors.WithLabelValues("test-process", "test-chain")))
+	require.Equal(t, 0.0, testutil.ToFloat64(chainProcessErrors.WithLabelValues("test-process", "other-chain")))
+}
+
+func TestAddGasSpent(t *testing.T) {
+	AddGasSpent("test-chain", 21_000, big.NewInt(42_000))
+	AddGasSpent("test-chain", 21_000, nil)
+
+	require.Equal(t, 42_000.0, testutil.ToFloat64(evmGasUsed.WithLabelValues("test-chain")))
+	require.Equal(t, 42_000.0, testutil.ToFloat64(evmFeesPaid.WithLabelValues("test-chain")))
+}
+
+func TestSetGasSpend(t *testing.T) {
+	SetGasSpend("test-chain", big.NewInt(1_000))
+	SetGasSpend("test-chain", big.NewInt(400))
+
+	require.Equal(t, 400.0, testutil.ToFloat64(evmGasSpend.WithLabelValues("test-chain")))
+}
+
+func TestHandler(t *testing.T) {
+	AddMessages("test-chain", "evm/test-chain/turnstone", ActionSigned, 3)
+
+	rec := httptest.NewRecorder()
+	Handler().ServeHTTP(rec, httptest.NewRequest("GET", "/metrics", nil))
+
+	require.True(t, strings.Contains(rec.Body.String(),
+		`pigeon_messages_total{action="signed",chain_reference_id="test-chain",queue="evm/test-chain/turnstone"} 3`))
+}
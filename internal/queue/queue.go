@@ -29,3 +29,13 @@ func (t TypeName) IsValidatorsValancesQueue() bool {
 func (t TypeName) IsReferenceBlockQueue() bool {
 	return strings.HasSuffix(string(t), QueueSuffixReferenceBlock)
 }
+
+// ChainReferenceID returns the chain reference ID of a queue name
+// in the form of <chain-type>/<chain-reference-id>/<queue-suffix>.
+func (t TypeName) ChainReferenceID() string {
+	parts := strings.Split(string(t), "/")
+	if len(parts) != 3 {
+		return ""
+	}
+	return parts[1]
+}
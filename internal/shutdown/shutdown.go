@@ -0,0 +1,40 @@
+// Package shutdown lets in-flight work outlive the cancellation of its
+// parent context, so it can be finished cleanly during a shutdown.
+package shutdown
+
+import "context"
+
+type requestedKey struct{}
+
+// Detach returns a context which isn't canceled together with parent.
+// Instead, the cancellation of parent marks a shutdown as requested, which
+// can be checked with Requested and IsRequested. The returned context is
+// only canceled by calling cancel.
+func Detach(parent context.Context) (context.Context, context.CancelFunc) {
+	ctx := context.WithValue(context.WithoutCancel(parent), requestedKey{}, parent.Done())
+	return context.WithCancel(ctx)
+}
+
+// Requested returns a channel which is closed once a shutdown was requested.
+// For contexts which weren't detached, this is the same as ctx.Done().
+func Requested(ctx context.Context) <-chan struct{} {
+	if ch, ok := ctx.Value(requestedKey{}).(<-chan struct{}); ok {
+		return ch
+	}
+	return ctx.Done()
+}
+
+// IsRequested reports whether a shutdown was requested or the context is
+// done. Long running work should check it before starting anything new.
+func IsRequested(ctx context.Context) bool {
+	if ctx.Err() != nil {
+		return true
+	}
+
+	select {
+	case <-Requested(ctx):
+		return true
+	default:
+		return false
+	}
+}
@@ -0,0 +1,33 @@
+package shutdown
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestDetach(t *testing.T) {
+	parent, cancelParent := context.WithCancel(context.Background())
+	ctx, cancel := Detach(parent)
+	defer cancel()
+
+	assert.False(t, IsRequested(ctx))
+
+	cancelParent()
+	<-Requested(ctx)
+	assert.True(t, IsRequested(ctx))
+	assert.NoError(t, ctx.Err(), "detached context must outlive its parent")
+
+	cancel()
+	assert.ErrorIs(t, ctx.Err(), context.Canceled)
+}
+
+func TestRequestedWithoutDetach(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	assert.False(t, IsRequested(ctx))
+
+	cancel()
+	<-Requested(ctx)
+	assert.True(t, IsRequested(ctx))
+}
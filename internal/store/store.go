@@ -0,0 +1,190 @@
+// Package store persists relayer progress, so that it survives restarts.
+package store
+
+import (
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"strconv"
+	"sync"
+	"time"
+
+	"github.com/VolumeFi/whoops"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// SchemaVersion is the version of the persisted data layout. Bump it
+	// and add a migration whenever the layout of stored values changes.
+	SchemaVersion = 1
+
+	metaBucket       = "meta"
+	schemaVersionKey = "schema-version"
+
+	openTimeout = 5 * time.Second
+)
+
+const (
+	ErrUnsupportedSchema = whoops.Errorf("state store schema version %d is newer than the supported version %d")
+	ErrMissingMigration  = whoops.Errorf("no migration for state store schema version %d")
+)
+
+// migration upgrades the store from the schema version it's registered
+// for to the next one.
+type migration func(tx *bolt.Tx) error
+
+// migrations holds the migration from version i+1 to i+2 at index i.
+var migrations = []migration{}
+
+// Store is a key value store for relayer state. Values are encoded as JSON.
+type Store interface {
+	// Get decodes the value stored under key into v and reports whether
+	// the key was found.
+	Get(bucket, key string, v any) (bool, error)
+	Put(bucket, key string, v any) error
+	Delete(bucket, key string) error
+	Close() error
+}
+
+type boltStore struct {
+	db *bolt.DB
+}
+
+// Open opens the file backed store at path, creating it if necessary, and
+// migrates it to the current schema version. Every write is committed in
+// its own transaction and synced to disk, so a crash never leaves partial
+// writes behind.
+func Open(path string) (Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0o700); err != nil {
+		return nil, err
+	}
+
+	db, err := bolt.Open(path, 0o600, &bolt.Options{Timeout: openTimeout})
+	if err != nil {
+		return nil, err
+	}
+
+	if err := db.Update(migrate); err != nil {
+		db.Close()
+		return nil, err
+	}
+
+	return &boltStore{db: db}, nil
+}
+
+func migrate(tx *bolt.Tx) error {
+	b, err := tx.CreateBucketIfNotExists([]byte(metaBucket))
+	if err != nil {
+		return err
+	}
+
+	version := SchemaVersion
+	if raw := b.Get([]byte(schemaVersionKey)); raw != nil {
+		version, err = strconv.Atoi(string(raw))
+		if err != nil {
+			return err
+		}
+	}
+
+	if version > SchemaVersion {
+		return ErrUnsupportedSchema.Format(version, SchemaVersion)
+	}
+	for ; version < SchemaVersion; version++ {
+		if version-1 >= len(migrations) {
+			return ErrMissingMigration.Format(version)
+		}
+		if err := migrations[version-1](tx); err != nil {
+			return err
+		}
+	}
+
+	return b.Put([]byte(schemaVersionKey), []byte(strconv.Itoa(SchemaVersion)))
+}
+
+func (s *boltStore) Get(bucket, key string, v any) (bool, error) {
+	var raw []byte
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		if val := b.Get([]byte(key)); val != nil {
+			raw = append([]byte(nil), val...)
+		}
+		return nil
+	})
+	if err != nil || raw == nil {
+		return false, err
+	}
+
+	return true, json.Unmarshal(raw, v)
+}
+
+func (s *boltStore) Put(bucket, key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(bucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(key), raw)
+	})
+}
+
+func (s *boltStore) Delete(bucket, key string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket([]byte(bucket))
+		if b == nil {
+			return nil
+		}
+		return b.Delete([]byte(key))
+	})
+}
+
+func (s *boltStore) Close() error {
+	return s.db.Close()
+}
+
+type memoryStore struct {
+	mu   sync.Mutex
+	data map[string][]byte
+}
+
+// NewMemory returns a store which keeps all values in memory only.
+func NewMemory() Store {
+	return &memoryStore{data: make(map[string][]byte)}
+}
+
+func (s *memoryStore) Get(bucket, key string, v any) (bool, error) {
+	s.mu.Lock()
+	raw, ok := s.data[bucket+"/"+key]
+	s.mu.Unlock()
+	if !ok {
+		return false, nil
+	}
+	return true, json.Unmarshal(raw, v)
+}
+
+func (s *memoryStore) Put(bucket, key string, v any) error {
+	raw, err := json.Marshal(v)
+	if err != nil {
+		return err
+	}
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.data[bucket+"/"+key] = raw
+	return nil
+}
+
+func (s *memoryStore) Delete(bucket, key string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	delete(s.data, bucket+"/"+key)
+	return nil
+}
+
+func (s *memoryStore) Close() error { return nil }
@@ -0,0 +1,88 @@
+package store
+
+import (
+	"path/filepath"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	bolt "go.etcd.io/bbolt"
+)
+
+type testState struct {
+	Height uint64   `json:"height"`
+	IDs    []uint64 `json:"ids"`
+}
+
+func TestStore(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "nested", "state.db")
+
+	for name, open := range map[string]func(t *testing.T) Store{
+		"bolt": func(t *testing.T) Store {
+			s, err := Open(path)
+			require.NoError(t, err)
+			return s
+		},
+		"memory": func(*testing.T) Store { return NewMemory() },
+	} {
+		t.Run(name, func(t *testing.T) {
+			s := open(t)
+			defer s.Close()
+
+			var got testState
+			found, err := s.Get("bucket", "key", &got)
+			require.NoError(t, err)
+			assert.False(t, found)
+
+			want := testState{Height: 42, IDs: []uint64{1, 2}}
+			require.NoError(t, s.Put("bucket", "key", want))
+
+			found, err = s.Get("bucket", "key", &got)
+			require.NoError(t, err)
+			assert.True(t, found)
+			assert.Equal(t, want, got)
+
+			require.NoError(t, s.Delete("bucket", "key"))
+			found, err = s.Get("bucket", "key", &got)
+			require.NoError(t, err)
+			assert.False(t, found)
+		})
+	}
+}
+
+func TestStoreSurvivesRestarts(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+
+	s, err := Open(path)
+	require.NoError(t, err)
+	require.NoError(t, s.Put("bucket", "key", testState{Height: 7}))
+	require.NoError(t, s.Close())
+
+	s, err = Open(path)
+	require.NoError(t, err)
+	defer s.Close()
+
+	var got testState
+	found, err := s.Get("bucket", "key", &got)
+	require.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, uint64(7), got.Height)
+}
+
+func TestStoreRejectsNewerSchema(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "state.db")
+
+	db, err := bolt.Open(path, 0o600, nil)
+	require.NoError(t, err)
+	require.NoError(t, db.Update(func(tx *bolt.Tx) error {
+		b, err := tx.CreateBucketIfNotExists([]byte(metaBucket))
+		if err != nil {
+			return err
+		}
+		return b.Put([]byte(schemaVersionKey), []byte("99"))
+	}))
+	require.NoError(t, db.Close())
+
+	_, err = Open(path)
+	assert.ErrorIs(t, err, ErrUnsupportedSchema)
+}
@@ -0,0 +1,275 @@
+package relayer
+
+import (
+	"context"
+	goerrors "errors"
+	"io"
+	"net"
+	"net/http"
+	"strings"
+	"sync"
+	"syscall"
+	"time"
+
+	"github.com/ethereum/go-ethereum/rpc"
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/metrics"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	BreakerClosed   = "closed"
+	BreakerOpen     = "open"
+	BreakerHalfOpen = "half-open"
+)
+
+const (
+	defaultBreakerThreshold = 5
+	defaultBreakerCooldown  = time.Minute
+
+	// chainBreaker is the key of the breaker covering a whole chain, as
+	// opposed to the breakers of its queues.
+	chainBreaker = "chain"
+
+	// rpcLimitExceededCode is returned by most RPC providers when a client
+	// is being rate limited.
+	rpcLimitExceededCode = -32005
+)
+
+// BreakerStatus is the state of a circuit breaker, exposed via the
+// health check server.
+type BreakerStatus struct {
+	State               string     `json:"state"`
+	ConsecutiveFailures int        `json:"consecutive-failures"`
+	OpenUntil           *time.Time `json:"open-until,omitempty"`
+	LastError           string     `json:"last-error,omitempty"`
+}
+
+type circuitBreaker struct {
+	state     string
+	failures  int
+	openUntil time.Time
+	lastError string
+	// probing is set while a single caller probes the chain of a
+	// half-open breaker. All others are held back until it's done.
+	probing bool
+}
+
+// breakers holds a circuit breaker for every chain and every queue. Once
+// a breaker saw threshold consecutive connectivity failures it opens, and
+// all work on its chain or queue is skipped for the cooldown period. After
+// that, the chain is probed and the breaker closes again if the probe
+// succeeds. For processors which can't be probed, the breaker is half-open:
+// work resumes, and the next failure opens it again right away.
+type breakers struct {
+	mu        sync.Mutex
+	chains    map[string]map[string]*circuitBreaker
+	threshold int
+	cooldown  time.Duration
+	now       func() time.Time
+}
+
+func newBreakers(threshold int, cooldown time.Duration) *breakers {
+	if threshold < 1 {
+		threshold = defaultBreakerThreshold
+	}
+	if cooldown <= 0 {
+		cooldown = defaultBreakerCooldown
+	}
+	return &breakers{
+		chains:    make(map[string]map[string]*circuitBreaker),
+		threshold: threshold,
+		cooldown:  cooldown,
+		now:       time.Now,
+	}
+}
+
+func (b *breakers) get(chainReferenceID, key string) *circuitBreaker {
+	if _, ok := b.chains[chainReferenceID]; !ok {
+		b.chains[chainReferenceID] = make(map[string]*circuitBreaker)
+	}
+	cb, ok := b.chains[chainReferenceID][key]
+	if !ok {
+		cb = &circuitBreaker{state: BreakerClosed}
+		b.chains[chainReferenceID][key] = cb
+	}
+	return cb
+}
+
+// allow reports whether work on the given chain and queue may run. An
+// empty queue name only checks the chain's breaker. A nil set of breakers
+// allows everything.
+func (b *breakers) allow(ctx context.Context, p chain.Processor, chainReferenceID, queueName string) bool {
+	if b == nil {
+		return true
+	}
+
+	for _, key := range []string{chainBreaker, queueName} {
+		if key == "" {
+			continue
+		}
+		if !b.allowOne(ctx, p, chainReferenceID, key) {
+			return false
+		}
+	}
+	return true
+}
+
+func (b *breakers) allowOne(ctx context.Context, p chain.Processor, chainReferenceID, key string) bool {
+	prober, canProbe := p.(chain.Prober)
+
+	b.mu.Lock()
+	cb := b.get(chainReferenceID, key)
+	if cb.probing {
+		b.mu.Unlock()
+		return false
+	}
+	if cb.state != BreakerOpen {
+		b.mu.Unlock()
+		return true
+	}
+	if b.now().Before(cb.openUntil) {
+		b.mu.Unlock()
+		return false
+	}
+	cb.state = BreakerHalfOpen
+	cb.probing = canProbe
+	b.mu.Unlock()
+
+	logger := liblog.WithContext(ctx).WithFields(log.Fields{
+		"component":          "circuit-breaker",
+		"chain-reference-id": chainReferenceID,
+		"breaker":            key,
+	})
+
+	if !canProbe {
+		logger.Info("Cooldown elapsed, resuming work.")
+		return true
+	}
+
+	err := prober.Probe(ctx)
+	if err != nil {
+		logger.WithError(err).Warn("Probe failed, keeping circuit breaker open.")
+	}
+	b.recordOne(chainReferenceID, key, err)
+
+	b.mu.Lock()
+	cb.probing = false
+	b.mu.Unlock()
+	return err == nil
+}
+
+// record counts the outcome of work on the given chain and queue. Context
+// errors don't count at all. Any other error which isn't a connectivity
+// error, e.g. a reverted message, proves that the chain is reachable, so it
+// counts as a success.
+func (b *breakers) record(chainReferenceID, queueName string, err error) {
+	if b == nil || goerrors.Is(err, context.Canceled) || goerrors.Is(err, context.DeadlineExceeded) {
+		return
+	}
+	if !isConnectivityError(err) {
+		err = nil
+	}
+
+	for _, key := range []string{chainBreaker, queueName} {
+		if key != "" {
+			b.recordOne(chainReferenceID, key, err)
+		}
+	}
+}
+
+func (b *breakers) recordOne(chainReferenceID, key string, err error) {
+	b.mu.Lock()
+	defer b.mu.Unlock()
+
+	logger := log.WithFields(log.Fields{
+		"component":          "circuit-breaker",
+		"chain-reference-id": chainReferenceID,
+		"breaker":            key,
+	})
+	cb := b.get(chainReferenceID, key)
+	if err == nil {
+		if cb.state != BreakerClosed {
+			logger.Info("Circuit breaker closed.")
+		}
+		cb.state, cb.failures, cb.lastError = BreakerClosed, 0, ""
+		metrics.SetCircuitBreakerOpen(chainReferenceID, key, false)
+		return
+	}
+
+	cb.failures++
+	cb.lastError = err.Error()
+	if cb.state == BreakerHalfOpen || cb.failures >= b.threshold {
+		if cb.state == BreakerClosed {
+			logger.WithError(err).WithFields(log.Fields{
+				"failures": cb.failures,
+				"cooldown": b.cooldown,
+			}).Warn("Circuit breaker opened.")
+		}
+		cb.state = BreakerOpen
+		cb.openUntil = b.now().Add(b.cooldown)
+		metrics.SetCircuitBreakerOpen(chainReferenceID, key, true)
+	}
+}
+
+// status returns the state of all breakers of a chain.
+func (b *breakers) status(chainReferenceID string) map[string]BreakerStatus {
+	if b == nil {
+		return nil
+	}
+
+	b.mu.Lock()
+	defer b.mu.Unlock()
+	res := make(map[string]BreakerStatus, len(b.chains[chainReferenceID]))
+	for k, cb := range b.chains[chainReferenceID] {
+		s := BreakerStatus{
+			State:               cb.state,
+			ConsecutiveFailures: cb.failures,
+			LastError:           cb.lastError,
+		}
+		if cb.state == BreakerOpen {
+			openUntil := cb.openUntil.UTC()
+			s.OpenUntil = &openUntil
+		}
+		res[k] = s
+	}
+	return res
+}
+
+// isConnectivityError reports whether err, or any error in a group of
+// errors, shows that the chain's RPC can't be reached or is overloaded.
+func isConnectivityError(err error) bool {
+	if err == nil {
+		return false
+	}
+
+	var netErr net.Error
+	if goerrors.As(err, &netErr) {
+		return true
+	}
+
+	var httpErr rpc.HTTPError
+	if goerrors.As(err, &httpErr) {
+		return httpErr.StatusCode == http.StatusTooManyRequests || httpErr.StatusCode >= http.StatusInternalServerError
+	}
+
+	var rpcErr rpc.Error
+	if goerrors.As(err, &rpcErr) && rpcErr.ErrorCode() == rpcLimitExceededCode {
+		return true
+	}
+
+	if goerrors.Is(err, io.EOF) || goerrors.Is(err, io.ErrUnexpectedEOF) ||
+		goerrors.Is(err, syscall.ECONNREFUSED) || goerrors.Is(err, syscall.ECONNRESET) {
+		return true
+	}
+
+	// Some errors only make it here as text.
+	msg := err.Error()
+	for _, s := range []string{"connection refused", "connection reset", "i/o timeout", "no such host"} {
+		if strings.Contains(msg, s) {
+			return true
+		}
+	}
+	return false
+}
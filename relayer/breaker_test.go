@@ -0,0 +1,187 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net"
+	"syscall"
+	"testing"
+	"time"
+
+	"github.com/VolumeFi/whoops"
+	"github.com/ethereum/go-ethereum/rpc"
+	chainmocks "github.com/palomachain/pigeon/chain/mocks"
+	"github.com/stretchr/testify/assert"
+)
+
+type probingProcessor struct {
+	*chainmocks.Processor
+	err   error
+	calls int
+}
+
+func (p *probingProcessor) Probe(context.Context) error {
+	p.calls++
+	return p.err
+}
+
+type blockingProber struct {
+	*chainmocks.Processor
+	probing chan<- struct{}
+	resume  <-chan struct{}
+}
+
+func (p *blockingProber) Probe(context.Context) error {
+	p.probing <- struct{}{}
+	<-p.resume
+	return nil
+}
+
+func TestIsConnectivityError(t *testing.T) {
+	for _, tt := range []struct {
+		name string
+		err  error
+		want bool
+	}{
+		{"nil", nil, false},
+		{"revert", errors.New("execution reverted"), false},
+		{"dial", &net.OpError{Op: "dial", Err: syscall.ECONNREFUSED}, true},
+		{"group with a transport error", whoops.Group{errors.New("execution reverted"), io.EOF}, true},
+		{"server error", rpc.HTTPError{StatusCode: 502}, true},
+		{"client error", rpc.HTTPError{StatusCode: 400}, false},
+		{"text", errors.New("Post \"https://rpc\": dial tcp: i/o timeout"), true},
+	} {
+		t.Run(tt.name, func(t *testing.T) {
+			assert.Equal(t, tt.want, isConnectivityError(tt.err))
+		})
+	}
+}
+
+func TestBreakers(t *testing.T) {
+	ctx := context.Background()
+	errRPC := &net.OpError{Op: "dial", Net: "tcp", Err: syscall.ECONNREFUSED}
+	const chainID, queueName = "eth-main", "evm/eth-main/evm-turnstone-message"
+
+	newTestBreakers := func() (*breakers, *time.Time) {
+		now := time.Now()
+		b := newBreakers(3, time.Minute)
+		b.now = func() time.Time { return now }
+		return b, &now
+	}
+
+	t.Run("it opens after the threshold of consecutive failures", func(t *testing.T) {
+		b, _ := newTestBreakers()
+		p := chainmocks.NewProcessor(t)
+
+		b.record(chainID, queueName, errRPC)
+		b.record(chainID, queueName, errRPC)
+		assert.True(t, b.allow(ctx, p, chainID, queueName))
+
+		b.record(chainID, queueName, errRPC)
+		assert.False(t, b.allow(ctx, p, chainID, queueName))
+		assert.False(t, b.allow(ctx, p, chainID, ""), "the chain's breaker must be open as well")
+
+		status := b.status(chainID)
+		assert.Equal(t, BreakerOpen, status[chainBreaker].State)
+		assert.Equal(t, BreakerOpen, status[queueName].State)
+		assert.Equal(t, 3, status[queueName].ConsecutiveFailures)
+		assert.Equal(t, errRPC.Error(), status[queueName].LastError)
+		assert.NotNil(t, status[queueName].OpenUntil)
+	})
+
+	t.Run("a success resets the failures", func(t *testing.T) {
+		b, _ := newTestBreakers()
+		p := chainmocks.NewProcessor(t)
+
+		b.record(chainID, queueName, errRPC)
+		b.record(chainID, queueName, errRPC)
+		b.record(chainID, queueName, nil)
+		b.record(chainID, queueName, errRPC)
+
+		assert.True(t, b.allow(ctx, p, chainID, queueName))
+		assert.Equal(t, 1, b.status(chainID)[queueName].ConsecutiveFailures)
+	})
+
+	t.Run("context errors aren't counted", func(t *testing.T) {
+		b, _ := newTestBreakers()
+		for range 5 {
+			b.record(chainID, "", context.Canceled)
+		}
+		assert.True(t, b.allow(ctx, chainmocks.NewProcessor(t), chainID, ""))
+	})
+
+	t.Run("only connectivity errors are counted", func(t *testing.T) {
+		b, _ := newTestBreakers()
+		for range 5 {
+			b.record(chainID, queueName, whoops.Group{errors.New("execution reverted")})
+		}
+		assert.True(t, b.allow(ctx, chainmocks.NewProcessor(t), chainID, queueName))
+
+		b.record(chainID, queueName, errRPC)
+		b.record(chainID, queueName, errors.New("execution reverted"))
+		assert.Equal(t, 0, b.status(chainID)[queueName].ConsecutiveFailures, "a reachable chain resets the failures")
+	})
+
+	t.Run("only one caller probes a half-open breaker", func(t *testing.T) {
+		b, now := newTestBreakers()
+		probing, resume := make(chan struct{}), make(chan struct{})
+		p := &blockingProber{Processor: chainmocks.NewProcessor(t), probing: probing, resume: resume}
+		for range 3 {
+			b.record(chainID, "", errRPC)
+		}
+
+		*now = now.Add(2 * time.Minute)
+		allowed := make(chan bool)
+		go func() { allowed <- b.allow(ctx, p, chainID, "") }()
+
+		<-probing
+		assert.False(t, b.allow(ctx, p, chainID, ""), "others must wait for the probe")
+		close(resume)
+		assert.True(t, <-allowed)
+		assert.True(t, b.allow(ctx, p, chainID, ""))
+	})
+
+	t.Run("it probes the chain once the cooldown elapsed", func(t *testing.T) {
+		b, now := newTestBreakers()
+		p := &probingProcessor{Processor: chainmocks.NewProcessor(t), err: errRPC}
+		for range 3 {
+			b.record(chainID, "", errRPC)
+		}
+
+		assert.False(t, b.allow(ctx, p, chainID, ""))
+		assert.Equal(t, 0, p.calls, "must not probe during the cooldown")
+
+		*now = now.Add(2 * time.Minute)
+		assert.False(t, b.allow(ctx, p, chainID, ""))
+		assert.Equal(t, 1, p.calls)
+		assert.Equal(t, BreakerOpen, b.status(chainID)[chainBreaker].State)
+
+		*now = now.Add(2 * time.Minute)
+		p.err = nil
+		assert.True(t, b.allow(ctx, p, chainID, ""))
+		assert.Equal(t, BreakerClosed, b.status(chainID)[chainBreaker].State)
+	})
+
+	t.Run("it resumes work half-open without a probe", func(t *testing.T) {
+		b, now := newTestBreakers()
+		p := chainmocks.NewProcessor(t)
+		for range 3 {
+			b.record(chainID, "", errRPC)
+		}
+
+		*now = now.Add(2 * time.Minute)
+		assert.True(t, b.allow(ctx, p, chainID, ""))
+		assert.Equal(t, BreakerHalfOpen, b.status(chainID)[chainBreaker].State)
+
+		b.record(chainID, "", errRPC)
+		assert.False(t, b.allow(ctx, p, chainID, ""), "a failure while half-open must open it right away")
+	})
+
+	t.Run("nil breakers allow everything", func(t *testing.T) {
+		var b *breakers
+		b.record(chainID, queueName, errRPC)
+		assert.True(t, b.allow(ctx, nil, chainID, queueName))
+		assert.Nil(t, b.status(chainID))
+	})
+}
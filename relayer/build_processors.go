@@ -4,20 +4,24 @@ import (
 	"context"
 	"fmt"
 	"math/big"
+	"sort"
 	"sync"
 
 	"github.com/VolumeFi/whoops"
 	"github.com/ethereum/go-ethereum/common"
 	evmtypes "github.com/palomachain/paloma/v2/x/evm/types"
 	"github.com/palomachain/pigeon/chain"
-	"github.com/palomachain/pigeon/errors"
+	"github.com/palomachain/pigeon/chain/evm"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/health"
+	"github.com/palomachain/pigeon/internal/eventbus"
 	"github.com/palomachain/pigeon/internal/liblog"
 	log "github.com/sirupsen/logrus"
+	"golang.org/x/exp/maps"
 )
 
 func (r *Relayer) buildProcessors(ctx context.Context, _ sync.Locker) error {
 	logger := liblog.WithContext(ctx)
-	// TODO: This should live in a short lived cache, it's run very often.
 	queriedChainsInfos, err := r.palomaClient.QueryGetEVMChainInfos(ctx)
 	if err != nil {
 		return err
@@ -27,12 +31,16 @@ func (r *Relayer) buildProcessors(ctx context.Context, _ sync.Locker) error {
 	r.procRefreshMutex.RLock()
 	err = r.validateChainInfos(queriedChainsInfos)
 	r.procRefreshMutex.RUnlock()
-	if err == nil {
+	if err == nil && !r.hasStaleChains() {
 		logger.Debug("chain infos unchanged since last tick")
 		return nil
 	}
 
-	logger.WithError(err).Warn("Chain infos changed. Building processors...")
+	if err != nil {
+		logger.WithError(err).Warn("Chain infos changed. Updating processors...")
+	} else {
+		logger.Info("Chain configs changed. Updating processors...")
+	}
 	logger.Debug("Acquiring mutex...")
 	r.procRefreshMutex.Lock()
 	logger.Debug("Mutex acquired.")
@@ -41,35 +49,241 @@ func (r *Relayer) buildProcessors(ctx context.Context, _ sync.Locker) error {
 		r.procRefreshMutex.Unlock()
 	}()
 
-	r.processors = []chain.Processor{}
-	r.chainsInfos = []evmtypes.ChainInfo{}
+	stale := r.takeStaleChains()
+	current := r.currentProcessors()
+
+	processors := make([]chain.Processor, 0, len(queriedChainsInfos))
+	chainsInfos := make([]evmtypes.ChainInfo, 0, len(queriedChainsInfos))
+	built := make([]chain.Processor, 0, len(queriedChainsInfos))
+	kept := make(map[string]struct{}, len(queriedChainsInfos))
 	for _, chainInfo := range queriedChainsInfos {
-		logger = logger.WithFields(log.Fields{
-			"chain-reference-id": chainInfo.GetChainReferenceID(),
+		chainReferenceID := chainInfo.GetChainReferenceID()
+		logger := logger.WithFields(log.Fields{
+			"chain-reference-id": chainReferenceID,
 		})
+
+		if cur, ok := current[chainReferenceID]; ok {
+			_, isStale := stale[chainReferenceID]
+			if !isStale && cmpChainInfo(cur.info, *chainInfo) == nil {
+				processors = append(processors, cur.processor)
+				chainsInfos = append(chainsInfos, *chainInfo)
+				kept[chainReferenceID] = struct{}{}
+				continue
+			}
+		}
+
+		logger.Info("building processor")
 		processor, err := r.processorFactory(chainInfo)
-		if errors.IsUnrecoverable(err) {
+		if err != nil {
 			logger.WithError(err).Error("unable to build processor")
+			r.readiness.Set(health.ConditionProcessors, err)
+			r.restoreStaleChains(stale)
+			closeProcessors(built)
 			return err
 		}
+		built = append(built, processor)
 
 		if err := processor.IsRightChain(ctx); err != nil {
 			logger.WithError(err).Error("incorrect chain")
+			r.readiness.Set(health.ConditionRightChain, err)
+			r.restoreStaleChains(stale)
+			closeProcessors(built)
 			return err
 		}
 
-		r.processors = append(r.processors, processor)
-		r.chainsInfos = append(r.chainsInfos, *chainInfo)
+		processors = append(processors, processor)
+		chainsInfos = append(chainsInfos, *chainInfo)
+		r.bus.Publish(ctx, eventbus.ProcessorRebuilt{ChainReferenceID: chainReferenceID})
 	}
 
+	// Chains of other chain types are unknown to Paloma's EVM module and
+	// are built from the chains config instead.
+	currentConfigured := r.currentConfiguredProcessors()
+	configuredChains := r.configuredChainInfos()
+	keptConfigured := make(map[string]struct{}, len(configuredChains))
+	for _, info := range configuredChains {
+		chainReferenceID := info.ChainReferenceID()
+		logger := logger.WithFields(log.Fields{
+			"chain-reference-id": chainReferenceID,
+			"chain-type":         info.ChainType(),
+		})
+
+		if cur, ok := currentConfigured[chainReferenceID]; ok {
+			if _, isStale := stale[chainReferenceID]; !isStale && cur.info == info {
+				processors = append(processors, cur.processor)
+				keptConfigured[chainReferenceID] = struct{}{}
+				continue
+			}
+		}
+
+		logger.Info("building processor")
+		processor, err := r.processorBuilders().Build(info, r.config())
+		if err != nil {
+			logger.WithError(err).Error("unable to build processor")
+			r.readiness.Set(health.ConditionProcessors, err)
+			r.restoreStaleChains(stale)
+			closeProcessors(built)
+			return err
+		}
+		built = append(built, processor)
+
+		if err := processor.IsRightChain(ctx); err != nil {
+			logger.WithError(err).Error("incorrect chain")
+			r.readiness.Set(health.ConditionRightChain, err)
+			r.restoreStaleChains(stale)
+			closeProcessors(built)
+			return err
+		}
+
+		processors = append(processors, processor)
+		r.bus.Publish(ctx, eventbus.ProcessorRebuilt{ChainReferenceID: chainReferenceID})
+	}
+
+	// Shut down processors of changed and removed chains.
+	var retired []chain.Processor
+	for id, cur := range current {
+		if _, ok := kept[id]; !ok {
+			logger.WithField("chain-reference-id", id).Info("shutting down processor")
+			retired = append(retired, cur.processor)
+		}
+	}
+	for id, cur := range currentConfigured {
+		if _, ok := keptConfigured[id]; !ok {
+			logger.WithField("chain-reference-id", id).Info("shutting down processor")
+			retired = append(retired, cur.processor)
+		}
+	}
+	r.retireProcessors(retired)
+
+	r.processors = processors
+	r.chainsInfos = chainsInfos
+	r.configuredChains = configuredChains
+
+	r.readiness.Set(health.ConditionProcessors, nil)
+	r.readiness.Set(health.ConditionRightChain, nil)
+
 	return nil
 }
 
+type builtProcessor struct {
+	info      evmtypes.ChainInfo
+	processor chain.Processor
+}
+
+type builtConfiguredProcessor struct {
+	info      configuredChainInfo
+	processor chain.Processor
+}
+
+// configuredChainInfo describes a chain of the chains config.
+type configuredChainInfo struct {
+	chainType        string
+	chainReferenceID string
+	chainID          string
+}
+
+var _ chain.ChainInfo = configuredChainInfo{}
+
+func (c configuredChainInfo) ChainReferenceID() string { return c.chainReferenceID }
+func (c configuredChainInfo) ChainID() string          { return c.chainID }
+func (c configuredChainInfo) ChainType() string        { return c.chainType }
+
+// configuredChainInfos returns the chains of the chains config, sorted by
+// chain type and chain reference ID. The chain ID is taken from the
+// optional chain-id key of a chain's config.
+func (r *Relayer) configuredChainInfos() []configuredChainInfo {
+	cfg := r.config()
+	if cfg == nil {
+		return nil
+	}
+
+	var res []configuredChainInfo
+	for _, chainType := range sortedKeys(cfg.Chains) {
+		if chainType == evm.ChainType {
+			// EVM chains are queried from Paloma.
+			continue
+		}
+		for _, id := range sortedKeys(cfg.Chains[chainType]) {
+			info := configuredChainInfo{chainType: chainType, chainReferenceID: id}
+			if chainID, ok := cfg.Chains[chainType][id]["chain-id"]; ok {
+				info.chainID = fmt.Sprint(chainID)
+			}
+			res = append(res, info)
+		}
+	}
+	return res
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := maps.Keys(m)
+	sort.Strings(keys)
+	return keys
+}
+
+// processorsInSync reports whether the processors match the EVM chain
+// infos followed by the configured chains.
+func (r *Relayer) processorsInSync() bool {
+	return len(r.processors) == len(r.chainsInfos)+len(r.configuredChains)
+}
+
+// currentProcessors returns the current EVM processors by chain reference
+// ID. If processors and chain infos are out of sync, none of them are
+// reused.
+func (r *Relayer) currentProcessors() map[string]builtProcessor {
+	res := make(map[string]builtProcessor, len(r.chainsInfos))
+	if !r.processorsInSync() {
+		return res
+	}
+	for i, info := range r.chainsInfos {
+		res[info.GetChainReferenceID()] = builtProcessor{info: info, processor: r.processors[i]}
+	}
+	return res
+}
+
+// currentConfiguredProcessors does the same as currentProcessors for the
+// chains of the chains config.
+func (r *Relayer) currentConfiguredProcessors() map[string]builtConfiguredProcessor {
+	res := make(map[string]builtConfiguredProcessor, len(r.configuredChains))
+	if !r.processorsInSync() {
+		return res
+	}
+	offset := len(r.chainsInfos)
+	for i, info := range r.configuredChains {
+		res[info.ChainReferenceID()] = builtConfiguredProcessor{info: info, processor: r.processors[offset+i]}
+	}
+	return res
+}
+
+func closeProcessors(processors []chain.Processor) {
+	for _, p := range processors {
+		if c, ok := p.(chain.Closer); ok {
+			c.Close()
+		}
+	}
+}
+
+// processorFactory builds the processor for an EVM chain reported by
+// Paloma using the builder registered for EVM chains.
 func (r *Relayer) processorFactory(chainInfo *evmtypes.ChainInfo) (chain.Processor, error) {
-	// TODO: add support of other types of chains! Right now, only EVM types are supported!
+	return r.processorBuilders().Build(evm.ChainInfo{ChainInfo: chainInfo}, r.config())
+}
+
+// evmProcessorBuilder builds EVM processors using the relayer's EVM
+// factory.
+type evmProcessorBuilder struct {
+	r *Relayer
+}
+
+func (b evmProcessorBuilder) Build(info chain.ChainInfo, c *config.Config) (chain.Processor, error) {
+	evmInfo, ok := info.(evm.ChainInfo)
+	if !ok {
+		return nil, chain.ErrUnsupportedChainType.Format(info.ChainType(), info.ChainReferenceID())
+	}
+	chainInfo := evmInfo.ChainInfo
+
 	retErr := whoops.Wrap(ErrMissingChainConfig, whoops.Errorf("reference chain id: %s").Format(chainInfo.GetChainReferenceID()))
 
-	cfg, ok := r.cfg.EVM[chainInfo.GetChainReferenceID()]
+	cfg, ok := c.EVM[chainInfo.GetChainReferenceID()]
 	if !ok {
 		return nil, retErr
 	}
@@ -81,7 +295,7 @@ func (r *Relayer) processorFactory(chainInfo *evmtypes.ChainInfo) (chain.Process
 		return nil, ErrInvalidMinOnChainBalance.Format(chainInfo.GetMinOnChainBalance())
 	}
 
-	processor, err := r.evmFactory.Build(
+	processor, err := b.r.evmFactory.Build(
 		cfg,
 		chainInfo.GetChainReferenceID(),
 		string(chainInfo.GetSmartContractUniqueID()),
@@ -92,7 +306,7 @@ func (r *Relayer) processorFactory(chainInfo *evmtypes.ChainInfo) (chain.Process
 		int64(chainInfo.GetReferenceBlockHeight()),
 		common.HexToHash(chainInfo.GetReferenceBlockHash()),
 		minOnChainBalance,
-		r.mevClient,
+		b.r.mevClient,
 	)
 	if err != nil {
 		return nil, whoops.Wrap(err, retErr)
@@ -114,35 +328,42 @@ func (r *Relayer) validateChainInfos(q []*evmtypes.ChainInfo) error {
 	}
 
 	for i, v := range r.chainsInfos {
-
-		type prdct struct {
-			name string
-			want interface{}
-			got  interface{}
+		if err := cmpChainInfo(v, *q[i]); err != nil {
+			return err
 		}
+	}
 
-		predicate := func(n string, w interface{}, g interface{}) prdct {
-			return prdct{name: n, want: w, got: g}
-		}
+	return nil
+}
 
-		for _, k := range []prdct{
-			predicate("Id", v.Id, q[i].Id),
-			predicate("ChainReferenceID", v.ChainReferenceID, q[i].ChainReferenceID),
-			predicate("ChainID", v.ChainID, q[i].ChainID),
-			predicate("SmartContractUniqueID", string(v.SmartContractUniqueID), string(q[i].SmartContractUniqueID)),
-			predicate("SmartContractAddr", v.SmartContractAddr, q[i].SmartContractAddr),
-			predicate("ReferenceBlockHeight", v.ReferenceBlockHeight, q[i].ReferenceBlockHeight),
-			predicate("ReferenceBlockHash", v.ReferenceBlockHash, q[i].ReferenceBlockHash),
-			predicate("Abi", v.Abi, q[i].Abi),
-			predicate("Bytecode", string(v.Bytecode), string(q[i].Bytecode)),
-			predicate("ConstructorInput", string(v.ConstructorInput), string(q[i].ConstructorInput)),
-			predicate("Status", v.Status, q[i].Status),
-			predicate("ActiveSmartContractID", v.ActiveSmartContractID, q[i].ActiveSmartContractID),
-			predicate("MinOnChainBalance", v.MinOnChainBalance, q[i].MinOnChainBalance),
-		} {
-			if err := cmpIfEq(k.name, k.want, k.got); err != nil {
-				return err
-			}
+func cmpChainInfo(v, q evmtypes.ChainInfo) error {
+	type prdct struct {
+		name string
+		want interface{}
+		got  interface{}
+	}
+
+	predicate := func(n string, w interface{}, g interface{}) prdct {
+		return prdct{name: n, want: w, got: g}
+	}
+
+	for _, k := range []prdct{
+		predicate("Id", v.Id, q.Id),
+		predicate("ChainReferenceID", v.ChainReferenceID, q.ChainReferenceID),
+		predicate("ChainID", v.ChainID, q.ChainID),
+		predicate("SmartContractUniqueID", string(v.SmartContractUniqueID), string(q.SmartContractUniqueID)),
+		predicate("SmartContractAddr", v.SmartContractAddr, q.SmartContractAddr),
+		predicate("ReferenceBlockHeight", v.ReferenceBlockHeight, q.ReferenceBlockHeight),
+		predicate("ReferenceBlockHash", v.ReferenceBlockHash, q.ReferenceBlockHash),
+		predicate("Abi", v.Abi, q.Abi),
+		predicate("Bytecode", string(v.Bytecode), string(q.Bytecode)),
+		predicate("ConstructorInput", string(v.ConstructorInput), string(q.ConstructorInput)),
+		predicate("Status", v.Status, q.Status),
+		predicate("ActiveSmartContractID", v.ActiveSmartContractID, q.ActiveSmartContractID),
+		predicate("MinOnChainBalance", v.MinOnChainBalance, q.MinOnChainBalance),
+	} {
+		if err := cmpIfEq(k.name, k.want, k.got); err != nil {
+			return err
 		}
 	}
 
@@ -2,17 +2,21 @@ package relayer
 
 import (
 	"context"
+	"sync/atomic"
 	"testing"
+	"time"
 
 	"github.com/palomachain/paloma/v2/x/evm/types"
 	"github.com/palomachain/pigeon/chain"
 	chainmocks "github.com/palomachain/pigeon/chain/mocks"
 	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/eventbus"
 	"github.com/palomachain/pigeon/relayer/mocks"
 	"github.com/palomachain/pigeon/testutil"
 	timemocks "github.com/palomachain/pigeon/util/time/mocks"
 	"github.com/stretchr/testify/assert"
 	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
 )
 
 func TestBuildProcessors(t *testing.T) {
@@ -309,3 +313,167 @@ func TestBuildProcessors(t *testing.T) {
 		})
 	}
 }
+
+type closableProcessor struct {
+	*chainmocks.Processor
+	closed atomic.Bool
+}
+
+func (c *closableProcessor) Close() { c.closed.Store(true) }
+
+func TestBuildProcessorsIncrementally(t *testing.T) {
+	chain1Info := types.ChainInfo{Id: 1, ChainReferenceID: "chain-1", MinOnChainBalance: "5"}
+	chain2Info := types.ChainInfo{Id: 2, ChainReferenceID: "chain-2", MinOnChainBalance: "5"}
+	chain2NewInfo := types.ChainInfo{Id: 2, ChainReferenceID: "chain-2", MinOnChainBalance: "50"}
+	chain3Info := types.ChainInfo{Id: 3, ChainReferenceID: "chain-3", MinOnChainBalance: "5"}
+
+	pc := mocks.NewPalomaClienter(t)
+	pc.On("QueryGetEVMChainInfos", mock.Anything, mock.Anything).Return(
+		[]*types.ChainInfo{&chain1Info, &chain2NewInfo},
+		nil,
+	)
+
+	rebuilt := chainmocks.NewProcessor(t)
+	rebuilt.On("IsRightChain", mock.Anything).Return(nil)
+
+	evmFactoryMock := mocks.NewEvmFactorier(t)
+	evmFactoryMock.On("Build", mock.Anything, "chain-2", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(rebuilt, nil).Once()
+
+	r := New(
+		&config.Config{
+			EVM: map[string]config.EVM{
+				"chain-1": {},
+				"chain-2": {},
+			},
+		},
+		pc,
+		evmFactoryMock,
+		timemocks.NewTime(t),
+		Config{},
+	)
+
+	bus := eventbus.New()
+	rebuiltEvents := bus.Subscribe(0, eventbus.KindProcessorRebuilt)
+	r.SetEventBus(bus)
+
+	unchanged := &closableProcessor{Processor: chainmocks.NewProcessor(t)}
+	changed := &closableProcessor{Processor: chainmocks.NewProcessor(t)}
+	removed := &closableProcessor{Processor: chainmocks.NewProcessor(t)}
+	r.processors = []chain.Processor{unchanged, changed, removed}
+	r.chainsInfos = []types.ChainInfo{chain1Info, chain2Info, chain3Info}
+
+	// A loop is still working on the old processors.
+	_, release := r.processorSnapshot()
+
+	var locker testutil.FakeMutex
+	assert.NoError(t, r.buildProcessors(context.Background(), locker))
+
+	assert.Equal(t, []chain.Processor{unchanged, rebuilt}, r.processors)
+	assert.Equal(t, []types.ChainInfo{chain1Info, chain2NewInfo}, r.chainsInfos)
+
+	// Replaced processors are only closed once the loop is done with them.
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, changed.closed.Load())
+	assert.False(t, removed.closed.Load())
+
+	release()
+	assert.Eventually(t, func() bool {
+		return changed.closed.Load() && removed.closed.Load()
+	}, time.Second, time.Millisecond)
+	assert.False(t, unchanged.closed.Load())
+
+	require.Len(t, rebuiltEvents.Events(), 1)
+	assert.Equal(t, eventbus.ProcessorRebuilt{ChainReferenceID: "chain-2"}, <-rebuiltEvents.Events())
+}
+
+func TestProcessorBuilders(t *testing.T) {
+	r := New(&config.Config{}, mocks.NewPalomaClienter(t), mocks.NewEvmFactorier(t), timemocks.NewTime(t), Config{})
+
+	assert.Equal(t, []string{"evm"}, r.processorBuilders().ChainTypes())
+	assert.ErrorIs(t, r.RegisterProcessorBuilder("evm", evmProcessorBuilder{r}), chain.ErrBuilderAlreadyRegistered)
+
+	_, err := r.processorFactory(&types.ChainInfo{ChainReferenceID: "chain-1"})
+	assert.ErrorIs(t, err, ErrMissingChainConfig)
+}
+
+type fakeProcessorBuilder struct {
+	build func(info chain.ChainInfo) (chain.Processor, error)
+}
+
+func (b fakeProcessorBuilder) Build(info chain.ChainInfo, _ *config.Config) (chain.Processor, error) {
+	return b.build(info)
+}
+
+func TestBuildConfiguredProcessors(t *testing.T) {
+	chain1Info := types.ChainInfo{Id: 1, ChainReferenceID: "chain-1", MinOnChainBalance: "5"}
+
+	pc := mocks.NewPalomaClienter(t)
+	pc.On("QueryGetEVMChainInfos", mock.Anything, mock.Anything).Return([]*types.ChainInfo{&chain1Info}, nil)
+
+	evmProcessor := chainmocks.NewProcessor(t)
+	evmProcessor.On("IsRightChain", mock.Anything).Return(nil)
+	evmFactoryMock := mocks.NewEvmFactorier(t)
+	evmFactoryMock.On("Build", mock.Anything, "chain-1", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(evmProcessor, nil).Once()
+
+	cfg := &config.Config{
+		EVM: map[string]config.EVM{"chain-1": {}},
+		Chains: map[string]map[string]config.ChainConfig{
+			"solana": {
+				"sol-main": {"chain-id": 101, "rpc-url": "http://sol"},
+				"sol-test": {"rpc-url": "http://sol-test"},
+			},
+		},
+	}
+	r := New(cfg, pc, evmFactoryMock, timemocks.NewTime(t), Config{})
+
+	var builtInfos []chain.ChainInfo
+	solProcessors := map[string]*closableProcessor{}
+	require.NoError(t, r.RegisterProcessorBuilder("solana", fakeProcessorBuilder{
+		build: func(info chain.ChainInfo) (chain.Processor, error) {
+			builtInfos = append(builtInfos, info)
+			p := &closableProcessor{Processor: chainmocks.NewProcessor(t)}
+			p.On("IsRightChain", mock.Anything).Return(nil)
+			solProcessors[info.ChainReferenceID()] = p
+			return p, nil
+		},
+	}))
+
+	var locker testutil.FakeMutex
+	require.NoError(t, r.buildProcessors(context.Background(), locker))
+
+	assert.Equal(t, []chain.ChainInfo{
+		configuredChainInfo{chainType: "solana", chainReferenceID: "sol-main", chainID: "101"},
+		configuredChainInfo{chainType: "solana", chainReferenceID: "sol-test"},
+	}, builtInfos)
+	assert.Equal(t, []chain.Processor{evmProcessor, solProcessors["sol-main"], solProcessors["sol-test"]}, r.processors)
+	assert.Equal(t, []types.ChainInfo{chain1Info}, r.chainsInfos)
+
+	t.Run("unchanged chains are kept", func(t *testing.T) {
+		builtInfos = nil
+		require.NoError(t, r.buildProcessors(context.Background(), locker))
+		assert.Empty(t, builtInfos)
+	})
+
+	t.Run("chains with a changed config are rebuilt and removed chains are closed", func(t *testing.T) {
+		builtInfos = nil
+		oldMain, oldTest := solProcessors["sol-main"], solProcessors["sol-test"]
+
+		r.SetConfig(&config.Config{
+			EVM: map[string]config.EVM{"chain-1": {}},
+			Chains: map[string]map[string]config.ChainConfig{
+				"solana": {
+					"sol-main": {"chain-id": 101, "rpc-url": "http://sol-2"},
+				},
+			},
+		})
+		require.NoError(t, r.buildProcessors(context.Background(), locker))
+
+		assert.Equal(t, []chain.ChainInfo{
+			configuredChainInfo{chainType: "solana", chainReferenceID: "sol-main", chainID: "101"},
+		}, builtInfos)
+		assert.Equal(t, []chain.Processor{evmProcessor, solProcessors["sol-main"]}, r.processors)
+		assert.Eventually(t, func() bool {
+			return oldMain.closed.Load() && oldTest.closed.Load()
+		}, time.Second, time.Millisecond)
+	})
+}
@@ -0,0 +1,114 @@
+package relayer
+
+import (
+	"reflect"
+
+	"github.com/palomachain/pigeon/config"
+)
+
+// SetConfig atomically replaces the relayer's configuration. Processors of
+// chains with a changed configuration are rebuilt on the next tick,
+// all other processors are left untouched.
+func (r *Relayer) SetConfig(cfg *config.Config) {
+	r.cfgMutex.Lock()
+	defer r.cfgMutex.Unlock()
+
+	if r.staleChains == nil {
+		r.staleChains = make(map[string]struct{})
+	}
+	for _, id := range append(changedEVMConfigs(r.cfg, cfg), changedChainConfigs(r.cfg, cfg)...) {
+		r.staleChains[id] = struct{}{}
+	}
+	r.cfg = cfg
+}
+
+func (r *Relayer) config() *config.Config {
+	r.cfgMutex.RLock()
+	defer r.cfgMutex.RUnlock()
+	return r.cfg
+}
+
+func (r *Relayer) hasStaleChains() bool {
+	r.cfgMutex.RLock()
+	defer r.cfgMutex.RUnlock()
+	return len(r.staleChains) > 0
+}
+
+func (r *Relayer) takeStaleChains() map[string]struct{} {
+	r.cfgMutex.Lock()
+	defer r.cfgMutex.Unlock()
+	stale := r.staleChains
+	r.staleChains = nil
+	return stale
+}
+
+// changedEVMConfigs returns the chain reference IDs of all EVM chains
+// which were added, removed or changed between both configurations.
+func changedEVMConfigs(old, new *config.Config) []string {
+	var oldEVM, newEVM map[string]config.EVM
+	if old != nil {
+		oldEVM = old.EVM
+	}
+	if new != nil {
+		newEVM = new.EVM
+	}
+
+	return changedKeys(oldEVM, newEVM)
+}
+
+// changedChainConfigs does the same as changedEVMConfigs for the chains
+// configured in the generic chains section.
+func changedChainConfigs(old, new *config.Config) []string {
+	var oldChains, newChains map[string]map[string]config.ChainConfig
+	if old != nil {
+		oldChains = old.Chains
+	}
+	if new != nil {
+		newChains = new.Chains
+	}
+
+	var changed []string
+	for chainType, cfgs := range newChains {
+		changed = append(changed, changedKeys(oldChains[chainType], cfgs)...)
+	}
+	for chainType, cfgs := range oldChains {
+		if _, ok := newChains[chainType]; !ok {
+			changed = append(changed, changedKeys(cfgs, nil)...)
+		}
+	}
+
+	return changed
+}
+
+func changedKeys[V any](old, new map[string]V) []string {
+	var changed []string
+	for id, cfg := range new {
+		if prev, ok := old[id]; !ok || !reflect.DeepEqual(prev, cfg) {
+			changed = append(changed, id)
+		}
+	}
+	for id := range old {
+		if _, ok := new[id]; !ok {
+			changed = append(changed, id)
+		}
+	}
+
+	return changed
+}
+
+// restoreStaleChains marks chains as stale again after a failed rebuild,
+// so that it's retried on the next tick.
+func (r *Relayer) restoreStaleChains(stale map[string]struct{}) {
+	if len(stale) < 1 {
+		return
+	}
+
+	r.cfgMutex.Lock()
+	defer r.cfgMutex.Unlock()
+	if r.staleChains == nil {
+		r.staleChains = make(map[string]struct{}, len(stale))
+	}
+	for id := range stale {
+		r.staleChains[id] = struct{}{}
+	}
+}
@@ -0,0 +1,117 @@
+package relayer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/palomachain/paloma/v2/x/evm/types"
+	"github.com/palomachain/pigeon/chain"
+	chainmocks "github.com/palomachain/pigeon/chain/mocks"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/relayer/mocks"
+	"github.com/palomachain/pigeon/testutil"
+	timemocks "github.com/palomachain/pigeon/util/time/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/mock"
+	"github.com/stretchr/testify/require"
+)
+
+func TestChangedEVMConfigs(t *testing.T) {
+	old := &config.Config{
+		EVM: map[string]config.EVM{
+			"unchanged": {ChainClientConfig: config.ChainClientConfig{BaseRPCURL: "a"}},
+			"changed":   {ChainClientConfig: config.ChainClientConfig{BaseRPCURL: "a"}},
+			"removed":   {},
+		},
+	}
+	new := &config.Config{
+		EVM: map[string]config.EVM{
+			"unchanged": {ChainClientConfig: config.ChainClientConfig{BaseRPCURL: "a"}},
+			"changed":   {ChainClientConfig: config.ChainClientConfig{BaseRPCURL: "b"}},
+			"added":     {},
+		},
+	}
+
+	assert.ElementsMatch(t, []string{"changed", "removed", "added"}, changedEVMConfigs(old, new))
+	assert.Empty(t, changedEVMConfigs(old, old))
+	assert.ElementsMatch(t, []string{"added", "changed", "unchanged"}, changedEVMConfigs(nil, new))
+}
+
+func TestChangedChainConfigs(t *testing.T) {
+	old := &config.Config{
+		Chains: map[string]map[string]config.ChainConfig{
+			"solana": {
+				"sol-main": {"rpc-url": "a"},
+				"sol-test": {"rpc-url": "a"},
+			},
+			"cosmos": {"osmo-main": {}},
+		},
+	}
+	new := &config.Config{
+		Chains: map[string]map[string]config.ChainConfig{
+			"solana": {
+				"sol-main": {"rpc-url": "a"},
+				"sol-test": {"rpc-url": "b"},
+			},
+		},
+	}
+
+	assert.ElementsMatch(t, []string{"sol-test", "osmo-main"}, changedChainConfigs(old, new))
+	assert.Empty(t, changedChainConfigs(old, old))
+	assert.ElementsMatch(t, []string{"sol-main", "sol-test"}, changedChainConfigs(nil, new))
+}
+
+func TestSetConfigRebuildsChangedProcessors(t *testing.T) {
+	ctx := context.Background()
+	chain1Info := types.ChainInfo{Id: 1, ChainReferenceID: "chain-1", MinOnChainBalance: "5"}
+	chain2Info := types.ChainInfo{Id: 2, ChainReferenceID: "chain-2", MinOnChainBalance: "5"}
+
+	pc := mocks.NewPalomaClienter(t)
+	pc.On("QueryGetEVMChainInfos", mock.Anything, mock.Anything).Return(
+		[]*types.ChainInfo{&chain1Info, &chain2Info},
+		nil,
+	)
+
+	newCfg := config.EVM{ChainClientConfig: config.ChainClientConfig{BaseRPCURL: "http://new"}}
+	rebuilt := chainmocks.NewProcessor(t)
+	rebuilt.On("IsRightChain", mock.Anything).Return(nil)
+
+	evmFactoryMock := mocks.NewEvmFactorier(t)
+	evmFactoryMock.On("Build", newCfg, "chain-2", mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything, mock.Anything).Return(rebuilt, nil).Once()
+
+	r := New(
+		&config.Config{
+			EVM: map[string]config.EVM{
+				"chain-1": {},
+				"chain-2": {},
+			},
+		},
+		pc,
+		evmFactoryMock,
+		timemocks.NewTime(t),
+		Config{},
+	)
+
+	orig1, orig2 := chainmocks.NewProcessor(t), chainmocks.NewProcessor(t)
+	r.processors = []chain.Processor{orig1, orig2}
+	r.chainsInfos = []types.ChainInfo{chain1Info, chain2Info}
+
+	var locker testutil.FakeMutex
+	require.NoError(t, r.buildProcessors(ctx, locker))
+	assert.Equal(t, []chain.Processor{orig1, orig2}, r.processors)
+
+	r.SetConfig(&config.Config{
+		EVM: map[string]config.EVM{
+			"chain-1": {},
+			"chain-2": newCfg,
+		},
+	})
+
+	require.NoError(t, r.buildProcessors(ctx, locker))
+	assert.Equal(t, []chain.Processor{orig1, rebuilt}, r.processors)
+	assert.Equal(t, []types.ChainInfo{chain1Info, chain2Info}, r.chainsInfos)
+
+	// Nothing is rebuilt once the change was picked up.
+	require.NoError(t, r.buildProcessors(ctx, locker))
+	assert.Equal(t, []chain.Processor{orig1, rebuilt}, r.processors)
+}
@@ -18,6 +18,8 @@ var (
 	ErrNotAValidatorAccount = whoops.String("not a validator account")
 
 	ErrValidatorIsNotStaking = whoops.String("validator is not staking")
+
+	ErrProcessGaveUp = whoops.Errorf("process %s keeps panicking, giving up")
 )
 
 func handleProcessError(ctx context.Context, err error) error {
@@ -7,6 +7,7 @@ import (
 
 	"github.com/VolumeFi/whoops"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
+	"github.com/palomachain/pigeon/chain"
 	"github.com/palomachain/pigeon/chain/evm"
 	log "github.com/sirupsen/logrus"
 )
@@ -50,8 +51,22 @@ func (r *Relayer) HealthCheck(ctx context.Context) error {
 		}
 	}
 
+	r.procRefreshMutex.RLock()
+	current := r.currentProcessors()
+	currentConfigured := r.currentConfiguredProcessors()
+	release := r.procGen.acquire()
+	r.procRefreshMutex.RUnlock()
+	defer release()
+
 	var g whoops.Group
 	for _, chainInfo := range chainsInfos {
+		if cur, ok := current[chainInfo.GetChainReferenceID()]; ok && cmpChainInfo(cur.info, *chainInfo) == nil {
+			g.Add(cur.processor.HealthCheck(ctx))
+			continue
+		}
+
+		// The chain has no up to date processor yet, e.g. when the health
+		// is verified from the command line. Check a temporary one.
 		p, err := r.processorFactory(chainInfo)
 		if err != nil {
 			g.Add(err)
@@ -59,6 +74,21 @@ func (r *Relayer) HealthCheck(ctx context.Context) error {
 		}
 
 		g.Add(p.HealthCheck(ctx))
+		closeProcessors([]chain.Processor{p})
+	}
+
+	for _, info := range r.configuredChainInfos() {
+		if cur, ok := currentConfigured[info.ChainReferenceID()]; ok && cur.info == info {
+			g.Add(cur.processor.HealthCheck(ctx))
+			continue
+		}
+
+		// Building one would start a plugin process just to kill it again,
+		// so only running processors are checked.
+		log.WithFields(log.Fields{
+			"chain-type":         info.ChainType(),
+			"chain-reference-id": info.ChainReferenceID(),
+		}).Info("processor not yet started, skipping its health check")
 	}
 
 	if !isStaking {
@@ -76,7 +106,7 @@ func (r *Relayer) HealthCheck(ctx context.Context) error {
 
 func (r *Relayer) BootHealthCheck(ctx context.Context) error {
 	var g whoops.Group
-	for _, cfg := range r.cfg.EVM {
+	for _, cfg := range r.config().EVM {
 		g.Add(evm.TestAndVerifyConfig(ctx, cfg))
 	}
 	return g.Return()
@@ -2,6 +2,7 @@ package relayer
 
 import (
 	"context"
+	"sync"
 	"testing"
 
 	"github.com/VolumeFi/whoops"
@@ -9,6 +10,7 @@ import (
 	. "github.com/onsi/ginkgo/v2"
 	. "github.com/onsi/gomega"
 	evmtypes "github.com/palomachain/paloma/v2/x/evm/types"
+	"github.com/palomachain/pigeon/chain"
 	chainmocks "github.com/palomachain/pigeon/chain/mocks"
 	"github.com/palomachain/pigeon/config"
 	"github.com/palomachain/pigeon/relayer/mocks"
@@ -49,9 +51,10 @@ var _ = Describe("health check", func() {
 
 	JustBeforeEach(func() {
 		r = &Relayer{
-			palomaClient: m,
-			cfg:          cfg,
-			evmFactory:   fm,
+			palomaClient:     m,
+			cfg:              cfg,
+			evmFactory:       fm,
+			procRefreshMutex: &sync.RWMutex{},
 		}
 	})
 
@@ -161,6 +164,37 @@ var _ = Describe("health check", func() {
 				err := r.HealthCheck(ctx)
 				Expect(err).To(MatchError(retErr))
 			})
+
+			It("checks the running processor instead of building a new one", func() {
+				chainInfo := evmtypes.ChainInfo{
+					ChainReferenceID:  "test",
+					MinOnChainBalance: "100000",
+				}
+				m.On("QueryGetEVMChainInfos", mock.Anything).Return([]*evmtypes.ChainInfo{&chainInfo}, nil)
+				m.On("GetValidator", mock.Anything).Return(val, nil)
+				r.processors = []chain.Processor{pm}
+				r.chainsInfos = []evmtypes.ChainInfo{chainInfo}
+
+				pm.On("HealthCheck", mock.Anything).Return(retErr)
+
+				err := r.HealthCheck(ctx)
+				Expect(err).To(MatchError(retErr))
+			})
+
+			It("doesn't build processors for configured chains which aren't started yet", func() {
+				cfg.Chains = map[string]map[string]config.ChainConfig{"solana": {"sol-main": {}}}
+				DeferCleanup(func() { cfg.Chains = nil })
+				m.On("QueryGetEVMChainInfos", mock.Anything).Return([]*evmtypes.ChainInfo{}, nil)
+				m.On("GetValidator", mock.Anything).Return(val, nil)
+				Expect(r.RegisterProcessorBuilder("solana", fakeProcessorBuilder{
+					build: func(chain.ChainInfo) (chain.Processor, error) {
+						Fail("no processor must be built")
+						return nil, nil
+					},
+				})).To(Succeed())
+
+				Expect(r.HealthCheck(ctx)).To(Succeed())
+			})
 		})
 	})
 })
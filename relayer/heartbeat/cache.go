@@ -6,6 +6,7 @@ import (
 	"time"
 
 	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/store"
 	"github.com/sirupsen/logrus"
 )
 
@@ -15,8 +16,20 @@ const (
 	cDefaultBlockSpeed                  time.Duration = time.Millisecond * 1620
 	cMinMovingChainBlockHeightDiff      int64         = 20
 	cMinMovingChainCacheRefreshTimeDiff time.Duration = time.Minute * 2
+
+	stateBucket = "heartbeat"
+	stateKey    = "keep-alive-cache"
 )
 
+// keepAliveState is the part of the cache which is persisted between
+// restarts.
+type keepAliveState struct {
+	LastRefresh         time.Time     `json:"last-refresh"`
+	EstimatedBlockSpeed time.Duration `json:"estimated-block-speed"`
+	LastBlockHeight     int64         `json:"last-block-height"`
+	LastAliveUntil      int64         `json:"last-alive-until"`
+}
+
 type keepAliveCache struct {
 	lastRefresh         time.Time
 	locker              sync.Locker
@@ -27,6 +40,7 @@ type keepAliveCache struct {
 	lastBlockHeight     int64
 	lastAliveUntil      int64
 	invalidated         bool
+	store               store.Store
 }
 
 func (c *keepAliveCache) get(ctx context.Context) (int64, error) {
@@ -78,11 +92,44 @@ func (c *keepAliveCache) refresh(ctx context.Context, _ sync.Locker) error {
 	c.lastBlockHeight = bh
 	c.lastRefresh = time.Now().UTC()
 	c.invalidated = false
+	c.persist(ctx)
 
 	logger.Debug("done refreshing cache")
 	return nil
 }
 
+func (c *keepAliveCache) restore(s store.Store) error {
+	c.store = s
+
+	var state keepAliveState
+	found, err := s.Get(stateBucket, stateKey, &state)
+	if err != nil || !found {
+		return err
+	}
+
+	c.lastRefresh = state.LastRefresh
+	c.estimatedBlockSpeed = state.EstimatedBlockSpeed
+	c.lastBlockHeight = state.LastBlockHeight
+	c.lastAliveUntil = state.LastAliveUntil
+	return nil
+}
+
+func (c *keepAliveCache) persist(ctx context.Context) {
+	if c.store == nil {
+		return
+	}
+
+	err := c.store.Put(stateBucket, stateKey, keepAliveState{
+		LastRefresh:         c.lastRefresh,
+		EstimatedBlockSpeed: c.estimatedBlockSpeed,
+		LastBlockHeight:     c.lastBlockHeight,
+		LastAliveUntil:      c.lastAliveUntil,
+	})
+	if err != nil {
+		liblog.WithContext(ctx).WithError(err).Warn("failed to persist keep alive cache")
+	}
+}
+
 func (c *keepAliveCache) isStale() bool {
 	if c.invalidated ||
 		c.estimatedBlockSpeed == 0 ||
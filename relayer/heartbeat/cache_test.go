@@ -7,6 +7,7 @@ import (
 	"testing"
 	"time"
 
+	"github.com/palomachain/pigeon/internal/store"
 	"github.com/stretchr/testify/require"
 )
 
@@ -253,4 +254,37 @@ func TestCache(t *testing.T) {
 			require.Equal(t, int64(47), btl, "must return alive until block height")
 		})
 	})
+
+	t.Run("restore", func(t *testing.T) {
+		ctx := context.Background()
+		s := store.NewMemory()
+
+		t.Run("with empty store", func(t *testing.T) {
+			c := &keepAliveCache{}
+			require.NoError(t, c.restore(s), "must not return error")
+			require.True(t, c.isStale(), "must be stale")
+		})
+
+		t.Run("with persisted refresh", func(t *testing.T) {
+			c := &keepAliveCache{
+				locker: &sync.Mutex{},
+				store:  s,
+				queryBTL: func(ctx context.Context) (int64, error) {
+					return 47, nil
+				},
+				queryBH: func(ctx context.Context) (int64, error) {
+					return 10, nil
+				},
+			}
+			require.NoError(t, c.refresh(ctx, c.locker), "must not return error")
+
+			restored := &keepAliveCache{}
+			require.NoError(t, restored.restore(s), "must not return error")
+			require.Equal(t, int64(47), restored.lastAliveUntil, "must restore last alive until")
+			require.Equal(t, int64(10), restored.lastBlockHeight, "must restore last block height")
+			require.Equal(t, c.estimatedBlockSpeed, restored.estimatedBlockSpeed, "must restore block speed")
+			require.True(t, c.lastRefresh.Equal(restored.lastRefresh), "must restore last refresh")
+			require.False(t, restored.isStale(), "must not be stale")
+		})
+	})
 }
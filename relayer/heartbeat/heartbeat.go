@@ -7,6 +7,7 @@ import (
 	"time"
 
 	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/store"
 	log "github.com/sirupsen/logrus"
 )
 
@@ -79,6 +80,12 @@ func (m *Heart) SetRetryFalloff(falloff time.Duration) {
 	m.c.retryFalloff = falloff
 }
 
+// SetStore restores the keep alive cache from the store and persists it
+// there from now on.
+func (m *Heart) SetStore(s store.Store) error {
+	return m.c.restore(s)
+}
+
 // Will be blocking during retries. Make sure to always call in Goroutine.
 func (m *Heart) trySendKeepAlive(ctx context.Context, _ sync.Locker) (err error) {
 	logger := liblog.WithContext(ctx).WithField("component", "try-send-keep-alive")
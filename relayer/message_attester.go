@@ -5,7 +5,9 @@ import (
 	"sync"
 
 	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/internal/eventbus"
 	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/metrics"
 	"github.com/palomachain/pigeon/internal/queue"
 	"github.com/palomachain/pigeon/util/slice"
 	log "github.com/sirupsen/logrus"
@@ -24,7 +26,9 @@ func (r *Relayer) AttestMessages(ctx context.Context, _ sync.Locker) error {
 		return err
 	}
 
-	err = r.attestMessages(ctx, r.processors)
+	processors, release := r.processorSnapshot()
+	defer release()
+	err = r.attestMessages(ctx, processors)
 
 	return handleProcessError(ctx, err)
 }
@@ -34,10 +38,13 @@ func (r *Relayer) attestMessages(ctx context.Context, processors []chain.Process
 		return nil
 	}
 
-	// todo randomise
-	for _, p := range processors {
+	return r.forEachProcessor(ctx, attestMessagesLoop, processors, func(ctx context.Context, p chain.Processor) error {
 		// todo randomise
 		for _, queueName := range p.SupportedQueues() {
+			chainReferenceID := queue.FromString(queueName).ChainReferenceID()
+			if !r.breakers.allow(ctx, p, chainReferenceID, queueName) {
+				continue
+			}
 			logger := liblog.WithContext(ctx).WithFields(log.Fields{
 				"queue-name": queueName,
 				"action":     "attest",
@@ -46,6 +53,7 @@ func (r *Relayer) attestMessages(ctx context.Context, processors []chain.Process
 			messagesInQueue, err := r.palomaClient.QueryMessagesForAttesting(ctx, queueName)
 			if err != nil {
 				logger.WithError(err).Error("couldn't get messages to attest")
+				r.status.recordChain(chainReferenceID, attestMessagesLoop, err)
 				if isFatal(err) {
 					return err
 				}
@@ -69,8 +77,10 @@ func (r *Relayer) attestMessages(ctx context.Context, processors []chain.Process
 				})
 				logger.Info("attesting ", len(messagesInQueue), " messages")
 				err := p.ProvideEvidence(ctx, queue.FromString(queueName), messagesInQueue)
+				r.breakers.record(chainReferenceID, queueName, err)
 				if err != nil {
 					logger.WithError(err).Error("error attesting messages")
+					r.status.recordChain(chainReferenceID, attestMessagesLoop, err)
 					if err := r.palomaClient.
 						NewStatus().
 						WithChainReferenceID(p.GetChainReferenceID()).
@@ -83,10 +93,17 @@ func (r *Relayer) attestMessages(ctx context.Context, processors []chain.Process
 					if isFatal(err) {
 						return err
 					}
+					continue
+				}
+				metrics.AddMessages(chainReferenceID, queueName, metrics.ActionAttested, len(messagesInQueue))
+				for _, msg := range messagesInQueue {
+					r.bus.Publish(ctx, eventbus.MessageAttested{ChainReferenceID: chainReferenceID, Queue: queueName, MessageID: msg.ID})
 				}
 			}
+
+			r.status.recordChain(chainReferenceID, attestMessagesLoop, nil)
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
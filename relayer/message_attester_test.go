@@ -54,6 +54,7 @@ func TestAttestMessages(t *testing.T) {
 				require.NoError(t, err)
 
 				p := chainmocks.NewProcessor(t)
+				p.On("GetChainReferenceID").Return("test-chain").Maybe()
 				p.On("IsRightChain", mock.Anything).Return(nil)
 				p.On("SupportedQueues").Return([]string{"a"})
 
@@ -124,6 +125,7 @@ func TestAttestMessages(t *testing.T) {
 				require.NoError(t, err)
 
 				p := chainmocks.NewProcessor(t)
+				p.On("GetChainReferenceID").Return("test-chain").Maybe()
 				p.On("IsRightChain", mock.Anything).Return(chain.ErrNotConnectedToRightChain)
 
 				pal := mocks.NewPalomaClienter(t)
@@ -5,6 +5,8 @@ import (
 	"sync"
 
 	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/internal/eventbus"
+	"github.com/palomachain/pigeon/internal/metrics"
 	"github.com/palomachain/pigeon/internal/queue"
 	"github.com/palomachain/pigeon/util/slice"
 	log "github.com/sirupsen/logrus"
@@ -22,7 +24,9 @@ func (r *Relayer) EstimateMessages(ctx context.Context, _ sync.Locker) error {
 		return err
 	}
 
-	err = r.estimateMessages(ctx, r.processors)
+	processors, release := r.processorSnapshot()
+	defer release()
+	err = r.estimateMessages(ctx, processors)
 	if err != nil {
 		return handleProcessError(ctx, err)
 	}
@@ -35,8 +39,12 @@ func (r *Relayer) estimateMessages(ctx context.Context, processors []chain.Proce
 		return nil
 	}
 
-	for _, p := range processors {
+	return r.forEachProcessor(ctx, estimateMessagesLoop, processors, func(ctx context.Context, p chain.Processor) error {
 		for _, queueName := range p.SupportedQueues() {
+			chainReferenceID := queue.FromString(queueName).ChainReferenceID()
+			if !r.breakers.allow(ctx, p, chainReferenceID, queueName) {
+				continue
+			}
 			logger := log.WithFields(log.Fields{
 				"queue-name": queueName,
 				"action":     "estimate",
@@ -45,6 +53,7 @@ func (r *Relayer) estimateMessages(ctx context.Context, processors []chain.Proce
 			messagesInQueue, err := r.palomaClient.QueryMessagesForEstimating(ctx, queueName)
 			if err != nil {
 				logger.WithError(err).Error("couldn't get messages to estimate")
+				r.status.recordChain(chainReferenceID, estimateMessagesLoop, err)
 				if isFatal(err) {
 					return err
 				}
@@ -68,8 +77,10 @@ func (r *Relayer) estimateMessages(ctx context.Context, processors []chain.Proce
 				})
 				logger.Info("estimating ", len(messagesInQueue), " messages")
 				estimates, err := p.EstimateMessages(ctx, queue.FromString(queueName), messagesInQueue)
+				r.breakers.record(chainReferenceID, queueName, err)
 				if err != nil {
 					logger.WithError(err).Error("error estimating messages")
+					r.status.recordChain(chainReferenceID, estimateMessagesLoop, err)
 					if isFatal(err) {
 						return err
 					}
@@ -101,13 +112,27 @@ func (r *Relayer) estimateMessages(ctx context.Context, processors []chain.Proce
 				err = r.palomaClient.AddMessagesGasEstimate(ctx, queueName, filteredEstimates...)
 				if err != nil {
 					logger.WithError(err).Error("failed to send estimates to Paloma")
+					r.status.recordChain(chainReferenceID, estimateMessagesLoop, err)
 					if isFatal(err) {
 						return err
 					}
+					continue
+				}
+				metrics.AddMessages(chainReferenceID, queueName, metrics.ActionEstimated, len(filteredEstimates))
+				for _, msg := range filteredEstimates {
+					r.bus.Publish(ctx, eventbus.MessageEstimated{
+						ChainReferenceID: chainReferenceID,
+						Queue:            queueName,
+						MessageID:        msg.ID,
+						Estimate:         msg.Estimate,
+					})
 				}
 			}
 
+			r.status.recordChain(chainReferenceID, estimateMessagesLoop, nil)
+
 		}
-	}
-	return nil
+
+		return nil
+	})
 }
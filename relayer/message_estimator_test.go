@@ -77,6 +77,7 @@ func TestEstimateMessages(t *testing.T) {
 				}
 
 				p := chainmocks.NewProcessor(t)
+				p.On("GetChainReferenceID").Return("test-chain").Maybe()
 				p.On("IsRightChain", mock.Anything).Return(nil)
 				p.On("SupportedQueues").Return([]string{"a"})
 				p.On(
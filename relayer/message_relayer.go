@@ -27,7 +27,9 @@ func (r *Relayer) RelayMessages(ctx context.Context, _ sync.Locker) error {
 		return err
 	}
 
-	err = r.relayMessages(ctx, r.processors)
+	processors, release := r.processorSnapshot()
+	defer release()
+	err = r.relayMessages(ctx, processors)
 	if err != nil {
 		return handleProcessError(ctx, err)
 	}
@@ -37,6 +39,9 @@ func (r *Relayer) RelayMessages(ctx context.Context, _ sync.Locker) error {
 }
 
 func (r *Relayer) syncMsgCacheWithPaloma(ctx context.Context) error {
+	r.msgCache.mu.Lock()
+	defer r.msgCache.mu.Unlock()
+
 	if time.Now().UTC().Sub(r.msgCache.lastSync) < cMsgCacheSyncInterval {
 		return nil
 	}
@@ -51,6 +56,7 @@ func (r *Relayer) syncMsgCacheWithPaloma(ctx context.Context) error {
 
 	r.msgCache.lastSync = time.Now().UTC()
 	clear(r.msgCache.records)
+	r.persistMsgCache(ctx)
 
 	return nil
 }
@@ -60,10 +66,13 @@ func (r *Relayer) relayMessages(ctx context.Context, processors []chain.Processo
 		return nil
 	}
 
-	// todo randomise
-	for _, p := range processors {
+	return r.forEachProcessor(ctx, relayMessagesLoop, processors, func(ctx context.Context, p chain.Processor) error {
 		// todo randomise
 		for _, queueName := range p.SupportedQueues() {
+			chainReferenceID := queue.FromString(queueName).ChainReferenceID()
+			if !r.breakers.allow(ctx, p, chainReferenceID, queueName) {
+				continue
+			}
 			logger := log.WithFields(log.Fields{
 				"queue-name": queueName,
 				"action":     "relay",
@@ -72,6 +81,7 @@ func (r *Relayer) relayMessages(ctx context.Context, processors []chain.Processo
 			messagesInQueue, err := r.palomaClient.QueryMessagesForRelaying(ctx, queueName)
 			if err != nil {
 				logger.WithError(err).Error("couldn't get messages to relay")
+				r.status.recordChain(chainReferenceID, relayMessagesLoop, err)
 				if isFatal(err) {
 					return err
 				}
@@ -87,9 +97,18 @@ func (r *Relayer) relayMessages(ctx context.Context, processors []chain.Processo
 
 			logger.Debug("got ", len(messagesInQueue), " messages from ", queueName)
 
+			r.msgCache.mu.Lock()
+			var added bool
 			for _, v := range messagesInQueue {
-				r.msgCache.records[v.ID] = struct{}{}
+				if _, ok := r.msgCache.records[v.ID]; !ok {
+					r.msgCache.records[v.ID] = struct{}{}
+					added = true
+				}
+			}
+			if added {
+				r.persistMsgCache(ctx)
 			}
+			r.msgCache.mu.Unlock()
 
 			if len(messagesInQueue) > 0 {
 				logger := logger.WithFields(log.Fields{
@@ -99,6 +118,7 @@ func (r *Relayer) relayMessages(ctx context.Context, processors []chain.Processo
 				})
 				logger.Info("relaying ", len(messagesInQueue), " messages")
 				err := p.ProcessMessages(ctx, queue.FromString(queueName), messagesInQueue)
+				r.breakers.record(chainReferenceID, queueName, err)
 				if err != nil {
 					logger.WithFields(log.Fields{
 						"err":        err,
@@ -107,12 +127,17 @@ func (r *Relayer) relayMessages(ctx context.Context, processors []chain.Processo
 							return msg.ID
 						}),
 					}).Error("error relaying messages")
+					r.status.recordChain(chainReferenceID, relayMessagesLoop, err)
 					if isFatal(err) {
 						return err
 					}
+					continue
 				}
 			}
+
+			r.status.recordChain(chainReferenceID, relayMessagesLoop, nil)
 		}
-	}
-	return nil
+
+		return nil
+	})
 }
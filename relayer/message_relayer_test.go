@@ -54,6 +54,7 @@ func TestRelayMessages(t *testing.T) {
 				require.NoError(t, err)
 
 				p := chainmocks.NewProcessor(t)
+				p.On("GetChainReferenceID").Return("test-chain").Maybe()
 				p.On("IsRightChain", mock.Anything).Return(nil)
 				p.On("SupportedQueues").Return([]string{"a"})
 				p.On(
@@ -127,6 +128,7 @@ func TestRelayMessages(t *testing.T) {
 				require.NoError(t, err)
 
 				p := chainmocks.NewProcessor(t)
+				p.On("GetChainReferenceID").Return("test-chain").Maybe()
 				p.On("IsRightChain", mock.Anything).Return(chain.ErrNotConnectedToRightChain)
 
 				pal := mocks.NewPalomaClienter(t)
@@ -6,6 +6,9 @@ import (
 
 	"github.com/palomachain/pigeon/chain"
 	"github.com/palomachain/pigeon/chain/paloma"
+	"github.com/palomachain/pigeon/internal/eventbus"
+	"github.com/palomachain/pigeon/internal/metrics"
+	"github.com/palomachain/pigeon/internal/queue"
 	"github.com/palomachain/pigeon/util/slice"
 	log "github.com/sirupsen/logrus"
 )
@@ -23,7 +26,9 @@ func (r *Relayer) SignMessages(ctx context.Context, _ sync.Locker) error {
 		return err
 	}
 
-	err = r.signMessages(ctx, r.processors)
+	processors, release := r.processorSnapshot()
+	defer release()
+	err = r.signMessages(ctx, processors)
 
 	return handleProcessError(ctx, err)
 }
@@ -33,10 +38,10 @@ func (r *Relayer) signMessages(ctx context.Context, processors []chain.Processor
 		return nil
 	}
 
-	// todo randomise
-	for _, p := range processors {
+	return r.forEachProcessor(ctx, signMessagesLoop, processors, func(ctx context.Context, p chain.Processor) error {
 		// todo randomise
 		for _, queueName := range p.SupportedQueues() {
+			chainReferenceID := queue.FromString(queueName).ChainReferenceID()
 			logger := log.WithFields(log.Fields{
 				"queue-name": queueName,
 				"action":     "sign",
@@ -45,6 +50,7 @@ func (r *Relayer) signMessages(ctx context.Context, processors []chain.Processor
 			messagesForSigning, err := r.palomaClient.QueryMessagesForSigning(ctx, queueName)
 			if err != nil {
 				logger.Error("failed getting messages to sign")
+				r.status.recordChain(chainReferenceID, signMessagesLoop, err)
 				if isFatal(err) {
 					return err
 				}
@@ -69,6 +75,7 @@ func (r *Relayer) signMessages(ctx context.Context, processors []chain.Processor
 				signedMessages, err := p.SignMessages(ctx, messagesForSigning...)
 				if err != nil {
 					logger.WithError(err).Error("unable to sign messages")
+					r.status.recordChain(chainReferenceID, signMessagesLoop, err)
 					// If we fail to sign this batch, we will fail to sign them
 					// all, so might as well return now
 					return err
@@ -84,16 +91,23 @@ func (r *Relayer) signMessages(ctx context.Context, processors []chain.Processor
 
 				if err = r.broadcastSignatures(ctx, queueName, signedMessages); err != nil {
 					logger.WithError(err).Error("couldn't broadcast signatures and process attestation")
+					r.status.recordChain(chainReferenceID, signMessagesLoop, err)
 					if isFatal(err) {
 						return err
 					}
+					continue
+				}
+				metrics.AddMessages(chainReferenceID, queueName, metrics.ActionSigned, len(signedMessages))
+				for _, msg := range signedMessages {
+					r.bus.Publish(ctx, eventbus.MessageSigned{ChainReferenceID: chainReferenceID, Queue: queueName, MessageID: msg.ID})
 				}
 			}
 
+			r.status.recordChain(chainReferenceID, signMessagesLoop, nil)
 		}
-	}
 
-	return nil
+		return nil
+	})
 }
 
 func (r *Relayer) broadcastSignatures(ctx context.Context, queueTypeName string, sigs []chain.SignedQueuedMessage) error {
@@ -52,6 +52,7 @@ func TestSignMessages(t *testing.T) {
 				require.NoError(t, err)
 
 				p := chainmocks.NewProcessor(t)
+				p.On("GetChainReferenceID").Return("test-chain").Maybe()
 				p.On("IsRightChain", mock.Anything).Return(nil)
 				p.On("SupportedQueues").Return([]string{"a"})
 				p.On("SignMessages", mock.Anything, mock.Anything, mock.Anything).Return(nil, nil)
@@ -118,6 +119,7 @@ func TestSignMessages(t *testing.T) {
 				require.NoError(t, err)
 
 				p := chainmocks.NewProcessor(t)
+				p.On("GetChainReferenceID").Return("test-chain").Maybe()
 				p.On("IsRightChain", mock.Anything).Return(chain.ErrNotConnectedToRightChain)
 
 				pal := mocks.NewPalomaClienter(t)
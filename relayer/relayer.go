@@ -4,8 +4,10 @@ import (
 	"context"
 	"math/big"
 	"sync"
+	"sync/atomic"
 	"time"
 
+	"github.com/VolumeFi/whoops"
 	sdk "github.com/cosmos/cosmos-sdk/types"
 	stakingtypes "github.com/cosmos/cosmos-sdk/x/staking/types"
 	proto "github.com/cosmos/gogoproto/proto"
@@ -14,9 +16,13 @@ import (
 	skyway "github.com/palomachain/paloma/v2/x/skyway/types"
 	valset "github.com/palomachain/paloma/v2/x/valset/types"
 	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/chain/evm"
 	"github.com/palomachain/pigeon/chain/paloma"
 	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/health"
+	"github.com/palomachain/pigeon/internal/eventbus"
 	"github.com/palomachain/pigeon/internal/mev"
+	"github.com/palomachain/pigeon/internal/store"
 	utiltime "github.com/palomachain/pigeon/util/time"
 )
 
@@ -74,6 +80,7 @@ type valueCache struct {
 }
 
 type messageCache struct {
+	mu       sync.Mutex
 	records  map[uint64]struct{}
 	lastSync time.Time
 }
@@ -81,22 +88,42 @@ type messageCache struct {
 type Relayer struct {
 	palomaClient     PalomaClienter
 	evmFactory       EvmFactorier
+	builders         *chain.Registry
+	buildersOnce     sync.Once
 	mevClient        mev.Client
 	time             utiltime.Time
 	valCache         *valueCache
 	procRefreshMutex *sync.RWMutex
+	cfgMutex         sync.RWMutex
 	cfg              *config.Config
+	staleChains      map[string]struct{}
 	msgCache         *messageCache
 	appVersion       string
 	chainsInfos      []evmtypes.ChainInfo
-	processors       []chain.Processor
+	configuredChains []configuredChainInfo
+	processors       []chain.Processor // of chainsInfos, then of configuredChains
+	procGen          *processorGeneration
 	relayerConfig    Config
-	staking          bool
+	staking          atomic.Bool
+	status           *statusTracker
+	readiness        *health.Readiness
+	store            store.Store
+	inFlight         inFlightTracker
+	breakers         *breakers
+	bus              *eventbus.Bus
 }
 
 type Config struct {
 	KeepAliveLoopTimeout    time.Duration
 	KeepAliveBlockThreshold int64
+	MaxConcurrentChains     int
+	// ShutdownTimeout limits how long in-flight work may take to finish
+	// once a shutdown was requested.
+	ShutdownTimeout time.Duration
+	// BreakerThreshold is the amount of consecutive failures after which
+	// work on a chain or queue is paused for BreakerCooldown.
+	BreakerThreshold int
+	BreakerCooldown  time.Duration
 }
 
 func New(config *config.Config, palomaClient PalomaClienter, evmFactory EvmFactorier, customTime utiltime.Time, cfg Config) *Relayer {
@@ -106,13 +133,31 @@ func New(config *config.Config, palomaClient PalomaClienter, evmFactory EvmFacto
 		evmFactory:       evmFactory,
 		time:             customTime,
 		relayerConfig:    cfg,
-		staking:          false,
 		procRefreshMutex: &sync.RWMutex{},
+		procGen:          newProcessorGeneration(nil),
 		valCache:         &valueCache{},
 		msgCache:         &messageCache{records: make(map[uint64]struct{}), lastSync: time.Now().UTC()},
+		status:           newStatusTracker(),
+		breakers:         newBreakers(cfg.BreakerThreshold, cfg.BreakerCooldown),
 	}
 }
 
+// RegisterProcessorBuilder registers the builder used to build processors
+// for chains of the given type.
+func (r *Relayer) RegisterProcessorBuilder(chainType string, b chain.ProcessorBuilder) error {
+	return r.processorBuilders().Register(chainType, b)
+}
+
+// processorBuilders returns the processor builder registry, which always
+// has EVM processors registered.
+func (r *Relayer) processorBuilders() *chain.Registry {
+	r.buildersOnce.Do(func() {
+		r.builders = chain.NewRegistry()
+		whoops.Assert(r.builders.Register(evm.ChainType, evmProcessorBuilder{r}))
+	})
+	return r.builders
+}
+
 func (r *Relayer) SetAppVersion(appVersion string) {
 	r.appVersion = appVersion
 }
@@ -120,3 +165,12 @@ func (r *Relayer) SetAppVersion(appVersion string) {
 func (r *Relayer) SetMevClient(c mev.Client) {
 	r.mevClient = c
 }
+
+// SetEventBus sets the bus the relayer publishes its lifecycle events to.
+func (r *Relayer) SetEventBus(bus *eventbus.Bus) {
+	r.bus = bus
+}
+
+func (r *Relayer) SetReadiness(readiness *health.Readiness) {
+	r.readiness = readiness
+}
@@ -0,0 +1,72 @@
+package relayer
+
+import (
+	"maps"
+	"slices"
+	"sync"
+	"time"
+
+	log "github.com/sirupsen/logrus"
+)
+
+const defaultShutdownTimeout = 20 * time.Second
+
+// inFlightTracker keeps track of the loops which are currently running an
+// iteration.
+type inFlightTracker struct {
+	mu    sync.Mutex
+	loops map[string]time.Time
+}
+
+func (t *inFlightTracker) start(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	if t.loops == nil {
+		t.loops = make(map[string]time.Time)
+	}
+	t.loops[name] = time.Now()
+}
+
+func (t *inFlightTracker) done(name string) {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	delete(t.loops, name)
+}
+
+func (t *inFlightTracker) names() []string {
+	t.mu.Lock()
+	defer t.mu.Unlock()
+	return slices.Sorted(maps.Keys(t.loops))
+}
+
+// drain waits for all loops to finish their in-flight iteration. Once the
+// shutdown timeout is exceeded, the remaining iterations are canceled.
+func (r *Relayer) drain(wg *sync.WaitGroup, cancel func()) {
+	timeout := r.relayerConfig.ShutdownTimeout
+	if timeout <= 0 {
+		timeout = defaultShutdownTimeout
+	}
+
+	logger := log.WithField("component", "shutdown")
+	if inFlight := r.inFlight.names(); len(inFlight) > 0 {
+		logger.WithFields(log.Fields{
+			"in-flight": inFlight,
+			"timeout":   timeout,
+		}).Info("Waiting for in-flight work to finish.")
+	}
+
+	done := make(chan struct{})
+	go func() {
+		wg.Wait()
+		close(done)
+	}()
+
+	select {
+	case <-done:
+		logger.Info("All in-flight work finished.")
+	case <-time.After(timeout):
+		logger.WithField("in-flight", r.inFlight.names()).
+			Error("Shutdown timeout exceeded, canceling in-flight work. Unreported transactions are logged by their processes.")
+		cancel()
+	}
+}
@@ -0,0 +1,79 @@
+package relayer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palomachain/pigeon/internal/shutdown"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestDrain(t *testing.T) {
+	t.Run("in-flight iterations finish after shutdown was requested", func(t *testing.T) {
+		r := &Relayer{status: newStatusTracker()}
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancelWork := shutdown.Detach(parent)
+		defer cancelWork()
+
+		started := make(chan struct{})
+		var calls atomic.Int32
+		var processErr error
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.startProcess(ctx, "test", &sync.Mutex{}, time.Millisecond, false, func(ctx context.Context, _ sync.Locker) error {
+				if calls.Add(1) > 1 {
+					return nil
+				}
+				close(started)
+				<-shutdown.Requested(ctx)
+				time.Sleep(20 * time.Millisecond)
+				processErr = ctx.Err()
+				return nil
+			})
+		}()
+
+		<-started
+		cancelParent()
+		r.drain(&wg, cancelWork)
+
+		require.NoError(t, processErr, "in-flight work must not be canceled")
+		assert.Equal(t, int32(1), calls.Load(), "no new iteration must be started")
+		assert.Empty(t, r.inFlight.names())
+	})
+
+	t.Run("in-flight iterations are canceled after the timeout", func(t *testing.T) {
+		r := &Relayer{
+			status:        newStatusTracker(),
+			relayerConfig: Config{ShutdownTimeout: 10 * time.Millisecond},
+		}
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancelWork := shutdown.Detach(parent)
+		defer cancelWork()
+
+		started := make(chan struct{})
+		var once sync.Once
+		var wg sync.WaitGroup
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			r.startProcess(ctx, "test", &sync.Mutex{}, time.Millisecond, false, func(ctx context.Context, _ sync.Locker) error {
+				once.Do(func() { close(started) })
+				<-ctx.Done()
+				return ctx.Err()
+			})
+		}()
+
+		<-started
+		cancelParent()
+		r.drain(&wg, cancelWork)
+		wg.Wait()
+
+		assert.ErrorIs(t, ctx.Err(), context.Canceled)
+	})
+}
@@ -5,6 +5,7 @@ import (
 	"sync"
 
 	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/internal/metrics"
 	"github.com/palomachain/pigeon/util/slice"
 	log "github.com/sirupsen/logrus"
 )
@@ -23,7 +24,9 @@ func (r *Relayer) SkywayEstimateBatchGas(ctx context.Context, locker sync.Locker
 	}
 
 	locker.Lock()
-	err = r.skywayEstimateBatchGas(ctx, r.processors)
+	processors, release := r.processorSnapshot()
+	defer release()
+	err = r.skywayEstimateBatchGas(ctx, processors)
 	locker.Unlock()
 
 	return handleProcessError(ctx, err)
@@ -34,8 +37,11 @@ func (r *Relayer) skywayEstimateBatchGas(ctx context.Context, processors []chain
 		return nil
 	}
 
-	for _, p := range processors {
+	return r.forEachProcessor(ctx, skywayEstimateBatchesLoop, processors, func(ctx context.Context, p chain.Processor) error {
 		chainReferenceID := p.GetChainReferenceID()
+		if !r.breakers.allow(ctx, p, chainReferenceID, "") {
+			return nil
+		}
 
 		logger := log.WithFields(log.Fields{
 			"chain-reference-id": chainReferenceID,
@@ -46,6 +52,7 @@ func (r *Relayer) skywayEstimateBatchGas(ctx context.Context, processors []chain
 		batchesForEstimating, err := r.palomaClient.SkywayQueryLastPendingBatchForGasEstimation(ctx, chainReferenceID)
 		if err != nil {
 			logger.WithError(err).Error("failed getting batches to estimate")
+			r.status.recordChain(chainReferenceID, skywayEstimateBatchesLoop, err)
 			return err
 		}
 
@@ -58,8 +65,10 @@ func (r *Relayer) skywayEstimateBatchGas(ctx context.Context, processors []chain
 		if len(batchesForEstimating) > 0 {
 			logger.Info("estimating ", len(batchesForEstimating), " batches")
 			estimatedBatches, err := p.SkywayEstimateBatches(ctx, batchesForEstimating)
+			r.breakers.record(chainReferenceID, "", err)
 			if err != nil {
 				logger.WithError(err).Error("unable to estimate batches")
+				r.status.recordChain(chainReferenceID, skywayEstimateBatchesLoop, err)
 				return err
 			}
 			logger = logger.WithFields(log.Fields{
@@ -73,11 +82,14 @@ func (r *Relayer) skywayEstimateBatchGas(ctx context.Context, processors []chain
 
 			if err = r.palomaClient.SkywayEstimateBatchGas(ctx, estimatedBatches...); err != nil {
 				logger.WithError(err).Error("couldn't broadcast gas estimates for batch.")
+				r.status.recordChain(chainReferenceID, skywayEstimateBatchesLoop, err)
 				return err
 			}
-
+			metrics.AddSkywayBatches(chainReferenceID, metrics.ActionEstimated, len(estimatedBatches))
 		}
-	}
 
-	return nil
+		r.status.recordChain(chainReferenceID, skywayEstimateBatchesLoop, nil)
+
+		return nil
+	})
 }
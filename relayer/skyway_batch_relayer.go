@@ -6,6 +6,7 @@ import (
 
 	"github.com/palomachain/pigeon/chain"
 	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/metrics"
 	"github.com/palomachain/pigeon/util/slice"
 	log "github.com/sirupsen/logrus"
 )
@@ -24,7 +25,9 @@ func (r *Relayer) SkywayRelayBatches(ctx context.Context, locker sync.Locker) er
 	}
 
 	locker.Lock()
-	err = r.skywayRelayBatches(ctx, r.processors)
+	processors, release := r.processorSnapshot()
+	defer release()
+	err = r.skywayRelayBatches(ctx, processors)
 	locker.Unlock()
 
 	return handleProcessError(ctx, err)
@@ -35,8 +38,11 @@ func (r *Relayer) skywayRelayBatches(ctx context.Context, processors []chain.Pro
 		return nil
 	}
 
-	for _, p := range processors {
+	return r.forEachProcessor(ctx, skywayRelayBatchesLoop, processors, func(ctx context.Context, p chain.Processor) error {
 		chainReferenceID := p.GetChainReferenceID()
+		if !r.breakers.allow(ctx, p, chainReferenceID, "") {
+			return nil
+		}
 
 		logger := liblog.WithContext(ctx).WithFields(log.Fields{
 			"chain-reference-id": chainReferenceID,
@@ -54,17 +60,24 @@ func (r *Relayer) skywayRelayBatches(ctx context.Context, processors []chain.Pro
 		logger.Debug("got ", len(batchesForRelaying), " batches")
 		if err != nil {
 			logger.WithError(err).Error("couldn't get batches to relay")
+			r.status.recordChain(chainReferenceID, skywayRelayBatchesLoop, err)
 			return err
 		}
 
 		if len(batchesForRelaying) > 0 {
 			logger.Info("relaying ", len(batchesForRelaying), " batches")
 			err := p.SkywayRelayBatches(ctx, batchesForRelaying)
+			r.breakers.record(chainReferenceID, "", err)
 			if err != nil {
 				logger.WithError(err).Error("error relaying batches")
+				r.status.recordChain(chainReferenceID, skywayRelayBatchesLoop, err)
 				return err
 			}
+			metrics.AddSkywayBatches(chainReferenceID, metrics.ActionRelayed, len(batchesForRelaying))
 		}
-	}
-	return nil
+
+		r.status.recordChain(chainReferenceID, skywayRelayBatchesLoop, nil)
+
+		return nil
+	})
 }
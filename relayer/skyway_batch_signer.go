@@ -6,6 +6,7 @@ import (
 
 	skyway "github.com/palomachain/paloma/v2/x/skyway/types"
 	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/internal/metrics"
 	"github.com/palomachain/pigeon/util/slice"
 	log "github.com/sirupsen/logrus"
 )
@@ -24,7 +25,9 @@ func (r *Relayer) SkywaySignBatches(ctx context.Context, locker sync.Locker) err
 	}
 
 	locker.Lock()
-	err = r.skywaySignBatches(ctx, r.processors)
+	processors, release := r.processorSnapshot()
+	defer release()
+	err = r.skywaySignBatches(ctx, processors)
 	locker.Unlock()
 
 	return handleProcessError(ctx, err)
@@ -35,7 +38,7 @@ func (r *Relayer) skywaySignBatches(ctx context.Context, processors []chain.Proc
 		return nil
 	}
 
-	for _, p := range processors {
+	return r.forEachProcessor(ctx, skywaySignBatchesLoop, processors, func(ctx context.Context, p chain.Processor) error {
 		chainReferenceID := p.GetChainReferenceID()
 
 		logger := log.WithFields(log.Fields{
@@ -47,6 +50,7 @@ func (r *Relayer) skywaySignBatches(ctx context.Context, processors []chain.Proc
 		batchesForSigning, err := r.palomaClient.SkywayQueryLastUnsignedBatch(ctx, chainReferenceID)
 		if err != nil {
 			logger.WithError(err).Error("failed getting batches to sign")
+			r.status.recordChain(chainReferenceID, skywaySignBatchesLoop, err)
 			return err
 		}
 
@@ -61,6 +65,7 @@ func (r *Relayer) skywaySignBatches(ctx context.Context, processors []chain.Proc
 			signedBatches, err := p.SkywaySignBatches(ctx, batchesForSigning...)
 			if err != nil {
 				logger.WithError(err).Error("unable to sign batches")
+				r.status.recordChain(chainReferenceID, skywaySignBatchesLoop, err)
 				return err
 			}
 			logger = logger.WithFields(log.Fields{
@@ -74,13 +79,16 @@ func (r *Relayer) skywaySignBatches(ctx context.Context, processors []chain.Proc
 
 			if err = r.skywayConfirmBatches(ctx, signedBatches); err != nil {
 				logger.WithError(err).Error("couldn't broadcast signatures and process attestation")
+				r.status.recordChain(chainReferenceID, skywaySignBatchesLoop, err)
 				return err
 			}
-
+			metrics.AddSkywayBatches(chainReferenceID, metrics.ActionSigned, len(signedBatches))
 		}
-	}
 
-	return nil
+		r.status.recordChain(chainReferenceID, skywaySignBatchesLoop, nil)
+
+		return nil
+	})
 }
 
 func (r *Relayer) skywayConfirmBatches(ctx context.Context, sigs []chain.SignedSkywayOutgoingTxBatch) error {
@@ -5,7 +5,9 @@ import (
 	"sync"
 
 	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/internal/eventbus"
 	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/metrics"
 	"github.com/palomachain/pigeon/util/slice"
 	log "github.com/sirupsen/logrus"
 )
@@ -24,7 +26,9 @@ func (r *Relayer) SkywayHandleEvents(ctx context.Context, locker sync.Locker) er
 	}
 
 	locker.Lock()
-	err = r.handleEvents(ctx, r.processors)
+	processors, release := r.processorSnapshot()
+	defer release()
+	err = r.handleEvents(ctx, processors)
 	locker.Unlock()
 
 	return handleProcessError(ctx, err)
@@ -35,8 +39,11 @@ func (r *Relayer) handleEvents(ctx context.Context, processors []chain.Processor
 		return nil
 	}
 
-	for _, p := range processors {
+	return r.forEachProcessor(ctx, skywayEventWatcherLoop, processors, func(ctx context.Context, p chain.Processor) error {
 		chainReferenceID := p.GetChainReferenceID()
+		if !r.breakers.allow(ctx, p, chainReferenceID, "") {
+			return nil
+		}
 
 		logger := liblog.WithContext(ctx).WithFields(log.Fields{
 			"chain-reference-id": chainReferenceID,
@@ -44,8 +51,10 @@ func (r *Relayer) handleEvents(ctx context.Context, processors []chain.Processor
 		})
 
 		events, err := p.GetSkywayEvents(ctx, r.palomaClient.GetCreator())
+		r.breakers.record(chainReferenceID, "", err)
 		if err != nil {
 			logger.WithError(err).Error("couldn't get events")
+			r.status.recordChain(chainReferenceID, skywayEventWatcherLoop, err)
 			return err
 		}
 
@@ -66,10 +75,21 @@ func (r *Relayer) handleEvents(ctx context.Context, processors []chain.Processor
 			err := p.SubmitEventClaims(ctx, events, r.palomaClient.GetCreator())
 			if err != nil {
 				logger.WithError(err).Error("error submitting claim for events")
+				r.status.recordChain(chainReferenceID, skywayEventWatcherLoop, err)
 				return err
 			}
+			metrics.AddSkywayClaims(chainReferenceID, len(events))
+			for _, event := range events {
+				r.bus.Publish(ctx, eventbus.ClaimSubmitted{
+					ChainReferenceID: chainReferenceID,
+					EventNonce:       event.GetEventNonce(),
+					SkywayNonce:      event.GetSkywayNonce(),
+				})
+			}
 		}
-	}
 
-	return nil
+		r.status.recordChain(chainReferenceID, skywayEventWatcherLoop, nil)
+
+		return nil
+	})
 }
@@ -2,11 +2,15 @@ package relayer
 
 import (
 	"context"
+	goerrors "errors"
 	"sync"
 	"time"
 
 	"github.com/palomachain/paloma/v2/util/libvalid"
+	"github.com/palomachain/pigeon/health"
+	"github.com/palomachain/pigeon/internal/eventbus"
 	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/shutdown"
 	"github.com/palomachain/pigeon/relayer/heartbeat"
 	log "github.com/sirupsen/logrus"
 )
@@ -18,6 +22,7 @@ const (
 	estimateMessagesLoopInterval     = 500 * time.Millisecond
 	attestMessagesLoopInterval       = 500 * time.Millisecond
 	checkStakingLoopInterval         = 5 * time.Second
+	replaceStuckTxsLoopInterval      = 30 * time.Second
 
 	skywaySignBatchesLoopInterval     = 5 * time.Second
 	skywayEstimateBatchesLoopInterval = 5 * time.Second
@@ -25,6 +30,22 @@ const (
 	skywayEventWatcherLoopInterval    = 1 * time.Minute
 )
 
+const (
+	checkStakingLoop          = "Check staking"
+	updateExternalChainsLoop  = "Update external chain infos"
+	signMessagesLoop          = "Sign messages"
+	estimateMessagesLoop      = "Estimate messages"
+	relayMessagesLoop         = "Relay messages"
+	attestMessagesLoop        = "Attest messages"
+	replaceStuckTxsLoop       = "Replace stuck transactions"
+	mevHeartbeatLoop          = "[MEV] Client heartbeat"
+	skywaySignBatchesLoop     = "[Skyway] Sign batches"
+	skywayEstimateBatchesLoop = "[Skyway] Estimate batches"
+	skywayRelayBatchesLoop    = "[Skyway] Relay batches"
+	skywayEventWatcherLoop    = "[Skyway] Handle Events"
+	keepAliveLoop             = "Keep alive"
+)
+
 func (r *Relayer) checkStaking(ctx context.Context, locker sync.Locker) error {
 	if ctx.Err() != nil {
 		return ctx.Err()
@@ -32,81 +53,102 @@ func (r *Relayer) checkStaking(ctx context.Context, locker sync.Locker) error {
 	log.Info("checking if validator is staking")
 
 	err := r.isStaking(ctx)
+	r.readiness.Set(health.ConditionStaking, err)
 	if err == nil {
 		log.Info("validator is staking")
-		r.staking = true
+		r.staking.Store(true)
 	} else {
 		log.Warn("validator is not staking... waiting")
-		r.staking = false
+		r.staking.Store(false)
 	}
 	return nil
 }
 
-func (r *Relayer) startProcess(ctx context.Context, name string, locker sync.Locker, tickerInterval time.Duration, requiresStaking bool, process func(context.Context, sync.Locker) error) {
-	ticker := time.NewTicker(tickerInterval)
-	defer ticker.Stop()
-
-	logger := liblog.WithContext(ctx).WithField("component", "procmon").WithField("process", name)
-	for {
-		select {
-		case <-ctx.Done():
-			logger.Warn("exiting due to context being done")
-			return
-		case <-ticker.C:
-			if !requiresStaking || r.staking {
-				jCtx := liblog.MustEnrichContext(ctx)
-				err := process(jCtx, locker)
-				if err != nil {
-					liblog.WithContext(jCtx).WithField("component", "procmon").WithField("process", name).WithError(err).Errorf("Failed to execute process: %v", err)
-				} else {
-					liblog.WithContext(jCtx).WithField("component", "procmon").WithField("process", name).Debug("Process executed")
-				}
-			} else {
-				logger.Debug("validor not staking, skipping process execution...")
-			}
-		}
-	}
-}
-
 // Start starts the relayer. It's responsible for handling the communication
 // with Paloma and other chains.
 func (r *Relayer) Start(ctx context.Context) error {
 	log.Info("starting pigeon")
 	var locker sync.Mutex
+	var wg sync.WaitGroup
+
+	// A loop which keeps panicking shuts pigeon down.
+	ctx, requestShutdown := context.WithCancelCause(ctx)
+	defer requestShutdown(nil)
+	shutdownCtx := ctx
+
+	// Loops run on a detached context, so that in-flight iterations can
+	// finish after the shutdown was requested.
+	ctx, cancelWork := shutdown.Detach(ctx)
+	defer cancelWork()
+
+	goProcess := func(name string, tickerInterval time.Duration, requiresStaking bool, process func(context.Context, sync.Locker) error) {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			if err := r.startProcess(ctx, name, &locker, tickerInterval, requiresStaking, process); err != nil {
+				requestShutdown(err)
+			}
+		}()
+	}
 
 	_ = r.checkStaking(ctx, &locker)
 
 	// Start background goroutines to run separately from each other
-	go r.startProcess(ctx, "Check staking", &locker, checkStakingLoopInterval, false, r.checkStaking)
-	go r.startProcess(ctx, "Update external chain infos", &locker, updateExternalChainsLoopInterval, true, r.UpdateExternalChainInfos)
-	go r.startProcess(ctx, "Sign messages", &locker, signMessagesLoopInterval, true, r.SignMessages)
-	go r.startProcess(ctx, "Estimate messages", &locker, estimateMessagesLoopInterval, true, r.EstimateMessages)
-	go r.startProcess(ctx, "Relay messages", &locker, relayMessagesLoopInterval, true, r.RelayMessages)
-	go r.startProcess(ctx, "Attest messages", &locker, attestMessagesLoopInterval, true, r.AttestMessages)
+	goProcess(checkStakingLoop, checkStakingLoopInterval, false, r.checkStaking)
+	goProcess(updateExternalChainsLoop, updateExternalChainsLoopInterval, true, r.UpdateExternalChainInfos)
+	goProcess(signMessagesLoop, signMessagesLoopInterval, true, r.SignMessages)
+	goProcess(estimateMessagesLoop, estimateMessagesLoopInterval, true, r.EstimateMessages)
+	goProcess(relayMessagesLoop, relayMessagesLoopInterval, true, r.RelayMessages)
+	goProcess(attestMessagesLoop, attestMessagesLoopInterval, true, r.AttestMessages)
+	goProcess(replaceStuckTxsLoop, replaceStuckTxsLoopInterval, true, r.ReplaceStuckTransactions)
 
 	if !libvalid.IsNil(r.mevClient) {
-		go r.startProcess(ctx, "[MEV] Client heartbeat", &locker, r.mevClient.GetHealthprobeInterval(), false, r.mevClient.KeepAlive)
+		goProcess(mevHeartbeatLoop, r.mevClient.GetHealthprobeInterval(), false, r.mevClient.KeepAlive)
 	}
 
 	// Start skyway background goroutines to run separately from each other
-	go r.startProcess(ctx, "[Skyway] Sign batches", &locker, skywaySignBatchesLoopInterval, true, r.SkywaySignBatches)
-	go r.startProcess(ctx, "[Skyway] Estimate batches", &locker, skywayEstimateBatchesLoopInterval, true, r.SkywayEstimateBatchGas)
-	go r.startProcess(ctx, "[Skyway] Relay batches", &locker, skywayRelayBatchesLoopInterval, true, r.SkywayRelayBatches)
-	go r.startProcess(ctx, "[Skyway] Handle Events", &locker, skywayEventWatcherLoopInterval, true, r.SkywayHandleEvents)
+	goProcess(skywaySignBatchesLoop, skywaySignBatchesLoopInterval, true, r.SkywaySignBatches)
+	goProcess(skywayEstimateBatchesLoop, skywayEstimateBatchesLoopInterval, true, r.SkywayEstimateBatchGas)
+	goProcess(skywayRelayBatchesLoop, skywayRelayBatchesLoopInterval, true, r.SkywayRelayBatches)
+	goProcess(skywayEventWatcherLoop, skywayEventWatcherLoopInterval, true, r.SkywayHandleEvents)
 
 	// Setup heartbeat to Paloma
 	heart := heartbeat.New(
 		r.palomaClient.QueryGetValidatorAliveUntilBlockHeight,
 		r.palomaClient.BlockHeight,
-		r.palomaClient.KeepValidatorAlive,
+		r.keepValidatorAlive,
 		r.relayerConfig.KeepAliveBlockThreshold,
 		r.appVersion,
 		&locker)
+	if r.store != nil {
+		if err := heart.SetStore(r.store); err != nil {
+			liblog.WithContext(ctx).WithError(err).Warn("Failed to restore keep alive cache.")
+		}
+	}
 
 	// Immediately send a keep alive to Paloma during startup
 	_ = heart.Beat(liblog.MustEnrichContext(ctx), &locker)
 
 	// Start the foreground process
-	r.startProcess(ctx, "Keep alive", &locker, r.relayerConfig.KeepAliveLoopTimeout, false, heart.Beat)
+	if err := r.startProcess(ctx, keepAliveLoop, &locker, r.relayerConfig.KeepAliveLoopTimeout, false, heart.Beat); err != nil {
+		requestShutdown(err)
+	}
+
+	r.drain(&wg, cancelWork)
+
+	if err := context.Cause(shutdownCtx); goerrors.Is(err, ErrProcessGaveUp) {
+		log.WithError(err).Error("Shut down as a process gave up.")
+		return err
+	}
+	return nil
+}
+
+// keepValidatorAlive keeps the validator alive on Paloma and publishes
+// that it did.
+func (r *Relayer) keepValidatorAlive(ctx context.Context, appVersion string) error {
+	if err := r.palomaClient.KeepValidatorAlive(ctx, appVersion); err != nil {
+		return err
+	}
+	r.bus.Publish(ctx, eventbus.KeepAliveSent{AppVersion: appVersion})
 	return nil
 }
@@ -0,0 +1,69 @@
+package relayer
+
+import (
+	"context"
+	"time"
+
+	"github.com/palomachain/pigeon/chain/paloma"
+	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/store"
+	"golang.org/x/exp/maps"
+)
+
+const (
+	stateBucket       = "relayer"
+	stateMsgCacheKey  = "msg-cache"
+	stateChainInfoKey = "chain-infos"
+)
+
+type msgCacheState struct {
+	Records  []uint64  `json:"records"`
+	LastSync time.Time `json:"last-sync"`
+}
+
+// SetStore sets the store the relayer persists its caches to and restores
+// them from it.
+func (r *Relayer) SetStore(s store.Store) {
+	r.store = s
+	logger := liblog.WithContext(context.Background()).WithField("component", "state")
+
+	var msgs msgCacheState
+	if found, err := s.Get(stateBucket, stateMsgCacheKey, &msgs); err != nil {
+		logger.WithError(err).Warn("Failed to restore message cache.")
+	} else if found {
+		r.msgCache.mu.Lock()
+		for _, id := range msgs.Records {
+			r.msgCache.records[id] = struct{}{}
+		}
+		r.msgCache.lastSync = msgs.LastSync
+		r.msgCache.mu.Unlock()
+	}
+
+	var chainInfos []paloma.ChainInfoIn
+	if found, err := s.Get(stateBucket, stateChainInfoKey, &chainInfos); err != nil {
+		logger.WithError(err).Warn("Failed to restore chain info record.")
+	} else if found {
+		r.valCache.lastChainInfoRecord = chainInfos
+	}
+}
+
+// persistMsgCache must be called with the message cache lock held.
+func (r *Relayer) persistMsgCache(ctx context.Context) {
+	r.persist(ctx, stateMsgCacheKey, msgCacheState{
+		Records:  maps.Keys(r.msgCache.records),
+		LastSync: r.msgCache.lastSync,
+	})
+}
+
+// persist stores a value, if the relayer has a store. Failing to persist
+// state is not fatal, the relayer just has to repeat some work after a
+// restart.
+func (r *Relayer) persist(ctx context.Context, key string, v any) {
+	if r.store == nil {
+		return
+	}
+
+	if err := r.store.Put(stateBucket, key, v); err != nil {
+		liblog.WithContext(ctx).WithError(err).WithField("key", key).Warn("Failed to persist relayer state.")
+	}
+}
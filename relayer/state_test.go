@@ -0,0 +1,47 @@
+package relayer
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/palomachain/pigeon/chain/paloma"
+	"github.com/palomachain/pigeon/config"
+	"github.com/palomachain/pigeon/internal/store"
+	"github.com/palomachain/pigeon/relayer/mocks"
+	timemocks "github.com/palomachain/pigeon/util/time/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestRelayerState(t *testing.T) {
+	ctx := context.Background()
+	s := store.NewMemory()
+	lastSync := time.Now().UTC().Truncate(time.Second)
+	chainInfos := []paloma.ChainInfoIn{{ChainReferenceID: "eth-main", ChainType: "evm"}}
+
+	newRelayer := func() *Relayer {
+		return New(&config.Config{}, mocks.NewPalomaClienter(t), mocks.NewEvmFactorier(t), timemocks.NewTime(t), Config{})
+	}
+
+	r := newRelayer()
+	r.SetStore(s)
+	r.msgCache.mu.Lock()
+	r.msgCache.records[42] = struct{}{}
+	r.msgCache.lastSync = lastSync
+	r.persistMsgCache(ctx)
+	r.msgCache.mu.Unlock()
+	r.persist(ctx, stateChainInfoKey, chainInfos)
+
+	restored := newRelayer()
+	restored.SetStore(s)
+
+	assert.Equal(t, map[uint64]struct{}{42: {}}, restored.msgCache.records)
+	assert.True(t, lastSync.Equal(restored.msgCache.lastSync))
+	assert.Equal(t, chainInfos, restored.valCache.lastChainInfoRecord)
+
+	t.Run("without a store nothing is persisted", func(t *testing.T) {
+		r := newRelayer()
+		require.NotPanics(t, func() { r.persist(ctx, stateChainInfoKey, chainInfos) })
+	})
+}
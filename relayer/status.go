@@ -0,0 +1,162 @@
+package relayer
+
+import (
+	"context"
+	"slices"
+	"sync"
+	"time"
+
+	valsettypes "github.com/palomachain/paloma/v2/x/valset/types"
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/internal/traits"
+)
+
+// LoopStatus holds the outcome of the most recent runs of a process loop.
+// State, Restarts and BackoffUntil are only set for supervised loops.
+type LoopStatus struct {
+	State        string     `json:"state,omitempty"`
+	Restarts     int        `json:"restarts,omitempty"`
+	BackoffUntil *time.Time `json:"backoff-until,omitempty"`
+	LastSuccess  *time.Time `json:"last-success,omitempty"`
+	LastError    string     `json:"last-error,omitempty"`
+	LastErrorAt  *time.Time `json:"last-error-at,omitempty"`
+}
+
+// ChainStatus is the status of a single processor.
+type ChainStatus struct {
+	ChainReferenceID string                `json:"chain-reference-id"`
+	MEVEnabled       bool                  `json:"mev-enabled"`
+	Loops            map[string]LoopStatus `json:"loops"`
+	// CircuitBreakers holds the breaker of the chain under "chain" and the
+	// breakers of its queues under their queue names.
+	CircuitBreakers map[string]BreakerStatus `json:"circuit-breakers,omitempty"`
+	chain.ProcessorStatus
+}
+
+// Status is the detailed status of the relayer, exposed via the
+// health check server.
+type Status struct {
+	Staking bool                  `json:"staking"`
+	Loops   map[string]LoopStatus `json:"loops"`
+	Chains  []ChainStatus         `json:"chains"`
+}
+
+type statusTracker struct {
+	mu     sync.RWMutex
+	loops  map[string]*LoopStatus
+	chains map[string]map[string]*LoopStatus
+}
+
+func newStatusTracker() *statusTracker {
+	return &statusTracker{
+		loops:  make(map[string]*LoopStatus),
+		chains: make(map[string]map[string]*LoopStatus),
+	}
+}
+
+func (s *statusTracker) recordLoop(loop string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.loops[loop] = record(s.loops[loop], err)
+}
+
+func (s *statusTracker) recordLoopState(loop, state string, restarts int, backoffUntil *time.Time) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	ls, ok := s.loops[loop]
+	if !ok {
+		ls = &LoopStatus{}
+		s.loops[loop] = ls
+	}
+	ls.State = state
+	ls.Restarts = restarts
+	ls.BackoffUntil = backoffUntil
+}
+
+func (s *statusTracker) recordChain(chainReferenceID, loop string, err error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if _, ok := s.chains[chainReferenceID]; !ok {
+		s.chains[chainReferenceID] = make(map[string]*LoopStatus)
+	}
+	s.chains[chainReferenceID][loop] = record(s.chains[chainReferenceID][loop], err)
+}
+
+// chainFailedSince reports whether the loop recorded an error for the chain
+// at or after t.
+func (s *statusTracker) chainFailedSince(chainReferenceID, loop string, t time.Time) bool {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	ls, ok := s.chains[chainReferenceID][loop]
+	return ok && ls.LastErrorAt != nil && !ls.LastErrorAt.Before(t)
+}
+
+func (s *statusTracker) chainLoops(chainReferenceID string) map[string]LoopStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyLoops(s.chains[chainReferenceID])
+}
+
+func (s *statusTracker) allLoops() map[string]LoopStatus {
+	s.mu.RLock()
+	defer s.mu.RUnlock()
+	return copyLoops(s.loops)
+}
+
+func record(ls *LoopStatus, err error) *LoopStatus {
+	if ls == nil {
+		ls = &LoopStatus{}
+	}
+
+	now := time.Now().UTC()
+	if err != nil {
+		ls.LastError = err.Error()
+		ls.LastErrorAt = &now
+	} else {
+		ls.LastSuccess = &now
+	}
+
+	return ls
+}
+
+func copyLoops(loops map[string]*LoopStatus) map[string]LoopStatus {
+	res := make(map[string]LoopStatus, len(loops))
+	for k, v := range loops {
+		res[k] = *v
+	}
+	return res
+}
+
+// Status returns the current status of the relayer and all of its
+// processors.
+func (r *Relayer) Status(ctx context.Context) any {
+	processors, release := r.processorSnapshot()
+	defer release()
+
+	status := Status{
+		Staking: r.staking.Load(),
+		Loops:   r.status.allLoops(),
+		Chains:  make([]ChainStatus, 0, len(processors)),
+	}
+
+	for _, p := range processors {
+		chainReferenceID := p.GetChainReferenceID()
+		cs := ChainStatus{
+			ChainReferenceID: chainReferenceID,
+			MEVEnabled: slices.Contains(
+				traits.Build(chainReferenceID, r.mevClient),
+				valsettypes.PIGEON_TRAIT_MEV,
+			),
+			Loops:           r.status.chainLoops(chainReferenceID),
+			CircuitBreakers: r.breakers.status(chainReferenceID),
+		}
+
+		if sr, ok := p.(chain.StatusReporter); ok {
+			cs.ProcessorStatus = sr.Status(ctx)
+		}
+
+		status.Chains = append(status.Chains, cs)
+	}
+
+	return status
+}
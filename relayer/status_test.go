@@ -0,0 +1,55 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/palomachain/pigeon/chain"
+	chainmocks "github.com/palomachain/pigeon/chain/mocks"
+	"github.com/palomachain/pigeon/config"
+	timemocks "github.com/palomachain/pigeon/util/time/mocks"
+	"github.com/stretchr/testify/require"
+)
+
+func TestStatus(t *testing.T) {
+	ctx := context.Background()
+
+	p := chainmocks.NewProcessor(t)
+	p.On("GetChainReferenceID").Return("eth-main")
+
+	r := New(&config.Config{}, nil, nil, timemocks.NewTime(t), Config{})
+	r.processors = []chain.Processor{p}
+	r.staking.Store(true)
+
+	r.status.recordLoop(signMessagesLoop, nil)
+	r.status.recordChain("eth-main", signMessagesLoop, nil)
+	r.status.recordChain("eth-main", relayMessagesLoop, errors.New("boom"))
+
+	status, ok := r.Status(ctx).(Status)
+	require.True(t, ok)
+	require.True(t, status.Staking)
+	require.NotNil(t, status.Loops[signMessagesLoop].LastSuccess)
+	require.Len(t, status.Chains, 1)
+
+	cs := status.Chains[0]
+	require.Equal(t, "eth-main", cs.ChainReferenceID)
+	require.False(t, cs.MEVEnabled)
+	require.NotNil(t, cs.Loops[signMessagesLoop].LastSuccess)
+	require.Empty(t, cs.Loops[signMessagesLoop].LastError)
+	require.Nil(t, cs.Loops[relayMessagesLoop].LastSuccess)
+	require.Equal(t, "boom", cs.Loops[relayMessagesLoop].LastError)
+}
+
+func TestChainFailedSince(t *testing.T) {
+	s := newStatusTracker()
+	start := time.Now()
+	require.False(t, s.chainFailedSince("eth-main", relayMessagesLoop, start))
+
+	s.recordChain("eth-main", relayMessagesLoop, errors.New("boom"))
+	s.recordChain("eth-main", relayMessagesLoop, nil)
+	require.True(t, s.chainFailedSince("eth-main", relayMessagesLoop, start))
+	require.False(t, s.chainFailedSince("eth-main", signMessagesLoop, start))
+	require.False(t, s.chainFailedSince("eth-main", relayMessagesLoop, time.Now().Add(time.Second)))
+}
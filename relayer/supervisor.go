@@ -0,0 +1,165 @@
+package relayer
+
+import (
+	"context"
+	goerrors "errors"
+	"fmt"
+	"runtime/debug"
+	"sync"
+	"time"
+
+	"github.com/palomachain/pigeon/errors"
+	"github.com/palomachain/pigeon/health"
+	"github.com/palomachain/pigeon/internal/liblog"
+	"github.com/palomachain/pigeon/internal/metrics"
+	"github.com/palomachain/pigeon/internal/shutdown"
+	log "github.com/sirupsen/logrus"
+)
+
+const (
+	LoopStateRunning    = "running"
+	LoopStateBackingOff = "backing-off"
+	LoopStateFailed     = "failed"
+)
+
+const (
+	supervisorMinBackoff = time.Second
+	supervisorMaxBackoff = 5 * time.Minute
+
+	// supervisorMaxRestarts is the amount of consecutive panics after which
+	// a loop is given up.
+	supervisorMaxRestarts = 10
+)
+
+// panicError is returned for a recovered panic. It is unrecoverable, so
+// the process loops pass it on instead of swallowing it.
+type panicError struct {
+	value any
+	stack []byte
+}
+
+func (e *panicError) Error() string {
+	return fmt.Sprintf("recovered panic: %v", e.value)
+}
+
+func (e *panicError) Is(target error) bool {
+	return target == errors.ErrUnrecoverable
+}
+
+// recoverPanic turns a panic into a panicError assigned to err. It must be
+// deferred directly.
+func recoverPanic(err *error) {
+	if v := recover(); v != nil {
+		*err = &panicError{value: v, stack: debug.Stack()}
+	}
+}
+
+// startProcess runs a process loop and supervises it. The loop is restarted
+// with exponential backoff whenever one of its iterations panics. Once it
+// keeps panicking, pigeon is marked as not ready and ErrProcessGaveUp is
+// returned.
+func (r *Relayer) startProcess(ctx context.Context, name string, locker sync.Locker, tickerInterval time.Duration, requiresStaking bool, process func(context.Context, sync.Locker) error) error {
+	logger := liblog.WithContext(ctx).WithField("component", "supervisor").WithField("process", name)
+	backoff := supervisorMinBackoff
+	restarts := 0
+
+	for {
+		r.status.recordLoopState(name, LoopStateRunning, restarts, nil)
+		started := time.Now()
+		err := r.runProcess(ctx, name, locker, tickerInterval, requiresStaking, process)
+		if err == nil {
+			return nil
+		}
+
+		if time.Since(started) > supervisorMaxBackoff {
+			// The loop was healthy for a while, so this isn't a crash loop.
+			backoff, restarts = supervisorMinBackoff, 0
+		}
+		restarts++
+
+		fields := log.Fields{"restarts": restarts}
+		var pErr *panicError
+		if goerrors.As(err, &pErr) {
+			fields["stack"] = string(pErr.stack)
+		}
+		logger.WithError(err).WithFields(fields).Error("Process panicked.")
+		r.reportPanic(ctx, name, err)
+
+		if restarts > supervisorMaxRestarts {
+			logger.Error("Process keeps panicking, giving up.")
+			r.status.recordLoopState(name, LoopStateFailed, restarts, nil)
+			gaveUp := ErrProcessGaveUp.Format(name)
+			r.readiness.Set(health.ConditionProcessLoops, gaveUp)
+			return gaveUp
+		}
+
+		until := time.Now().UTC().Add(backoff)
+		r.status.recordLoopState(name, LoopStateBackingOff, restarts, &until)
+		logger.WithField("backoff", backoff).Warn("Restarting process after backoff.")
+		select {
+		case <-shutdown.Requested(ctx):
+			return nil
+		case <-time.After(backoff):
+		}
+		backoff = min(2*backoff, supervisorMaxBackoff)
+	}
+}
+
+func (r *Relayer) reportPanic(ctx context.Context, name string, err error) {
+	if r.palomaClient == nil {
+		return
+	}
+
+	if sErr := r.palomaClient.NewStatus().
+		WithArg("process", name).
+		WithLog(err.Error()).
+		Error(ctx); sErr != nil {
+		liblog.WithContext(ctx).WithError(sErr).Error("Failed to report panic to Paloma.")
+	}
+}
+
+// runProcess runs the process loop until a shutdown is requested or one of
+// its iterations panics.
+func (r *Relayer) runProcess(ctx context.Context, name string, locker sync.Locker, tickerInterval time.Duration, requiresStaking bool, process func(context.Context, sync.Locker) error) error {
+	ticker := time.NewTicker(tickerInterval)
+	defer ticker.Stop()
+
+	logger := liblog.WithContext(ctx).WithField("component", "procmon").WithField("process", name)
+	for {
+		select {
+		case <-shutdown.Requested(ctx):
+			logger.Warn("exiting due to shutdown")
+			return nil
+		case <-ticker.C:
+			if shutdown.IsRequested(ctx) {
+				// Never start a new iteration once we're shutting down
+				continue
+			}
+			if !requiresStaking || r.staking.Load() {
+				jCtx := liblog.MustEnrichContext(ctx)
+				start := time.Now()
+				err := r.runIteration(jCtx, name, locker, process)
+				metrics.ObserveProcess(name, time.Since(start), err)
+				r.status.recordLoop(name, err)
+				var pErr *panicError
+				if goerrors.As(err, &pErr) {
+					return err
+				}
+				if err != nil {
+					liblog.WithContext(jCtx).WithField("component", "procmon").WithField("process", name).WithError(err).Errorf("Failed to execute process: %v", err)
+				} else {
+					liblog.WithContext(jCtx).WithField("component", "procmon").WithField("process", name).Debug("Process executed")
+				}
+			} else {
+				logger.Debug("validor not staking, skipping process execution...")
+			}
+		}
+	}
+}
+
+func (r *Relayer) runIteration(ctx context.Context, name string, locker sync.Locker, process func(context.Context, sync.Locker) error) (err error) {
+	r.inFlight.start(name)
+	defer r.inFlight.done(name)
+	defer recoverPanic(&err)
+	return process(ctx, locker)
+}
@@ -0,0 +1,110 @@
+package relayer
+
+import (
+	"context"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palomachain/pigeon/chain"
+	chainmocks "github.com/palomachain/pigeon/chain/mocks"
+	"github.com/palomachain/pigeon/chain/paloma"
+	"github.com/palomachain/pigeon/errors"
+	"github.com/palomachain/pigeon/internal/shutdown"
+	"github.com/palomachain/pigeon/relayer/mocks"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type fakeStatusUpdater struct {
+	mu   sync.Mutex
+	args map[string]string
+	logs []string
+}
+
+func (s *fakeStatusUpdater) WithLog(status string) paloma.StatusUpdater {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.logs = append(s.logs, status)
+	return s
+}
+
+func (s *fakeStatusUpdater) WithArg(key, value string) paloma.StatusUpdater {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	s.args[key] = value
+	return s
+}
+
+func (s *fakeStatusUpdater) WithMsg(*chain.MessageWithSignatures) paloma.StatusUpdater { return s }
+func (s *fakeStatusUpdater) WithQueueType(string) paloma.StatusUpdater                 { return s }
+func (s *fakeStatusUpdater) WithChainReferenceID(string) paloma.StatusUpdater          { return s }
+func (s *fakeStatusUpdater) Info(context.Context) error                                { return nil }
+func (s *fakeStatusUpdater) Error(context.Context) error                               { return nil }
+func (s *fakeStatusUpdater) Debug(context.Context) error                               { return nil }
+
+func TestSupervisor(t *testing.T) {
+	t.Run("a panicking loop is reported and restarted", func(t *testing.T) {
+		pc := mocks.NewPalomaClienter(t)
+		su := &fakeStatusUpdater{args: make(map[string]string)}
+		pc.On("NewStatus").Return(su).Once()
+		r := &Relayer{palomaClient: pc, status: newStatusTracker()}
+
+		parent, cancelParent := context.WithCancel(context.Background())
+		ctx, cancelWork := shutdown.Detach(parent)
+		defer cancelWork()
+
+		var calls atomic.Int32
+		recovered := make(chan struct{})
+		done := make(chan struct{})
+		go func() {
+			defer close(done)
+			r.startProcess(ctx, "test", &sync.Mutex{}, time.Millisecond, false, func(context.Context, sync.Locker) error {
+				switch calls.Add(1) {
+				case 1:
+					var m map[string]int
+					m["boom"]++
+				case 2:
+					close(recovered)
+				}
+				return nil
+			})
+		}()
+
+		select {
+		case <-recovered:
+		case <-time.After(5 * time.Second):
+			t.Fatal("loop wasn't restarted")
+		}
+
+		ls := r.status.allLoops()["test"]
+		assert.Equal(t, LoopStateRunning, ls.State)
+		assert.Equal(t, 1, ls.Restarts)
+		assert.Contains(t, ls.LastError, "recovered panic")
+
+		su.mu.Lock()
+		assert.Equal(t, "test", su.args["process"])
+		require.Len(t, su.logs, 1)
+		assert.Contains(t, su.logs[0], "assignment to entry in nil map")
+		su.mu.Unlock()
+
+		cancelParent()
+		<-done
+	})
+
+	t.Run("panics in workers are returned as unrecoverable errors", func(t *testing.T) {
+		r := &Relayer{}
+		p := chainmocks.NewProcessor(t)
+		p.On("GetChainReferenceID").Return("test-chain")
+		processors := []chain.Processor{p}
+		err := r.forEachProcessor(context.Background(), "test-process", processors, func(context.Context, chain.Processor) error {
+			panic("boom")
+		})
+
+		var pErr *panicError
+		require.ErrorAs(t, err, &pErr)
+		assert.True(t, errors.IsUnrecoverable(err))
+		assert.NotEmpty(t, pErr.stack)
+	})
+}
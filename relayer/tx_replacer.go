@@ -0,0 +1,63 @@
+package relayer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/internal/liblog"
+	log "github.com/sirupsen/logrus"
+)
+
+func (r *Relayer) ReplaceStuckTransactions(ctx context.Context, _ sync.Locker) error {
+	logger := liblog.WithContext(ctx)
+	logger.Info("tx replacer loop")
+	if ctx.Err() != nil {
+		logger.Info("exiting tx replacer loop as context has ended")
+		return ctx.Err()
+	}
+
+	err := r.buildProcessors(ctx, nil)
+	if err != nil {
+		return err
+	}
+
+	processors, release := r.processorSnapshot()
+	defer release()
+	err = r.replaceStuckTransactions(ctx, processors)
+
+	return handleProcessError(ctx, err)
+}
+
+func (r *Relayer) replaceStuckTransactions(ctx context.Context, processors []chain.Processor) error {
+	if len(processors) == 0 {
+		return nil
+	}
+
+	return r.forEachProcessor(ctx, replaceStuckTxsLoop, processors, func(ctx context.Context, p chain.Processor) error {
+		replacer, ok := p.(chain.TxReplacer)
+		if !ok {
+			return nil
+		}
+
+		chainReferenceID := p.GetChainReferenceID()
+		if !r.breakers.allow(ctx, p, chainReferenceID, "") {
+			return nil
+		}
+
+		err := replacer.ReplaceStuckTransactions(ctx)
+		r.breakers.record(chainReferenceID, "", err)
+		r.status.recordChain(chainReferenceID, replaceStuckTxsLoop, err)
+		if err != nil {
+			liblog.WithContext(ctx).WithError(err).WithFields(log.Fields{
+				"chain-reference-id": chainReferenceID,
+				"action":             "replace-stuck-txs",
+			}).Error("failed to replace stuck transactions")
+			if isFatal(err) {
+				return err
+			}
+		}
+
+		return nil
+	})
+}
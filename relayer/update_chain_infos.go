@@ -21,8 +21,10 @@ func (r *Relayer) UpdateExternalChainInfos(ctx context.Context, _ sync.Locker) e
 		return err
 	}
 
-	externalAccounts := make([]chain.ExternalAccount, len(r.processors))
-	for i, v := range r.processors {
+	processors, release := r.processorSnapshot()
+	defer release()
+	externalAccounts := make([]chain.ExternalAccount, len(processors))
+	for i, v := range processors {
 		externalAccounts[i] = v.ExternalAccount()
 	}
 
@@ -61,5 +63,6 @@ func (r *Relayer) UpdateExternalChainInfos(ctx context.Context, _ sync.Locker) e
 
 	logger.Info("Updated chain infos record sent, refreshing cache...")
 	r.valCache.lastChainInfoRecord = chainInfos
+	r.persist(ctx, stateChainInfoKey, chainInfos)
 	return nil
 }
@@ -0,0 +1,129 @@
+package relayer
+
+import (
+	"context"
+	goerrors "errors"
+	"slices"
+	"sync"
+	"time"
+
+	"github.com/palomachain/pigeon/chain"
+	"github.com/palomachain/pigeon/internal/metrics"
+	"golang.org/x/sync/errgroup"
+)
+
+const defaultMaxConcurrentChains = 8
+
+// processorSnapshot returns a copy of the current processors, so that
+// a concurrent rebuild doesn't affect processors that are being worked on.
+// The processors stay open until release is called.
+func (r *Relayer) processorSnapshot() (processors []chain.Processor, release func()) {
+	r.procRefreshMutex.RLock()
+	defer r.procRefreshMutex.RUnlock()
+	return slices.Clone(r.processors), r.procGen.acquire()
+}
+
+// processorGeneration tracks the users of the processors between two
+// rebuilds, so that processors which were replaced or removed are only
+// closed once nobody works on them anymore.
+type processorGeneration struct {
+	users sync.WaitGroup
+	// prev is closed once the previous generation was released.
+	prev <-chan struct{}
+	done chan struct{}
+}
+
+func newProcessorGeneration(prev *processorGeneration) *processorGeneration {
+	g := &processorGeneration{done: make(chan struct{})}
+	if prev != nil {
+		g.prev = prev.done
+	} else {
+		done := make(chan struct{})
+		close(done)
+		g.prev = done
+	}
+	return g
+}
+
+// acquire registers a user of the generation. It must be called while
+// holding the read lock of the processors. A nil generation is never
+// released.
+func (g *processorGeneration) acquire() func() {
+	if g == nil {
+		return func() {}
+	}
+	g.users.Add(1)
+	var once sync.Once
+	return func() { once.Do(g.users.Done) }
+}
+
+// retireProcessors starts a new generation of processors and closes the
+// retired ones once every user of this and all earlier generations is
+// done. It must be called while holding the write lock of the processors.
+func (r *Relayer) retireProcessors(retired []chain.Processor) {
+	prev := r.procGen
+	r.procGen = newProcessorGeneration(prev)
+	if prev == nil {
+		closeProcessors(retired)
+		return
+	}
+
+	go func() {
+		prev.users.Wait()
+		<-prev.prev
+		close(prev.done)
+		closeProcessors(retired)
+	}()
+}
+
+// forEachProcessor runs fn of the given process loop for every processor
+// in its own worker, with at most relayerConfig.MaxConcurrentChains workers
+// at a time. An error of one chain doesn't stop the others, all errors are
+// returned once every worker has finished.
+func (r *Relayer) forEachProcessor(ctx context.Context, process string, processors []chain.Processor, fn func(context.Context, chain.Processor) error) error {
+	limit := r.relayerConfig.MaxConcurrentChains
+	if limit < 1 {
+		limit = defaultMaxConcurrentChains
+	}
+
+	var g errgroup.Group
+	g.SetLimit(limit)
+
+	errs := make([]error, len(processors))
+	for i, p := range processors {
+		g.Go(func() error {
+			// Recover here, as the supervisor can't catch panics in the
+			// workers' goroutines.
+			start := time.Now()
+			errs[i] = func() (err error) {
+				defer recoverPanic(&err)
+				return fn(ctx, p)
+			}()
+			r.observeChainProcess(process, p.GetChainReferenceID(), start, errs[i])
+			return nil
+		})
+	}
+	_ = g.Wait()
+
+	return joinErrors(errs)
+}
+
+// observeChainProcess records the metrics of one chain's share of a process
+// loop execution. Loops carry on with the next queue on most errors, so
+// those recorded in the chain's status count as well.
+func (r *Relayer) observeChainProcess(process, chainReferenceID string, start time.Time, err error) {
+	failed := err != nil || (r.status != nil && r.status.chainFailedSince(chainReferenceID, process, start))
+	metrics.ObserveChainProcess(process, chainReferenceID, time.Since(start), failed)
+}
+
+func joinErrors(errs []error) error {
+	errs = slices.DeleteFunc(errs, func(err error) bool { return err == nil })
+	switch len(errs) {
+	case 0:
+		return nil
+	case 1:
+		return errs[0]
+	default:
+		return goerrors.Join(errs...)
+	}
+}
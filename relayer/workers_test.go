@@ -0,0 +1,108 @@
+package relayer
+
+import (
+	"context"
+	"errors"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/palomachain/pigeon/chain"
+	chainmocks "github.com/palomachain/pigeon/chain/mocks"
+	"github.com/palomachain/pigeon/config"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestForEachProcessor(t *testing.T) {
+	ctx := context.Background()
+	processors := make([]chain.Processor, 6)
+	for i := range processors {
+		p := chainmocks.NewProcessor(t)
+		p.On("GetChainReferenceID").Return("test-chain").Maybe()
+		processors[i] = p
+	}
+
+	t.Run("it limits the amount of concurrent workers", func(t *testing.T) {
+		r := &Relayer{relayerConfig: Config{MaxConcurrentChains: 2}}
+
+		var running, maxRunning, calls atomic.Int32
+		err := r.forEachProcessor(ctx, "test-process", processors, func(context.Context, chain.Processor) error {
+			n := running.Add(1)
+			for {
+				m := maxRunning.Load()
+				if n <= m || maxRunning.CompareAndSwap(m, n) {
+					break
+				}
+			}
+			time.Sleep(10 * time.Millisecond)
+			running.Add(-1)
+			calls.Add(1)
+			return nil
+		})
+
+		assert.NoError(t, err)
+		assert.Equal(t, int32(len(processors)), calls.Load())
+		assert.Equal(t, int32(2), maxRunning.Load())
+	})
+
+	t.Run("an error of one chain doesn't stop the others", func(t *testing.T) {
+		r := &Relayer{}
+		errFirst, errLast := errors.New("first"), errors.New("last")
+
+		var calls atomic.Int32
+		err := r.forEachProcessor(ctx, "test-process", processors, func(_ context.Context, p chain.Processor) error {
+			calls.Add(1)
+			switch p {
+			case processors[0]:
+				return errFirst
+			case processors[len(processors)-1]:
+				return errLast
+			}
+			return nil
+		})
+
+		assert.Equal(t, int32(len(processors)), calls.Load())
+		assert.ErrorIs(t, err, errFirst)
+		assert.ErrorIs(t, err, errLast)
+	})
+
+	t.Run("a single error is returned as is", func(t *testing.T) {
+		r := &Relayer{}
+		errChain := errors.New("chain")
+
+		err := r.forEachProcessor(ctx, "test-process", processors, func(_ context.Context, p chain.Processor) error {
+			if p == processors[2] {
+				return errChain
+			}
+			return nil
+		})
+
+		assert.Equal(t, errChain, err)
+	})
+}
+
+func TestRetireProcessors(t *testing.T) {
+	r := New(&config.Config{}, nil, nil, nil, Config{})
+	p1 := &closableProcessor{Processor: chainmocks.NewProcessor(t)}
+	p2 := &closableProcessor{Processor: chainmocks.NewProcessor(t)}
+	r.processors = []chain.Processor{p1, p2}
+
+	// A user of the first generation still works on both processors, while
+	// p2 only retires with the second rebuild.
+	_, releaseFirst := r.processorSnapshot()
+	r.retireProcessors([]chain.Processor{p1})
+	r.processors = []chain.Processor{p2}
+	_, releaseSecond := r.processorSnapshot()
+	r.retireProcessors([]chain.Processor{p2})
+	r.processors = nil
+
+	releaseSecond()
+	time.Sleep(10 * time.Millisecond)
+	assert.False(t, p1.closed.Load())
+	assert.False(t, p2.closed.Load())
+
+	releaseFirst()
+	assert.Eventually(t, func() bool {
+		return p1.closed.Load() && p2.closed.Load()
+	}, time.Second, time.Millisecond)
+}
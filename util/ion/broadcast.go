@@ -36,7 +36,7 @@ func (cc *Client) BroadcastTx(ctx context.Context, tx []byte) (*sdk.TxResponse,
 
 	return broadcastTx(
 		ctx,
-		cc.RPCClient,
+		cc.RPCClient(),
 		cc.Codec.TxConfig.TxDecoder(),
 		tx,
 		blockTimeout,
@@ -8,9 +8,6 @@ import (
 	"time"
 
 	"github.com/avast/retry-go/v4"
-	provtypes "github.com/cometbft/cometbft/light/provider"
-	prov "github.com/cometbft/cometbft/light/provider/http"
-	rpcclient "github.com/cometbft/cometbft/rpc/client"
 	rpchttp "github.com/cometbft/cometbft/rpc/client/http"
 	libclient "github.com/cometbft/cometbft/rpc/jsonrpc/client"
 	"github.com/cosmos/cosmos-sdk/codec/types"
@@ -32,12 +29,12 @@ var (
 type Client struct {
 	Codec          Codec
 	Keybase        keyring.Keyring
-	RPCClient      rpcclient.Client
-	LightProvider  provtypes.Provider
 	Input          io.Reader
 	Output         io.Writer
 	Config         *ChainClientConfig
 	KeyringOptions []keyring.Option
+
+	endpoints *rpcEndpoints
 }
 
 // UnpackAny implements types.AnyUnpacker.
@@ -70,20 +67,13 @@ func (c *Client) init() (*Client, error) {
 	// TODO: figure out how to deal with input or maybe just make all keyring backends test?
 
 	timeout, _ := time.ParseDuration(c.Config.Timeout)
-	rpcClient, err := NewRPCClient(c.Config.RPCAddr, timeout)
-	if err != nil {
-		log.WithField("err", err).Error("keybase new keyring error")
-		return nil, err
-	}
-
-	lightprovider, err := prov.New(c.Config.ChainID, c.Config.RPCAddr)
+	endpoints, err := newRPCEndpoints(c.Config.ChainID, c.Config.RPCAddrs(), timeout)
 	if err != nil {
-		log.WithField("err", err).Error("failed to load light provider")
+		log.WithField("err", err).Error("failed to create rpc clients")
 		return nil, err
 	}
 
-	c.RPCClient = rpcClient
-	c.LightProvider = lightprovider
+	c.endpoints = endpoints
 	c.Keybase = keybase
 
 	return c, nil
@@ -46,22 +46,28 @@ var ModuleBasics = []module.AppModuleBasic{
 }
 
 type ChainClientConfig struct {
-	BlockTimeout   string                  `json:"block-timeout" yaml:"block-timeout"`
-	OutputFormat   string                  `json:"output-format" yaml:"output-format"`
-	KeyDirectory   string                  `json:"key-directory" yaml:"key-directory"`
-	GRPCAddr       string                  `json:"grpc-addr" yaml:"grpc-addr"`
-	AccountPrefix  string                  `json:"account-prefix" yaml:"account-prefix"`
-	KeyringBackend string                  `json:"keyring-backend" yaml:"keyring-backend"`
-	SignModeStr    string                  `json:"sign-mode" yaml:"sign-mode"`
-	GasPrices      string                  `json:"gas-prices" yaml:"gas-prices"`
-	RPCAddr        string                  `json:"rpc-addr" yaml:"rpc-addr"`
-	ChainID        string                  `json:"chain-id" yaml:"chain-id"`
-	Timeout        string                  `json:"timeout" yaml:"timeout"`
-	Key            string                  `json:"key" yaml:"key"`
-	ExtraCodecs    []string                `json:"extra-codecs" yaml:"extra-codecs"`
-	Modules        []module.AppModuleBasic `json:"-" yaml:"-"`
-	Slip44         int                     `json:"slip44" yaml:"slip44"`
-	GasAdjustment  float64                 `json:"gas-adjustment" yaml:"gas-adjustment"`
-	MinGasAmount   uint64                  `json:"min-gas-amount" yaml:"min-gas-amount"`
-	Debug          bool                    `json:"debug" yaml:"debug"`
+	BlockTimeout     string                  `json:"block-timeout" yaml:"block-timeout"`
+	OutputFormat     string                  `json:"output-format" yaml:"output-format"`
+	KeyDirectory     string                  `json:"key-directory" yaml:"key-directory"`
+	GRPCAddr         string                  `json:"grpc-addr" yaml:"grpc-addr"`
+	AccountPrefix    string                  `json:"account-prefix" yaml:"account-prefix"`
+	KeyringBackend   string                  `json:"keyring-backend" yaml:"keyring-backend"`
+	SignModeStr      string                  `json:"sign-mode" yaml:"sign-mode"`
+	GasPrices        string                  `json:"gas-prices" yaml:"gas-prices"`
+	RPCAddr          string                  `json:"rpc-addr" yaml:"rpc-addr"`
+	FallbackRPCAddrs []string                `json:"fallback-rpc-addrs" yaml:"fallback-rpc-addrs"`
+	ChainID          string                  `json:"chain-id" yaml:"chain-id"`
+	Timeout          string                  `json:"timeout" yaml:"timeout"`
+	Key              string                  `json:"key" yaml:"key"`
+	ExtraCodecs      []string                `json:"extra-codecs" yaml:"extra-codecs"`
+	Modules          []module.AppModuleBasic `json:"-" yaml:"-"`
+	Slip44           int                     `json:"slip44" yaml:"slip44"`
+	GasAdjustment    float64                 `json:"gas-adjustment" yaml:"gas-adjustment"`
+	MinGasAmount     uint64                  `json:"min-gas-amount" yaml:"min-gas-amount"`
+	Debug            bool                    `json:"debug" yaml:"debug"`
+}
+
+// RPCAddrs returns the primary RPC address followed by all fallback addresses.
+func (c *ChainClientConfig) RPCAddrs() []string {
+	return append([]string{c.RPCAddr}, c.FallbackRPCAddrs...)
 }
@@ -0,0 +1,97 @@
+package ion
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	provtypes "github.com/cometbft/cometbft/light/provider"
+	prov "github.com/cometbft/cometbft/light/provider/http"
+	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	"github.com/palomachain/pigeon/internal/liblog"
+	log "github.com/sirupsen/logrus"
+)
+
+type rpcEndpoint struct {
+	addr  string
+	rpc   rpcclient.Client
+	light provtypes.Provider
+}
+
+// rpcEndpoints holds every RPC endpoint the client may talk to.
+// Only one of them is active at a time, the others are used as
+// fallbacks once the active endpoint becomes unreachable.
+type rpcEndpoints struct {
+	all    []rpcEndpoint
+	active int
+	mu     sync.RWMutex
+}
+
+func newRPCEndpoints(chainID string, addrs []string, timeout time.Duration) (*rpcEndpoints, error) {
+	e := &rpcEndpoints{}
+	for _, addr := range addrs {
+		rpcClient, err := NewRPCClient(addr, timeout)
+		if err != nil {
+			return nil, err
+		}
+
+		lightprovider, err := prov.New(chainID, addr)
+		if err != nil {
+			return nil, err
+		}
+
+		e.all = append(e.all, rpcEndpoint{
+			addr:  addr,
+			rpc:   rpcClient,
+			light: lightprovider,
+		})
+	}
+
+	return e, nil
+}
+
+func (e *rpcEndpoints) current() rpcEndpoint {
+	e.mu.RLock()
+	defer e.mu.RUnlock()
+	return e.all[e.active]
+}
+
+// RPCClient returns the client of the currently active RPC endpoint.
+func (cc *Client) RPCClient() rpcclient.Client {
+	return cc.endpoints.current().rpc
+}
+
+// LightProvider returns the light provider of the currently active RPC endpoint.
+func (cc *Client) LightProvider() provtypes.Provider {
+	return cc.endpoints.current().light
+}
+
+// ActiveRPCEndpoint returns the address of the currently active RPC endpoint.
+func (cc *Client) ActiveRPCEndpoint() string {
+	return cc.endpoints.current().addr
+}
+
+// RPCEndpointCount returns the number of configured RPC endpoints.
+func (cc *Client) RPCEndpointCount() int {
+	return len(cc.endpoints.all)
+}
+
+// FailoverRPCEndpoint switches to the next configured RPC endpoint, given
+// the active one is still the failed one. This way, concurrent callers
+// running into the same outage only advance the endpoint once.
+func (cc *Client) FailoverRPCEndpoint(ctx context.Context, failed string) {
+	e := cc.endpoints
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	if len(e.all) < 2 || e.all[e.active].addr != failed {
+		return
+	}
+
+	e.active = (e.active + 1) % len(e.all)
+	liblog.WithContext(ctx).WithFields(log.Fields{
+		"component": "rpc-endpoints",
+		"from":      failed,
+		"to":        e.all[e.active].addr,
+	}).Warn("Switching paloma RPC endpoint.")
+}
@@ -1,5 +1,7 @@
 package ion
 
+import "fmt"
+
 type _err string
 
 func (e _err) Error() string { return string(e) }
@@ -8,3 +10,16 @@ const (
 	ErrTimeoutAfterWaitingForTxBroadcast _err = "timed out after waiting for tx to get included in the block"
 	ErrUnexpectedNonZeroCode             _err = "node returned unexpected code"
 )
+
+// BroadcastError is returned when broadcasting a signed transaction failed.
+// The transaction may still have reached the node.
+type BroadcastError struct {
+	TxHash []byte
+	Err    error
+}
+
+func (e *BroadcastError) Error() string {
+	return fmt.Sprintf("failed to broadcast tx %X: %v", e.TxHash, e.Err)
+}
+
+func (e *BroadcastError) Unwrap() error { return e.Err }
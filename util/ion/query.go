@@ -35,7 +35,7 @@ func (cc *Client) queryBalanceWithAddress(ctx context.Context, address string) (
 
 func (cc *Client) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
 	log.Info("Client: Status")
-	status, err := cc.RPCClient.Status(ctx)
+	status, err := cc.RPCClient().Status(ctx)
 	if err != nil {
 		log.WithField("err", err).Error("Nope")
 		liblog.WithContext(ctx).WithError(err).Error("Nope")
@@ -47,12 +47,25 @@ func (cc *Client) Status(ctx context.Context) (*ctypes.ResultStatus, error) {
 	return status, nil
 }
 
+// IsTxKnown reports whether the active RPC endpoint knows the transaction
+// with hash, i.e. it was included in a block.
+func (cc *Client) IsTxKnown(ctx context.Context, hash []byte) (bool, error) {
+	_, err := cc.RPCClient().Tx(ctx, hash, false)
+	if err == nil {
+		return true, nil
+	}
+	if strings.Contains(err.Error(), "not found") {
+		return false, nil
+	}
+	return false, err
+}
+
 func (cc *Client) queryLatestHeight(ctx context.Context) (int64, error) {
 	stat, err := cc.Status(ctx)
 	if err != nil {
 		return -1, err
 	} else if stat.SyncInfo.CatchingUp {
-		return -1, fmt.Errorf("node at %s running chain %s not caught up", cc.Config.RPCAddr, cc.Config.ChainID)
+		return -1, fmt.Errorf("node at %s running chain %s not caught up", cc.ActiveRPCEndpoint(), cc.Config.ChainID)
 	}
 	return stat.SyncInfo.LatestBlockHeight, nil
 }
@@ -242,7 +255,7 @@ func (cc *Client) QueryTx(ctx context.Context, hashHex string, prove bool) (*cty
 		return nil, err
 	}
 
-	return cc.RPCClient.Tx(ctx, hash, prove)
+	return cc.RPCClient().Tx(ctx, hash, prove)
 }
 
 // QueryTxs returns an array of transactions related to the specified event search criteria.
@@ -259,7 +272,7 @@ func (cc *Client) QueryTxs(ctx context.Context, page, limit int, events []string
 		return nil, errors.New("limit must greater than 0")
 	}
 
-	res, err := cc.RPCClient.TxSearch(ctx, strings.Join(events, " AND "), true, &page, &limit, "")
+	res, err := cc.RPCClient().TxSearch(ctx, strings.Join(events, " AND "), true, &page, &limit, "")
 	if err != nil {
 		return nil, err
 	}
@@ -9,6 +9,7 @@ import (
 	"github.com/avast/retry-go/v4"
 	abci "github.com/cometbft/cometbft/abci/types"
 	rpcclient "github.com/cometbft/cometbft/rpc/client"
+	tmtypes "github.com/cometbft/cometbft/types"
 	"github.com/cosmos/cosmos-sdk/client"
 	"github.com/cosmos/cosmos-sdk/client/tx"
 	"github.com/cosmos/cosmos-sdk/crypto/keyring"
@@ -124,7 +125,7 @@ func (cc *Client) SendMsgs(ctx context.Context, msgs []sdk.Msg, memo string, opt
 	// Broadcast those bytes
 	res, err := cc.BroadcastTx(ctx, txBytes)
 	if err != nil {
-		return nil, fmt.Errorf("failed to broadcast tx: %w", err)
+		return nil, &BroadcastError{TxHash: tmtypes.Tx(txBytes).Hash(), Err: err}
 	}
 
 	// transaction was executed, log the success or failure using the tx response code
@@ -155,7 +156,7 @@ func (cc *Client) PrepareFactory(txf tx.Factory) (tx.Factory, error) {
 		return tx.Factory{}, err
 	}
 
-	cliCtx := client.Context{}.WithClient(cc.RPCClient).
+	cliCtx := client.Context{}.WithClient(cc.RPCClient()).
 		WithInterfaceRegistry(cc.Codec.InterfaceRegistry).
 		WithChainID(cc.Config.ChainID).
 		WithCodec(cc.Codec.Marshaler)
@@ -246,7 +247,7 @@ func (cc *Client) QueryABCI(ctx context.Context, req abci.RequestQuery) (abci.Re
 		Height: req.Height,
 		Prove:  req.Prove,
 	}
-	result, err := cc.RPCClient.ABCIQueryWithOptions(ctx, req.Path, req.Data, opts)
+	result, err := cc.RPCClient().ABCIQueryWithOptions(ctx, req.Path, req.Data, opts)
 	if err != nil {
 		return abci.ResponseQuery{}, err
 	}